@@ -1,21 +1,36 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/gin-gonic/gin"
 
 	// 改成你的專案模組路徑（依 go.mod）
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/analytics"
 	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/cache"
 	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/config"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/courier"
 	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/db"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/invoice"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/logistics"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/mail"
 	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/middleware"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/notify"
 	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/order"
 	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/product"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/secretbox"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/settlement"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/webhook"
+
+	adminauth "github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/admin"
 
 	// Vendor
+	vendorauth "github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/auth"
 	vendormodels "github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
 	vendorroutes "github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/routes"
 )
@@ -25,8 +40,25 @@ func main() {
 	cfg := config.Load()
 
 	if cfg.DBDSN == "" { log.Fatal("config: DBDSN is empty") }
-	if cfg.AdminToken == "" { log.Fatal("config: AdminToken is empty") }
+	if cfg.AdminJWTSecret == "" {
+		if os.Getenv("APP_ENV") == "production" { log.Fatal("config: ADMIN_JWT_SECRET is empty") }
+		log.Printf("config: ADMIN_JWT_SECRET not set, using development key")
+		cfg.AdminJWTSecret = "dev-admin-secret"
+	}
+	if cfg.VendorJWTSecret == "" {
+		if os.Getenv("APP_ENV") == "production" { log.Fatal("config: JWT_SECRET is empty") }
+		log.Printf("config: JWT_SECRET not set, using development key")
+		cfg.VendorJWTSecret = "dev-secret"
+	}
+	if cfg.AdminToken != "" { log.Printf("config: ADMIN_TOKEN is set; it only works for creating the first admin user, unset it afterwards") }
 	if cfg.Port == "" { cfg.Port = "8080" }
+	if cfg.DataKey == "" {
+		if os.Getenv("APP_ENV") == "production" { log.Fatal("config: DATA_KEY is empty") }
+		log.Printf("config: DATA_KEY not set, using development key")
+		cfg.DataKey = "dev-data-key"
+	}
+	box, err := secretbox.New(cfg.DataKey)
+	if err != nil { log.Fatalf("secretbox: %v", err) }
 
 	log.Printf("starting ZeusShop… port=%s, db=%s, redis=%s",
 		cfg.Port, safeDSN(cfg.DBDSN), safeRedis(cfg.RedisAddr),
@@ -35,18 +67,56 @@ func main() {
 	gormDB := db.MustOpen(cfg.DBDSN)
 	rdb := cache.MustOpen(cfg.RedisAddr)
 
+	// 舊版明碼重設 token 欄位須在 AutoMigrate 前移除
+	if err := vendormodels.DropPlainResetTokens(gormDB); err != nil {
+		log.Fatalf("drop plain reset tokens: %v", err)
+	}
 	// AutoMigrate（保留原本 + Vendor）
 	if err := gormDB.AutoMigrate(
 		&product.Product{},
 		&product.ProductImage{}, // ★
+		&product.ProductRevision{},
+		&product.ProductViewDaily{},
 		&order.Order{},
 		&order.OrderItem{},
 		&order.OrderCounter{},
+		&order.SubOrder{},
+		&order.OrderHistory{},
+		&order.Migration{},
 		&vendormodels.Vendor{},
 		&vendormodels.VendorPasswordReset{},
+		&vendormodels.VendorSession{},
+		&vendormodels.VendorLoginAudit{},
+		&vendormodels.VendorStaff{},
+		&vendormodels.VendorAPIKey{},
+		&invoice.Invoice{},
+		&invoice.Allowance{},
+		&settlement.CommissionRate{},
+		&settlement.Statement{},
+		&settlement.StatementLine{},
+		&settlement.ScheduledRun{},
+		&settlement.GenerateLock{},
+		&adminauth.User{},
+		&adminauth.AuditLog{},
+		&adminauth.Setting{},
+		&notify.Delivery{},
+		&webhook.Subscription{},
+		&webhook.Delivery{},
 	); err != nil {
 		log.Fatalf("auto migrate: %v", err)
 	}
+	// 舊訂單補拆廠商子訂單
+	if err := order.BackfillSubOrders(gormDB); err != nil {
+		log.Fatalf("backfill sub orders: %v", err)
+	}
+	// 付款狀態上線前已完成的舊訂單標為已付款（只執行一次）
+	if err := order.BackfillPayments(gormDB); err != nil {
+		log.Fatalf("backfill payments: %v", err)
+	}
+	// 舊廠商補上店家頁 slug
+	if err := vendormodels.BackfillSlugs(gormDB); err != nil {
+		log.Fatalf("backfill vendor slugs: %v", err)
+	}
 
 	r := gin.Default()
 	r.Use(middleware.CORS(cfg.CORSOrigins))
@@ -61,30 +131,157 @@ func main() {
 	ph := product.NewHandler(gormDB, rdb)
 	r.GET("/api/products", ph.List)
 	r.GET("/api/products/:id", ph.Get)
+	vendorroutes.RegisterVendorPublicRoutes(r, gormDB, ph)                         // 廠商店家頁
+
+	// 超商電子地圖選店
+	cvsProvider := mustLogisticsProvider(cfg)
+	ls := logistics.NewService(rdb, cvsProvider)
+	lh := logistics.NewHandler(ls, cfg.PublicBaseURL)
+	r.GET("/api/logistics/cvs/map", lh.Map)
+	r.POST("/api/logistics/cvs/callback", lh.Callback)
+	r.GET("/api/logistics/cvs/store/:state", lh.Store)
+	if fp, ok := cvsProvider.(*logistics.FakeProvider); ok && os.Getenv("APP_ENV") != "production" {
+		r.Any("/api/logistics/fake-map", fp.ServeMap)
+	}
+
+	// 通知信（背景佇列寄送，失敗自動重試）
+	ms, err := mail.NewService(mail.NewQueue(mustMailer(cfg), cfg.MailFrom, 1000), cfg.ShopName)
+	if err != nil { log.Fatalf("mail: %v", err) }
+	go ms.Run(context.Background(), 2)
+	ms.SubscribeOrders(gormDB)
+
+	// 簡訊 / LINE 通知（有 LINE userId 走 LINE，否則簡訊）
+	ns, err := notify.NewService(gormDB, cfg.ShopName, mustNotifyChannels(cfg)...)
+	if err != nil { log.Fatalf("notify: %v", err) }
+	ns.Subscribe()
+	nh := notify.NewHandler(ns)
+
+	// 對外 webhook（訂單 / 商品事件，簽章後投遞，失敗指數退避重試）
+	product.LowStockThreshold = cfg.LowStockThreshold
+	ws := webhook.NewService(gormDB, box, cfg.WebhookAllowPrivate)
+	ws.Subscribe()
+	go ws.Run(context.Background(), 4)
+	wh := webhook.NewHandler(ws)
 
 	oh := order.NewHandler(gormDB)
+	oh.SetStoreLookup(ls.Lookup)
+	if cfg.LineLoginChannel != "" {
+		oh.SetLineVerifier(notify.NewLIFFVerifier(cfg.LineLoginChannel).Verify)
+	}
 	r.POST("/api/orders", oh.Create)
 	r.PUT("/api/orders/:id/remit", oh.UpdateRemit)
 
 	// Admin（保留）
-	admin := r.Group("/api/admin", func(c *gin.Context) {
-		if c.GetHeader("X-Admin-Token") != cfg.AdminToken {
-			c.AbortWithStatusJSON(401, gin.H{"error": "unauthorized"})
-			return
-		}
-	})
+	// 後台帳號 / 角色權限（各端點所需權限見 internal/admin/permission.go）
+	as := adminauth.NewService(gormDB, cfg.AdminJWTSecret, cfg.AdminToken, box, cfg.ShopName+" Admin")
+	ah := adminauth.NewHandler(as)
+	r.POST("/api/admin/login", ah.Login)
+	r.POST("/api/admin/login/2fa", ah.LoginTwoFactor)
+	admin := r.Group("/api/admin", as.Middleware())
+	admin.GET("/me", ah.Me)
+	admin.PUT("/me/password", ah.ChangePassword)
+	admin.GET("/me/2fa", ah.TwoFactor)
+	admin.POST("/me/2fa/setup", ah.SetupTwoFactor)
+	admin.POST("/me/2fa/enable", ah.EnableTwoFactor)
+	admin.POST("/me/2fa/disable", ah.DisableTwoFactor)
+	admin.POST("/me/2fa/recovery-codes", ah.RegenerateRecoveryCodes)
+	admin.GET("/users", ah.ListUsers)
+	admin.POST("/users", ah.CreateUser)
+	admin.PUT("/users/:id", ah.UpdateUser)
+	admin.PUT("/users/:id/password", ah.ResetPassword)
+	admin.GET("/audit-logs", ah.AuditLogs)
+	admin.DELETE("/users/:id/2fa", ah.ResetTwoFactor)
+	admin.GET("/security-policy", ah.SecurityPolicy)
+	admin.PUT("/security-policy", ah.SetSecurityPolicy)
+	admin.GET("/products", ph.AdminList)
+	admin.GET("/products/:id", ph.AdminGet)
 	admin.POST("/products", ph.Create)
 	admin.PUT("/products/:id", ph.Update)
 	admin.DELETE("/products/:id", ph.Delete)
+	admin.GET("/product-reviews", ph.AdminListReviews)
+	admin.PUT("/product-reviews/:id/approve", ph.AdminApproveReview)
+	admin.PUT("/product-reviews/:id/reject", ph.AdminRejectReview)
 	admin.GET("/orders", oh.AdminList)
 	admin.GET("/orders/:id", oh.AdminGet)
 	admin.PUT("/orders/:id/status", oh.AdminUpdateStatus)
+	admin.PUT("/orders/:id/payment", oh.AdminConfirmPayment)
+	admin.PUT("/orders/:id/shipment", oh.AdminAssignShipment)
+	admin.POST("/orders/bulk/status", oh.AdminBulkStatus)
+	admin.POST("/orders/bulk/payment", oh.AdminBulkPayment)
+	admin.POST("/orders/bulk/shipments", oh.AdminBulkShipments)
+	admin.POST("/orders/bulk/print", oh.AdminBulkPrint)
+	admin.GET("/orders/:id/history", oh.AdminHistory)
+	admin.POST("/orders/items/:itemId/refund", oh.AdminRefundItem)
 	admin.DELETE("/orders/:id", oh.AdminDelete)
+	admin.PUT("/sub-orders/:id/status", oh.AdminUpdateSubOrder)
+	admin.GET("/notifications", nh.AdminList)
+	admin.POST("/notifications/:id/resend", nh.AdminResend)
+	admin.GET("/webhooks", wh.AdminList)
+	admin.POST("/webhooks", wh.AdminCreate)
+	admin.PUT("/webhooks/:id", wh.AdminUpdate)
+	admin.DELETE("/webhooks/:id", wh.AdminDelete)
+	admin.POST("/webhooks/:id/rotate-secret", wh.AdminRotateSecret)
+	admin.POST("/webhooks/:id/ping", wh.AdminPing)
+	admin.GET("/webhook-deliveries", wh.AdminDeliveries)
+	admin.POST("/webhook-deliveries/:id/redeliver", wh.AdminRedeliver)
+
+	// 物流託運上傳檔 / 回傳單號匯入
+	couriers, err := courier.LoadTemplates(cfg.CourierTemplates)
+	if err != nil { log.Fatalf("courier: %v", err) }
+	ch := courier.NewHandler(courier.NewService(gormDB, couriers, courier.Sender{
+		Name: cfg.CourierSenderName, Phone: cfg.CourierSenderPhone, Address: cfg.CourierSenderAddress,
+	}, cfg.CourierHome))
+	admin.GET("/courier/templates", ch.AdminTemplates)
+	admin.POST("/courier/export", ch.AdminExport)
+	admin.POST("/courier/:code/import", ch.AdminImport)
+
+	// 電子發票
+	ih := invoice.NewHandler(invoice.NewService(gormDB, mustInvoiceProvider(cfg)))
+	admin.POST("/orders/:id/invoice", ih.AdminIssue)
+	admin.GET("/orders/:id/invoice", ih.AdminListByOrder)
+	admin.POST("/invoices/:id/void", ih.AdminVoid)
+	admin.POST("/invoices/:id/allowances", ih.AdminAllowance)
+
+	// 廠商結算 / 抽成
+	ss := settlement.NewService(gormDB, cfg.CommissionDefaultBP)
+	sh := settlement.NewHandler(ss)
+	admin.GET("/settlements", sh.AdminList)
+	admin.POST("/settlements/generate", sh.AdminGenerate)
+	admin.GET("/settlements/:id", sh.AdminGet)
+	admin.GET("/settlements/:id/csv", sh.AdminCSV)
+	admin.PUT("/settlements/:id/approve", sh.AdminApprove)
+	admin.PUT("/settlements/:id/paid", sh.AdminMarkPaid)
+	admin.DELETE("/settlements/:id", sh.AdminDiscard)
+	admin.GET("/commission-rates", sh.AdminRates)
+	admin.PUT("/commission-rates", sh.AdminSetRate)
+	admin.DELETE("/commission-rates/:id", sh.AdminDeleteRate)
+	go ss.RunMonthly(context.Background(), time.Hour) // 每小時檢查上月是否已結算
+
+	// 營收報表（台北時間；?format=csv|xlsx 下載）
+	an := analytics.NewService(gormDB, rdb)
+	rh := analytics.NewHandler(an)
+	admin.GET("/reports/summary", rh.AdminSummary)
+	admin.GET("/reports/revenue", rh.AdminRevenue)
+	admin.GET("/reports/categories", rh.AdminByCategory)
+	admin.GET("/reports/vendors", rh.AdminByVendor)
+	admin.GET("/reports/shipping", rh.AdminByShipping)
 
-	// ★ 廠商專用 API
-	vendorroutes.RegisterVendorRoutes(r, gormDB)                 // 註冊/登入/密碼
-	vendorroutes.RegisterVendorProductRoutes(r, gormDB)          // 上架商品 / 多圖上傳 / CRUD
-	vendorroutes.RegisterVendorOrderRoutes(r, gormDB)            // 只看自己的訂單
+	// ★ 廠商專用 API（登入驗證統一由 vendors/auth 處理）
+	va := vendorauth.New(cfg.VendorJWTSecret, os.Getenv("APP_ENV") == "production", gormDB, rdb)
+	lg := vendorauth.NewLoginGuard(gormDB, rdb)            // 登入失敗次數 / 鎖定
+	go va.RunResetCleanup(context.Background(), time.Hour) // 每小時清除過期的重設密碼 token
+
+	vendorroutes.RegisterVendorRoutes(r, gormDB, va, lg, ms, cfg.PublicBaseURL)      // 註冊/登入/密碼/登入裝置
+	vendorroutes.RegisterVendorTwoFactorRoutes(r, gormDB, box, va, lg, cfg.ShopName) // 雙因素驗證（TOTP）
+	vendorroutes.RegisterVendorProductRoutes(r, gormDB, cfg.ProductModeration, va)   // 上架商品 / 多圖上傳 / CRUD
+	vendorroutes.RegisterVendorOrderRoutes(r, gormDB, va)                            // 只看自己的訂單
+	vendorroutes.RegisterVendorSettlementRoutes(r, ss, va)                           // 結算單
+	vendorroutes.RegisterVendorProfileRoutes(r, gormDB, box, va)                     // 商業資料 / 撥款帳戶
+	vendorroutes.RegisterVendorStaffRoutes(r, gormDB, va, ms, cfg.PublicBaseURL)     // 員工子帳號 / 邀請
+	vendorroutes.RegisterVendorAPIKeyRoutes(r, gormDB, va)                           // API 金鑰（ERP 同步商品 / 訂單）
+	vendorroutes.RegisterVendorWebhookRoutes(r, ws, va)                              // Webhook 訂閱 / 投遞紀錄
+	vendorroutes.RegisterVendorAnalyticsRoutes(r, an, va)                            // 銷售分析
+	vendorroutes.RegisterAdminVendorRoutes(admin, gormDB, box, lg)                   // 後台：帳戶審核
 
 	log.Printf("listening on :%s", cfg.Port)
 	if err := r.Run(":" + cfg.Port); err != nil {
@@ -93,6 +290,80 @@ func main() {
 }
 
 // === Helpers ===
+func mustInvoiceProvider(cfg config.Config) invoice.Provider {
+	switch cfg.InvoiceProvider {
+	case "file", "":
+		return invoice.NewFileProvider(cfg.InvoiceDir)
+	default:
+		log.Fatalf("config: unknown INVOICE_PROVIDER %q", cfg.InvoiceProvider)
+		return nil
+	}
+}
+
+func mustMailer(cfg config.Config) mail.Mailer {
+	switch cfg.MailDriver {
+	case "file", "":
+		return mail.NewFileOutbox(cfg.MailOutboxDir)
+	case "memory":
+		return mail.NewMemoryOutbox(100)
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			log.Fatal("config: SMTP_HOST is required when MAIL_DRIVER=smtp")
+		}
+		return mail.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword)
+	default:
+		log.Fatalf("config: unknown MAIL_DRIVER %q", cfg.MailDriver)
+		return nil
+	}
+}
+
+func mustNotifyChannels(cfg config.Config) []notify.Channel {
+	switch cfg.NotifyDriver {
+	case "fake", "":
+		return []notify.Channel{notify.NewFakeChannel(notify.ChannelSMS), notify.NewFakeChannel(notify.ChannelLINE)}
+	case "live":
+		var chs []notify.Channel
+		if cfg.SMSGatewayURL != "" {
+			chs = append(chs, notify.NewSMSGateway(cfg.SMSGatewayURL, cfg.SMSGatewayKey, cfg.SMSSender))
+		}
+		if cfg.LineChannelToken != "" {
+			chs = append(chs, notify.NewLINEPush(cfg.LineChannelToken))
+		}
+		return chs
+	case "off":
+		return nil
+	default:
+		log.Fatalf("config: unknown NOTIFY_DRIVER %q", cfg.NotifyDriver)
+		return nil
+	}
+}
+
+func mustLogisticsProvider(cfg config.Config) logistics.Provider {
+	production := os.Getenv("APP_ENV") == "production"
+	switch cfg.LogisticsProvider {
+	case "":
+		if production {
+			log.Fatal("config: LOGISTICS_PROVIDER is required when APP_ENV=production")
+		}
+		log.Printf("config: LOGISTICS_PROVIDER not set, using fake store map")
+		return logistics.NewFakeProvider("/api/logistics/fake-map")
+	case "fake":
+		if production {
+			log.Fatal("config: LOGISTICS_PROVIDER=fake is not allowed when APP_ENV=production")
+		}
+		return logistics.NewFakeProvider("/api/logistics/fake-map")
+	case "ecpay":
+		// 電子地圖只需要 MerchantID（回傳不帶 CheckMacValue）；HashKey / HashIV 留給物流訂單 API
+		if cfg.ECPayMerchantID == "" {
+			log.Fatal("config: ECPAY_MERCHANT_ID is required when LOGISTICS_PROVIDER=ecpay")
+		}
+		return logistics.NewECPayProvider(cfg.ECPayMapURL, cfg.ECPayMerchantID, cfg.ECPayHashKey, cfg.ECPayHashIV)
+	default:
+		log.Fatalf("config: unknown LOGISTICS_PROVIDER %q", cfg.LogisticsProvider)
+		return nil
+	}
+}
+
 func safeDSN(dsn string) string {
 	at := strings.LastIndex(dsn, "@")
 	if at == -1 { return maskIfURL(dsn) }
@@ -0,0 +1,61 @@
+// webhook-receiver：本機測試用的 webhook 接收端，驗證簽章後把事件印出來
+//
+//	go run ./cmd/webhook-receiver -secret whsec_xxx -fail 2
+//
+// 伺服器端需設 WEBHOOK_ALLOW_PRIVATE=true 才能投遞到 http://localhost:9000/
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/webhook"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "listen address")
+	secret := flag.String("secret", "", "signing secret (whsec_...); empty = skip verification")
+	failN := flag.Int64("fail", 0, "respond 500 to the first N requests (to exercise retries)")
+	tolerance := flag.Duration("tolerance", 5*time.Minute, "max signature age; 0 = no check")
+	flag.Parse()
+
+	var n atomic.Int64
+	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		event, delivery := r.Header.Get(webhook.HeaderEvent), r.Header.Get(webhook.HeaderDelivery)
+
+		if *secret != "" {
+			if err := webhook.Verify(*secret, r.Header.Get(webhook.HeaderSignature), body, time.Now(), *tolerance); err != nil {
+				log.Printf("REJECT %s %s: %v", event, delivery, err)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+		}
+		if i := n.Add(1); i <= *failN {
+			log.Printf("FAIL (%d/%d) %s %s", i, *failN, event, delivery)
+			http.Error(w, "simulated failure", http.StatusInternalServerError)
+			return
+		}
+
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, body, "", "  ") != nil {
+			pretty.Reset()
+			pretty.Write(body)
+		}
+		log.Printf("OK %s %s\n%s", event, delivery, pretty.String())
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	log.Printf("webhook receiver listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
@@ -0,0 +1,333 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/totp"
+)
+
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// 後台登入 POST /api/admin/login {email, password}
+func (h *Handler) Login(c *gin.Context) {
+	var in struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	res, err := h.svc.Login(c.Request.Context(), in.Email, in.Password)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if res.Challenge != "" {
+		// 已啟用 2FA：改走 POST /api/admin/login/2fa
+		c.JSON(http.StatusOK, gin.H{"twoFactorRequired": true, "challenge": res.Challenge})
+		return
+	}
+	h.loggedIn(c, res)
+}
+
+// 登入第二步 POST /api/admin/login/2fa {challenge, code}
+func (h *Handler) LoginTwoFactor(c *gin.Context) {
+	var in struct {
+		Challenge string `json:"challenge"`
+		Code      string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	res, err := h.svc.LoginTwoFactor(c.Request.Context(), in.Challenge, in.Code)
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "challenge expired, please sign in again"})
+			return
+		}
+		writeError(c, err)
+		return
+	}
+	h.loggedIn(c, res)
+}
+
+func (h *Handler) loggedIn(c *gin.Context, res *LoginResult) {
+	c.JSON(http.StatusOK, gin.H{
+		"token":       res.Token,
+		"expiresIn":   int(tokenTTL.Seconds()),
+		"user":        res.User,
+		"permissions": RolePermissions(res.User.Role),
+		// 政策要求 2FA 但尚未綁定：前端應導向綁定頁
+		"twoFactorSetupRequired": !res.User.TOTP.Enabled() && h.svc.Require2FA(c.Request.Context()),
+	})
+}
+
+// 目前登入者 GET /api/admin/me
+func (h *Handler) Me(c *gin.Context) {
+	p := Current(c)
+	c.JSON(http.StatusOK, gin.H{
+		"user":                   p,
+		"permissions":            RolePermissions(p.Role),
+		"twoFactorSetupRequired": !p.Legacy && !p.TOTP && h.svc.Require2FA(c.Request.Context()),
+	})
+}
+
+// 修改自己的密碼 PUT /api/admin/me/password {currentPassword, newPassword}
+func (h *Handler) ChangePassword(c *gin.Context) {
+	p := Current(c)
+	if p.Legacy {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "legacy token has no password"})
+		return
+	}
+	var in struct {
+		CurrentPassword string `json:"currentPassword"`
+		NewPassword     string `json:"newPassword"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if err := h.svc.SetPassword(c.Request.Context(), p.ID, &in.CurrentPassword, in.NewPassword); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// 帳號列表 GET /api/admin/users
+func (h *Handler) ListUsers(c *gin.Context) {
+	list, err := h.svc.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
+
+// 新增帳號 POST /api/admin/users {email, name, role, password}
+func (h *Handler) CreateUser(c *gin.Context) {
+	var in struct {
+		Email    string `json:"email"`
+		Name     string `json:"name"`
+		Role     string `json:"role"`
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	u, err := h.svc.Create(c.Request.Context(), in.Email, in.Name, in.Role, in.Password)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, u)
+}
+
+// 修改帳號 PUT /api/admin/users/:id {name?, role?, active?}
+func (h *Handler) UpdateUser(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var in struct {
+		Name   *string `json:"name"`
+		Role   *string `json:"role"`
+		Active *bool   `json:"active"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	u, err := h.svc.Update(c.Request.Context(), id, in.Name, in.Role, in.Active)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, u)
+}
+
+// 重設他人密碼 PUT /api/admin/users/:id/password {password}
+func (h *Handler) ResetPassword(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var in struct {
+		Password string `json:"password"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if err := h.svc.SetPassword(c.Request.Context(), id, nil, in.Password); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// 操作紀錄 GET /api/admin/audit-logs?userId=&limit=50&offset=0
+func (h *Handler) AuditLogs(c *gin.Context) {
+	userID, _ := strconv.ParseUint(c.Query("userId"), 10, 64)
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(c.Query("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+	list, total, err := h.svc.AuditLogs(c.Request.Context(), userID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": limit, "offset": offset})
+}
+
+// 雙因素驗證狀態 GET /api/admin/me/2fa
+func (h *Handler) TwoFactor(c *gin.Context) {
+	p := Current(c)
+	if p.Legacy {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "legacy token has no account"})
+		return
+	}
+	f, err := h.svc.TwoFactor(c.Request.Context(), p.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"twoFactor": f, "required": h.svc.Require2FA(c.Request.Context())})
+}
+
+// 開始綁定 POST /api/admin/me/2fa/setup → {secret, uri}（需再呼叫 enable 確認）
+func (h *Handler) SetupTwoFactor(c *gin.Context) {
+	p := Current(c)
+	if p.Legacy {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "legacy token has no account"})
+		return
+	}
+	secret, uri, err := h.svc.SetupTwoFactor(c.Request.Context(), p.ID)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"secret": secret, "uri": uri})
+}
+
+// 確認綁定 POST /api/admin/me/2fa/enable {code} → {recoveryCodes}
+func (h *Handler) EnableTwoFactor(c *gin.Context) {
+	var in struct {
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	codes, err := h.svc.EnableTwoFactor(c.Request.Context(), Current(c).ID, in.Code)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
+
+// 停用 POST /api/admin/me/2fa/disable {password, code}
+func (h *Handler) DisableTwoFactor(c *gin.Context) {
+	var in struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if err := h.svc.DisableTwoFactor(c.Request.Context(), Current(c).ID, in.Password, in.Code); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// 重新產生備用碼 POST /api/admin/me/2fa/recovery-codes {code}
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	var in struct {
+		Code string `json:"code"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	codes, err := h.svc.RegenerateRecoveryCodes(c.Request.Context(), Current(c).ID, in.Code)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"recoveryCodes": codes})
+}
+
+// 清除他人 2FA DELETE /api/admin/users/:id/2fa
+func (h *Handler) ResetTwoFactor(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err := h.svc.ResetTwoFactor(c.Request.Context(), id); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// 安全政策 GET /api/admin/security-policy
+func (h *Handler) SecurityPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"require2fa": h.svc.Require2FA(c.Request.Context())})
+}
+
+// 修改安全政策 PUT /api/admin/security-policy {require2fa}
+func (h *Handler) SetSecurityPolicy(c *gin.Context) {
+	var in struct {
+		Require2FA *bool `json:"require2fa"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil || in.Require2FA == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if err := h.svc.SetRequire2FA(c.Request.Context(), Current(c), *in.Require2FA); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"require2fa": *in.Require2FA})
+}
+
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, totp.ErrLocked):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrTwoFactorRequired):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, totp.ErrAlreadyEnabled):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, totp.ErrInvalidCode), errors.Is(err, totp.ErrNotEnabled),
+		errors.Is(err, totp.ErrNotSetup), errors.Is(err, ErrEnableOwnFirst):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrEmailTaken), errors.Is(err, ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidCredentials), errors.Is(err, ErrInvalidRole),
+		errors.Is(err, ErrInvalidEmail), errors.Is(err, ErrWeakPassword):
+		// 密碼錯誤回 400（401 會讓前端當成登出）
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,116 @@
+package admin
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ctxKey = "admin"
+
+// enrolmentRoutes：尚未綁定 2FA 時仍可使用的端點
+var enrolmentRoutes = map[string]bool{
+	"GET /api/admin/me":             true,
+	"PUT /api/admin/me/password":    true,
+	"GET /api/admin/me/2fa":         true,
+	"POST /api/admin/me/2fa/setup":  true,
+	"POST /api/admin/me/2fa/enable": true,
+}
+
+// bootstrapRoute：舊版 ADMIN_TOKEN 唯一可呼叫的端點（建立第一個帳號）
+const bootstrapRoute = "POST /api/admin/users"
+
+// Current：目前請求的後台使用者（未經 Middleware 時為 nil）
+func Current(c *gin.Context) *Principal {
+	if v, ok := c.Get(ctxKey); ok {
+		if p, ok := v.(*Principal); ok {
+			return p
+		}
+	}
+	return nil
+}
+
+// Middleware：驗證身分、依 routePerms 檢查權限，並記錄異動操作
+// 憑證：Authorization: Bearer <JWT>；或舊版 X-Admin-Token（僅在尚無任何帳號時可建立第一個帳號）
+func (s *Service) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var p *Principal
+		if auth := c.GetHeader("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+			var err error
+			if p, err = s.Authenticate(c.Request.Context(), strings.TrimPrefix(auth, "Bearer ")); err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+				return
+			}
+		} else if s.legacy(c.GetHeader("X-Admin-Token")) {
+			if c.Request.Method+" "+c.FullPath() != bootstrapRoute {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "ADMIN_TOKEN can only create the first admin user"})
+				return
+			}
+			empty, err := s.noUsers(c.Request.Context())
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if !empty {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "ADMIN_TOKEN is disabled once an admin user exists"})
+				return
+			}
+			p = &Principal{Email: "ADMIN_TOKEN", Role: RoleOwner, Legacy: true}
+		} else {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		route := c.Request.Method + " " + c.FullPath()
+		perm, listed := routePerms[route]
+		allowed := false
+		switch {
+		case p.Legacy:
+			allowed = true // 已限定為 bootstrapRoute
+		case !listed:
+			allowed = p.Role == RoleOwner
+		default:
+			allowed = perm == "" || Can(p.Role, perm)
+		}
+		if !allowed {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden", "permission": perm})
+			return
+		}
+		// 政策要求 2FA 而尚未綁定：只能進行綁定
+		if !p.Legacy && !p.TOTP && !enrolmentRoutes[route] && s.Require2FA(c.Request.Context()) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "two-factor authentication required", "code": "TWO_FACTOR_REQUIRED"})
+			return
+		}
+
+		c.Set(ctxKey, p)
+		c.Next()
+
+		if c.Request.Method != http.MethodGet {
+			s.audit(c, p)
+		}
+	}
+}
+
+func (s *Service) audit(c *gin.Context, p *Principal) {
+	entry := AuditLog{
+		UserID: p.ID,
+		Email:  p.Email,
+		Method: c.Request.Method,
+		Path:   truncate(c.Request.URL.Path, 255),
+		Route:  c.FullPath(),
+		Status: c.Writer.Status(),
+		IP:     c.ClientIP(),
+	}
+	if err := s.db.WithContext(c.Request.Context()).Create(&entry).Error; err != nil {
+		log.Printf("admin audit: %v", err)
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,62 @@
+// Package admin 後台帳號：登入（JWT）、角色權限、操作紀錄
+package admin
+
+import (
+	"time"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/totp"
+)
+
+// 角色
+const (
+	RoleOwner      = "owner"      // 全部權限，含管理後台帳號
+	RoleOperator   = "operator"   // 商品、訂單、出貨、廠商審核
+	RoleAccountant = "accountant" // 收款、發票、結算、報表
+	RoleWarehouse  = "warehouse"  // 查單、出貨
+)
+
+func ValidRole(r string) bool {
+	_, ok := rolePerms[r]
+	return ok
+}
+
+type User struct {
+	ID           uint64     `gorm:"primaryKey" json:"id"`
+	Email        string     `gorm:"size:190;uniqueIndex" json:"email"`
+	Name         string     `gorm:"size:100" json:"name"`
+	PasswordHash string     `gorm:"size:100" json:"-"`
+	Role         string     `gorm:"size:16;index" json:"role"`
+	Active       bool       `gorm:"default:true" json:"active"`
+	LastLoginAt  *time.Time `json:"lastLoginAt"`
+	TokenVersion int        `gorm:"not null;default:0" json:"-"` // 改密碼時 +1，先前簽發的 JWT 一律失效
+	CreatedAt    time.Time  `json:"createdAt"`
+	UpdatedAt    time.Time  `json:"updatedAt"`
+
+	TOTP totp.Factor `gorm:"embedded;embeddedPrefix:totp_" json:"twoFactor"`
+}
+
+func (User) TableName() string { return "admin_users" }
+
+// Setting：後台全域設定（目前只有 require_2fa）
+type Setting struct {
+	Key       string    `gorm:"primaryKey;size:64" json:"key"`
+	Value     string    `gorm:"size:255" json:"value"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func (Setting) TableName() string { return "admin_settings" }
+
+// AuditLog：後台異動操作紀錄（GET 不記）
+type AuditLog struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	UserID    uint64    `gorm:"index" json:"userId"` // 0 = 舊版 ADMIN_TOKEN
+	Email     string    `gorm:"size:190" json:"email"`
+	Method    string    `gorm:"size:8" json:"method"`
+	Path      string    `gorm:"size:255" json:"path"`
+	Route     string    `gorm:"size:255;index" json:"route"`
+	Status    int       `json:"status"`
+	IP        string    `gorm:"size:64" json:"ip"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
+
+func (AuditLog) TableName() string { return "admin_audit_logs" }
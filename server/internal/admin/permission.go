@@ -0,0 +1,119 @@
+package admin
+
+// 權限
+const (
+	PermCatalog     = "catalog"      // 商品、商品審核
+	PermOrdersRead  = "orders.read"  // 查詢訂單
+	PermOrdersWrite = "orders.write" // 訂單狀態、刪除
+	PermFulfilment  = "fulfilment"   // 出貨、揀貨單、物流檔
+	PermPayments    = "payments"     // 收款確認、退款
+	PermInvoices    = "invoices"     // 電子發票
+	PermSettlements = "settlements"  // 廠商結算、抽成
+	PermReports     = "reports"      // 營收報表
+	PermVendors     = "vendors"      // 廠商審核 / 停權
+	PermVendorBank  = "vendors.bank" // 廠商撥款帳戶審核
+	PermUsers       = "users"        // 後台帳號、操作紀錄
+)
+
+var rolePerms = map[string][]string{
+	RoleOwner: {
+		PermCatalog, PermOrdersRead, PermOrdersWrite, PermFulfilment, PermPayments,
+		PermInvoices, PermSettlements, PermReports, PermVendors, PermVendorBank, PermUsers,
+	},
+	RoleOperator:   {PermCatalog, PermOrdersRead, PermOrdersWrite, PermFulfilment, PermVendors, PermReports},
+	RoleAccountant: {PermOrdersRead, PermPayments, PermInvoices, PermSettlements, PermReports, PermVendorBank},
+	RoleWarehouse:  {PermOrdersRead, PermFulfilment},
+}
+
+// Can：角色是否具備權限
+func Can(role, perm string) bool {
+	for _, p := range rolePerms[role] {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// RolePermissions：角色的權限清單（前端決定要顯示哪些功能）
+func RolePermissions(role string) []string {
+	return append([]string(nil), rolePerms[role]...)
+}
+
+// routePerms：/api/admin 底下每個端點所需權限（key = "METHOD 路由樣式"）
+// 未列出的端點只有 owner 可用，新增端點時記得補上
+// 權限為空字串 = 登入即可
+var routePerms = map[string]string{
+	"GET /api/admin/me":                     "",
+	"PUT /api/admin/me/password":            "",
+	"GET /api/admin/me/2fa":                 "",
+	"POST /api/admin/me/2fa/setup":          "",
+	"POST /api/admin/me/2fa/enable":         "",
+	"POST /api/admin/me/2fa/disable":        "",
+	"POST /api/admin/me/2fa/recovery-codes": "",
+
+	"GET /api/admin/products":                    PermCatalog,
+	"GET /api/admin/products/:id":                PermCatalog,
+	"POST /api/admin/products":                   PermCatalog,
+	"PUT /api/admin/products/:id":                PermCatalog,
+	"DELETE /api/admin/products/:id":             PermCatalog,
+	"GET /api/admin/product-reviews":             PermCatalog,
+	"PUT /api/admin/product-reviews/:id/approve": PermCatalog,
+	"PUT /api/admin/product-reviews/:id/reject":  PermCatalog,
+
+	"GET /api/admin/orders":                    PermOrdersRead,
+	"GET /api/admin/orders/:id":                PermOrdersRead,
+	"GET /api/admin/orders/:id/history":        PermOrdersRead,
+	"GET /api/admin/orders/:id/invoice":        PermOrdersRead,
+	"PUT /api/admin/orders/:id/status":         PermOrdersWrite,
+	"POST /api/admin/orders/bulk/status":       PermOrdersWrite,
+	"DELETE /api/admin/orders/:id":             PermOrdersWrite,
+	"PUT /api/admin/sub-orders/:id/status":     PermOrdersWrite,
+	"PUT /api/admin/orders/:id/shipment":       PermFulfilment,
+	"POST /api/admin/orders/bulk/shipments":    PermFulfilment,
+	"POST /api/admin/orders/bulk/print":        PermFulfilment,
+	"GET /api/admin/courier/templates":         PermFulfilment,
+	"POST /api/admin/courier/export":           PermFulfilment,
+	"POST /api/admin/courier/:code/import":     PermFulfilment,
+	"GET /api/admin/notifications":             PermOrdersRead,
+	"POST /api/admin/notifications/:id/resend": PermOrdersWrite,
+
+	"PUT /api/admin/orders/:id/payment":           PermPayments,
+	"POST /api/admin/orders/bulk/payment":         PermPayments,
+	"POST /api/admin/orders/items/:itemId/refund": PermPayments,
+	"POST /api/admin/orders/:id/invoice":          PermInvoices,
+	"POST /api/admin/invoices/:id/void":           PermInvoices,
+	"POST /api/admin/invoices/:id/allowances":     PermInvoices,
+	"GET /api/admin/settlements":                  PermSettlements,
+	"POST /api/admin/settlements/generate":        PermSettlements,
+	"GET /api/admin/settlements/:id":              PermSettlements,
+	"GET /api/admin/settlements/:id/csv":          PermSettlements,
+	"PUT /api/admin/settlements/:id/approve":      PermSettlements,
+	"PUT /api/admin/settlements/:id/paid":         PermSettlements,
+	"DELETE /api/admin/settlements/:id":           PermSettlements,
+	"GET /api/admin/commission-rates":             PermSettlements,
+	"PUT /api/admin/commission-rates":             PermSettlements,
+	"DELETE /api/admin/commission-rates/:id":      PermSettlements,
+	"GET /api/admin/reports/summary":              PermReports,
+	"GET /api/admin/reports/revenue":              PermReports,
+	"GET /api/admin/reports/categories":           PermReports,
+	"GET /api/admin/reports/vendors":              PermReports,
+	"GET /api/admin/reports/shipping":             PermReports,
+	"GET /api/admin/vendors":                      PermVendors,
+	"PUT /api/admin/vendors/:id/approve":          PermVendors,
+	"PUT /api/admin/vendors/:id/reject":           PermVendors,
+	"PUT /api/admin/vendors/:id/suspend":          PermVendors,
+	"GET /api/admin/vendors/login-audits":         PermVendors,
+	"POST /api/admin/vendors/:id/unlock":          PermVendors,
+	"GET /api/admin/vendors/bank-reviews":         PermVendorBank,
+	"PUT /api/admin/vendors/:id/bank-review":      PermVendorBank,
+
+	"GET /api/admin/users":              PermUsers,
+	"POST /api/admin/users":             PermUsers,
+	"PUT /api/admin/users/:id":          PermUsers,
+	"PUT /api/admin/users/:id/password": PermUsers,
+	"GET /api/admin/audit-logs":         PermUsers,
+	"DELETE /api/admin/users/:id/2fa":   PermUsers,
+	"GET /api/admin/security-policy":    PermUsers,
+	"PUT /api/admin/security-policy":    PermUsers,
+}
@@ -0,0 +1,324 @@
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/secretbox"
+)
+
+const (
+	tokenIssuer   = "zeusshop"
+	tokenAudience = "admin"
+	tokenTTL      = 12 * time.Hour
+	bcryptCost    = 12
+	minPassword   = 8
+)
+
+// 密碼正確、等待輸入驗證碼的 challenge
+const (
+	challengeAudience = "admin-2fa"
+	challengeTTL      = 5 * time.Minute
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrUserNotFound       = errors.New("admin user not found")
+	ErrEmailTaken         = errors.New("email already used")
+	ErrInvalidRole        = errors.New("invalid role")
+	ErrInvalidEmail       = errors.New("invalid email")
+	ErrWeakPassword       = fmt.Errorf("password must be at least %d characters", minPassword)
+	ErrLastOwner          = errors.New("at least one active owner is required")
+	ErrTwoFactorRequired  = errors.New("two-factor authentication is required for all admins")
+	ErrEnableOwnFirst     = errors.New("enable two-factor authentication on your own account first")
+)
+
+// Principal：目前登入的後台使用者
+type Principal struct {
+	ID     uint64 `json:"id"`
+	Email  string `json:"email"`
+	Name   string `json:"name"`
+	Role   string `json:"role"`
+	Legacy bool   `json:"legacy,omitempty"` // 以 ADMIN_TOKEN 登入（僅能建立第一個帳號）
+	TOTP   bool   `json:"twoFactor"`        // 已啟用雙因素驗證
+}
+
+type Service struct {
+	db          *gorm.DB
+	secret      []byte
+	legacyToken string // 空字串 = 停用
+	box         *secretbox.Box
+	issuer      string // 驗證器 App 顯示的名稱
+
+	policyMu       sync.Mutex
+	require2FA     bool
+	policyLoadedAt time.Time
+}
+
+func NewService(db *gorm.DB, secret, legacyToken string, box *secretbox.Box, issuer string) *Service {
+	return &Service{db: db, secret: []byte(secret), legacyToken: legacyToken, box: box, issuer: issuer}
+}
+
+// LoginResult：Token 與 Challenge 只會有一個；有 Challenge 代表需輸入驗證碼（LoginTwoFactor）
+type LoginResult struct {
+	Token     string
+	Challenge string
+	User      *User
+}
+
+// Login：驗證帳密；未啟用雙因素驗證直接簽發 JWT，否則回傳 challenge
+func (s *Service) Login(ctx context.Context, email, password string) (*LoginResult, error) {
+	var u User
+	err := s.db.WithContext(ctx).Where("email = ?", normalizeEmail(email)).First(&u).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			dummyCompare(password)
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil || !u.Active {
+		return nil, ErrInvalidCredentials
+	}
+	if u.TOTP.Enabled() {
+		challenge, err := s.sign(fmt.Sprint(u.ID), u.TokenVersion, challengeAudience, challengeTTL)
+		if err != nil {
+			return nil, err
+		}
+		return &LoginResult{Challenge: challenge, User: &u}, nil
+	}
+	return s.complete(ctx, &u)
+}
+
+// complete：記錄登入時間並簽發 JWT
+func (s *Service) complete(ctx context.Context, u *User) (*LoginResult, error) {
+	now := time.Now()
+	_ = s.db.WithContext(ctx).Model(&User{ID: u.ID}).Update("last_login_at", now).Error
+	u.LastLoginAt = &now
+	token, err := s.sign(fmt.Sprint(u.ID), u.TokenVersion, tokenAudience, tokenTTL)
+	if err != nil {
+		return nil, err
+	}
+	return &LoginResult{Token: token, User: u}, nil
+}
+
+// tokenClaims：Ver = 簽發當下的 User.TokenVersion
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Ver int `json:"ver"`
+}
+
+func (s *Service) sign(subject string, ver int, audience string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    tokenIssuer,
+			Audience:  jwt.ClaimStrings{audience},
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Ver: ver,
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+}
+
+// parse：驗證 JWT（HS256、issuer、audience、有效期）並回傳 subject 與 token 版本
+func (s *Service) parse(token, audience string) (string, int, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.Subject == "" {
+		return "", 0, ErrInvalidToken
+	}
+	return claims.Subject, claims.Ver, nil
+}
+
+// Authenticate：驗證 Bearer JWT；每次都重新讀取帳號，停用、改角色或改密碼立即生效
+func (s *Service) Authenticate(ctx context.Context, token string) (*Principal, error) {
+	sub, ver, err := s.parse(token, tokenAudience)
+	if err != nil {
+		return nil, err
+	}
+	var u User
+	if err := s.db.WithContext(ctx).Where("id = ?", sub).First(&u).Error; err != nil || !u.Active || u.TokenVersion != ver {
+		return nil, ErrInvalidToken
+	}
+	return &Principal{ID: u.ID, Email: u.Email, Name: u.Name, Role: u.Role, TOTP: u.TOTP.Enabled()}, nil
+}
+
+var (
+	dummyOnce sync.Once
+	dummyHash []byte
+)
+
+// dummyCompare：帳號不存在時仍比對一組假雜湊，讓回應時間一致，無法藉此判斷帳號是否存在
+func dummyCompare(password string) {
+	dummyOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("zeusshop-dummy-password"), bcryptCost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+}
+
+// legacy：舊版共用 ADMIN_TOKEN，僅作為建立第一個帳號用的啟動憑證
+func (s *Service) legacy(token string) bool {
+	return s.legacyToken != "" && token != "" &&
+		subtle.ConstantTimeCompare([]byte(token), []byte(s.legacyToken)) == 1
+}
+
+// noUsers：尚未建立任何後台帳號（ADMIN_TOKEN 只在此時有效）
+func (s *Service) noUsers(ctx context.Context) (bool, error) {
+	var n int64
+	err := s.db.WithContext(ctx).Model(&User{}).Count(&n).Error
+	return n == 0, err
+}
+
+// ---- 帳號管理 ----
+
+func (s *Service) List(ctx context.Context) ([]User, error) {
+	var list []User
+	err := s.db.WithContext(ctx).Order("id ASC").Find(&list).Error
+	return list, err
+}
+
+func (s *Service) Create(ctx context.Context, email, name, role, password string) (*User, error) {
+	if !ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+	u := &User{Email: normalizeEmail(email), Name: strings.TrimSpace(name), Role: role, Active: true, PasswordHash: hash}
+	if u.Email == "" || !strings.Contains(u.Email, "@") {
+		return nil, ErrInvalidEmail
+	}
+	var n int64
+	if err := s.db.WithContext(ctx).Model(&User{}).Where("email = ?", u.Email).Count(&n).Error; err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		return nil, ErrEmailTaken
+	}
+	if err := s.db.WithContext(ctx).Create(u).Error; err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// Update：修改名稱 / 角色 / 啟用狀態（nil = 不變）；不可移除最後一位 owner
+func (s *Service) Update(ctx context.Context, id uint64, name, role *string, active *bool) (*User, error) {
+	var u User
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// 先依 id 順序鎖住所有有效的 owner，再鎖目標帳號：
+		// 併發降級 / 停用不同 owner 時會依序執行，後到的看得到前一筆的結果，不會把 owner 全數移除
+		var owners []uint64
+		if err := tx.Model(&User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("role = ? AND active = ?", RoleOwner, true).
+			Order("id ASC").Pluck("id", &owners).Error; err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&u, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		if name != nil {
+			u.Name = strings.TrimSpace(*name)
+		}
+		if role != nil {
+			if !ValidRole(*role) {
+				return ErrInvalidRole
+			}
+			u.Role = *role
+		}
+		if active != nil {
+			u.Active = *active
+		}
+		if u.Role != RoleOwner || !u.Active {
+			others := 0
+			for _, oid := range owners {
+				if oid != u.ID {
+					others++
+				}
+			}
+			if others == 0 {
+				return ErrLastOwner
+			}
+		}
+		return tx.Model(&User{ID: u.ID}).Updates(map[string]any{
+			"name":   u.Name,
+			"role":   u.Role,
+			"active": u.Active,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+// SetPassword：重設密碼（本人修改時 current 必填）。
+// 同時遞增 TokenVersion，該帳號所有已簽發的 JWT（含本人目前的登入）立即失效，需重新登入
+func (s *Service) SetPassword(ctx context.Context, id uint64, current *string, password string) error {
+	var u User
+	if err := s.db.WithContext(ctx).First(&u, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if current != nil && bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(*current)) != nil {
+		return ErrInvalidCredentials
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Model(&User{ID: u.ID}).Updates(map[string]any{
+		"password_hash": hash,
+		"token_version": gorm.Expr("token_version + 1"),
+	}).Error
+}
+
+func (s *Service) AuditLogs(ctx context.Context, userID uint64, limit, offset int) ([]AuditLog, int64, error) {
+	q := s.db.WithContext(ctx).Model(&AuditLog{})
+	if userID > 0 {
+		q = q.Where("user_id = ?", userID)
+	}
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []AuditLog
+	err := q.Order("id DESC").Limit(limit).Offset(offset).Find(&list).Error
+	return list, total, err
+}
+
+func hashPassword(pw string) (string, error) {
+	if len(pw) < minPassword {
+		return "", ErrWeakPassword
+	}
+	b, err := bcrypt.GenerateFromPassword([]byte(pw), bcryptCost)
+	return string(b), err
+}
+
+func normalizeEmail(e string) string { return strings.ToLower(strings.TrimSpace(e)) }
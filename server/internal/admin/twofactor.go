@@ -0,0 +1,197 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/totp"
+)
+
+const (
+	settingRequire2FA = "require_2fa"
+	policyCacheTTL    = 30 * time.Second
+)
+
+// LoginTwoFactor：登入第二步，以 challenge 與驗證碼（或備用碼）換取 JWT
+func (s *Service) LoginTwoFactor(ctx context.Context, challenge, code string) (*LoginResult, error) {
+	sub, ver, err := s.parse(challenge, challengeAudience)
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseUint(sub, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	u, err := s.updateFactor(ctx, id, func(u *User) error {
+		if !u.Active || u.TokenVersion != ver {
+			return ErrInvalidToken
+		}
+		return u.TOTP.Check(s.box, code, time.Now())
+	})
+	if errors.Is(err, ErrUserNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.complete(ctx, u)
+}
+
+// updateFactor：在交易中以 SELECT ... FOR UPDATE 鎖定帳號後執行 fn，並寫回 2FA 欄位。
+// 同一組驗證碼的併發請求因此依序比對，後到的會看到已用過的時間步而被拒絕。
+// fn 的錯誤（例如驗證碼錯誤）不會回滾交易：錯誤次數與鎖定時間照樣寫回
+func (s *Service) updateFactor(ctx context.Context, id uint64, fn func(u *User) error) (*User, error) {
+	var (
+		u     User
+		fnErr error
+	)
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&u, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUserNotFound
+			}
+			return err
+		}
+		fnErr = fn(&u)
+		return tx.Model(&User{ID: u.ID}).Updates(u.TOTP.Columns()).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &u, fnErr
+}
+
+func (s *Service) saveFactor(ctx context.Context, u *User) error {
+	return s.db.WithContext(ctx).Model(&User{ID: u.ID}).Updates(u.TOTP.Columns()).Error
+}
+
+func (s *Service) get(ctx context.Context, id uint64) (*User, error) {
+	var u User
+	if err := s.db.WithContext(ctx).First(&u, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &u, nil
+}
+
+// TwoFactor：目前的雙因素驗證狀態
+func (s *Service) TwoFactor(ctx context.Context, id uint64) (*totp.Factor, error) {
+	u, err := s.get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return &u.TOTP, nil
+}
+
+// SetupTwoFactor：產生新金鑰（尚未啟用），回傳明碼金鑰與 otpauth URI
+func (s *Service) SetupTwoFactor(ctx context.Context, id uint64) (string, string, error) {
+	u, err := s.get(ctx, id)
+	if err != nil {
+		return "", "", err
+	}
+	secret, uri, err := u.TOTP.Setup(s.box, s.issuer, u.Email)
+	if err != nil {
+		return "", "", err
+	}
+	return secret, uri, s.saveFactor(ctx, u)
+}
+
+// EnableTwoFactor：以驗證碼確認綁定，回傳備用碼明碼（只顯示一次）
+func (s *Service) EnableTwoFactor(ctx context.Context, id uint64, code string) ([]string, error) {
+	var codes []string
+	_, err := s.updateFactor(ctx, id, func(u *User) error {
+		var err error
+		codes, err = u.TOTP.Enable(s.box, code, time.Now())
+		return err
+	})
+	return codes, err
+}
+
+// DisableTwoFactor：本人停用，需密碼與驗證碼；政策要求 2FA 時不可停用
+func (s *Service) DisableTwoFactor(ctx context.Context, id uint64, password, code string) error {
+	if s.Require2FA(ctx) {
+		return ErrTwoFactorRequired
+	}
+	_, err := s.updateFactor(ctx, id, func(u *User) error {
+		if bcrypt.CompareHashAndPassword([]byte(u.PasswordHash), []byte(password)) != nil {
+			return ErrInvalidCredentials
+		}
+		if err := u.TOTP.Check(s.box, code, time.Now()); err != nil {
+			return err
+		}
+		u.TOTP.Disable()
+		return nil
+	})
+	return err
+}
+
+// RegenerateRecoveryCodes：驗證後重新產生備用碼（舊的全部失效）
+func (s *Service) RegenerateRecoveryCodes(ctx context.Context, id uint64, code string) ([]string, error) {
+	var codes []string
+	_, err := s.updateFactor(ctx, id, func(u *User) error {
+		if err := u.TOTP.Check(s.box, code, time.Now()); err != nil {
+			return err
+		}
+		var err error
+		codes, err = u.TOTP.RegenerateRecovery()
+		return err
+	})
+	return codes, err
+}
+
+// ResetTwoFactor：owner 替遺失手機的帳號清除 2FA（下次登入需重新綁定）
+func (s *Service) ResetTwoFactor(ctx context.Context, id uint64) error {
+	u, err := s.get(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.TOTP.Disable()
+	return s.saveFactor(ctx, u)
+}
+
+// Require2FA：是否要求所有後台帳號啟用 2FA（快取 policyCacheTTL）
+func (s *Service) Require2FA(ctx context.Context) bool {
+	s.policyMu.Lock()
+	defer s.policyMu.Unlock()
+	if time.Since(s.policyLoadedAt) < policyCacheTTL {
+		return s.require2FA
+	}
+	var st Setting
+	err := s.db.WithContext(ctx).Where("`key` = ?", settingRequire2FA).Limit(1).Find(&st).Error
+	if err != nil {
+		// 讀取失敗沿用舊值，不快取
+		return s.require2FA
+	}
+	s.require2FA, s.policyLoadedAt = st.Value == "1", time.Now()
+	return s.require2FA
+}
+
+// SetRequire2FA：開啟前操作者本人須已啟用 2FA，避免把自己擋在外面
+func (s *Service) SetRequire2FA(ctx context.Context, p *Principal, require bool) error {
+	if require && !p.Legacy && !p.TOTP {
+		return ErrEnableOwnFirst
+	}
+	value := "0"
+	if require {
+		value = "1"
+	}
+	err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "updated_at"}),
+	}).Create(&Setting{Key: settingRequire2FA, Value: value}).Error
+	if err != nil {
+		return err
+	}
+	s.policyMu.Lock()
+	s.require2FA, s.policyLoadedAt = require, time.Now()
+	s.policyMu.Unlock()
+	return nil
+}
@@ -0,0 +1,221 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/tz"
+)
+
+// RevenueRow：單一區間（日 / 月）的營收
+type RevenueRow struct {
+	Period  string `json:"period"`
+	Orders  int64  `json:"orders"`
+	Units   int64  `json:"units"`
+	Gross   int64  `json:"gross"`   // 商品小計合計（缺貨不計）
+	Refunds int64  `json:"refunds"` // 已退款金額
+	Net     int64  `json:"net"`     // gross - refunds
+}
+
+// GroupRow：依類別 / 廠商 / 寄送方式分組的營收
+type GroupRow struct {
+	Key     string `json:"key"`
+	Label   string `json:"label"`
+	Orders  int64  `json:"orders"`
+	Units   int64  `json:"units"`
+	Gross   int64  `json:"gross"`
+	Refunds int64  `json:"refunds"`
+	Net     int64  `json:"net"`
+}
+
+type AdminSummary struct {
+	Orders            int64 `json:"orders"`
+	Units             int64 `json:"units"`
+	Gross             int64 `json:"gross"`
+	Refunds           int64 `json:"refunds"`
+	Net               int64 `json:"net"`
+	AverageOrderValue int64 `json:"averageOrderValue"` // gross / orders（四捨五入）
+	UnpaidOrders      int64 `json:"unpaidOrders"`      // 區間內尚未確認收款
+	UnpaidAmount      int64 `json:"unpaidAmount"`
+	OutstandingOrders int64 `json:"outstandingOrders"` // 全部期間尚未確認收款
+	OutstandingAmount int64 `json:"outstandingAmount"`
+}
+
+// 全站訂單項目（缺貨項目不計）
+type adminRow struct {
+	OrderID        uint64
+	ShippingMethod string
+	Category       string
+	VendorID       string
+	VendorName     string
+	Quantity       int64
+	Subtotal       int64
+	RefundAmount   int64
+	CreatedAt      time.Time
+}
+
+func (s *Service) adminSales(ctx context.Context, rg Range) ([]adminRow, error) {
+	var rows []adminRow
+	err := s.db.WithContext(ctx).Table("order_items AS oi").
+		Select("oi.order_id, o.shipping_method, COALESCE(p.category, '') AS category, oi.vendor_id, "+
+			"COALESCE(v.name, '') AS vendor_name, oi.quantity, oi.subtotal, oi.refund_amount, o.created_at").
+		Joins("JOIN orders o ON o.id = oi.order_id").
+		Joins("LEFT JOIN products p ON p.id = oi.product_id").
+		Joins("LEFT JOIN vendors v ON v.id = oi.vendor_id").
+		Where("o.created_at >= ? AND o.created_at < ?", rg.From, rg.To).
+		Where("oi.status <> ?", "out_of_stock").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// Revenue：依日 / 月分組（台北時間），無銷售的區間補 0
+func (s *Service) Revenue(ctx context.Context, rg Range, by string) ([]RevenueRow, error) {
+	rows, err := s.adminSales(ctx, rg)
+	if err != nil {
+		return nil, err
+	}
+	groups := aggregate(rows, func(r adminRow) (string, string) {
+		k := bucket(r.CreatedAt.In(tz.Taipei), by)
+		return k, k
+	})
+	out := []RevenueRow{}
+	from, to := rg.From.In(tz.Taipei), rg.To.In(tz.Taipei)
+	for t := truncate(from, by); t.Before(to); t = next(t, by) {
+		k := bucket(t, by)
+		row := RevenueRow{Period: k}
+		if g := groups[k]; g != nil {
+			row.Orders, row.Units, row.Gross, row.Refunds, row.Net = g.Orders, g.Units, g.Gross, g.Refunds, g.Net
+		}
+		out = append(out, row)
+	}
+	return out, nil
+}
+
+// ByCategory：依商品類別（未分類 = 空字串）
+func (s *Service) ByCategory(ctx context.Context, rg Range) ([]GroupRow, error) {
+	return s.grouped(ctx, rg, func(r adminRow) (string, string) {
+		if r.Category == "" {
+			return "", "未分類"
+		}
+		return r.Category, r.Category
+	})
+}
+
+// ByVendor：依廠商（平台自營 = 空字串）
+func (s *Service) ByVendor(ctx context.Context, rg Range) ([]GroupRow, error) {
+	return s.grouped(ctx, rg, func(r adminRow) (string, string) {
+		if r.VendorID == "" {
+			return "", "平台自營"
+		}
+		name := r.VendorName
+		if name == "" {
+			name = r.VendorID
+		}
+		return r.VendorID, name
+	})
+}
+
+// ByShipping：依寄送方式
+func (s *Service) ByShipping(ctx context.Context, rg Range) ([]GroupRow, error) {
+	return s.grouped(ctx, rg, func(r adminRow) (string, string) {
+		return r.ShippingMethod, shippingLabel(r.ShippingMethod)
+	})
+}
+
+// AdminSummary：區間合計、平均客單價與未收款金額
+func (s *Service) AdminSummary(ctx context.Context, rg Range) (*AdminSummary, error) {
+	rows, err := s.adminSales(ctx, rg)
+	if err != nil {
+		return nil, err
+	}
+	out := &AdminSummary{}
+	orders := map[uint64]bool{}
+	for _, r := range rows {
+		out.Units += r.Quantity
+		out.Gross += r.Subtotal
+		out.Refunds += r.RefundAmount
+		orders[r.OrderID] = true
+	}
+	out.Orders = int64(len(orders))
+	out.Net = out.Gross - out.Refunds
+	if out.Orders > 0 {
+		out.AverageOrderValue = (out.Gross + out.Orders/2) / out.Orders
+	}
+
+	type unpaid struct {
+		N      int64
+		Amount int64
+	}
+	var inRange, all unpaid
+	base := func() *gorm.DB {
+		return s.db.WithContext(ctx).Table("orders").
+			Select("COUNT(*) AS n, COALESCE(SUM(total_amount), 0) AS amount").
+			Where("payment_status <> ? AND status <> ?", "paid", "out_of_stock")
+	}
+	if err := base().Where("created_at >= ? AND created_at < ?", rg.From, rg.To).Scan(&inRange).Error; err != nil {
+		return nil, err
+	}
+	if err := base().Scan(&all).Error; err != nil {
+		return nil, err
+	}
+	out.UnpaidOrders, out.UnpaidAmount = inRange.N, inRange.Amount
+	out.OutstandingOrders, out.OutstandingAmount = all.N, all.Amount
+	return out, nil
+}
+
+func (s *Service) grouped(ctx context.Context, rg Range, key func(adminRow) (string, string)) ([]GroupRow, error) {
+	rows, err := s.adminSales(ctx, rg)
+	if err != nil {
+		return nil, err
+	}
+	groups := aggregate(rows, key)
+	out := make([]GroupRow, 0, len(groups))
+	for _, g := range groups {
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Net != out[j].Net {
+			return out[i].Net > out[j].Net
+		}
+		return out[i].Key < out[j].Key
+	})
+	return out, nil
+}
+
+func aggregate(rows []adminRow, key func(adminRow) (string, string)) map[string]*GroupRow {
+	groups := map[string]*GroupRow{}
+	orders := map[string]map[uint64]bool{}
+	for _, r := range rows {
+		k, label := key(r)
+		g := groups[k]
+		if g == nil {
+			g = &GroupRow{Key: k, Label: label}
+			groups[k] = g
+			orders[k] = map[uint64]bool{}
+		}
+		g.Units += r.Quantity
+		g.Gross += r.Subtotal
+		g.Refunds += r.RefundAmount
+		g.Net = g.Gross - g.Refunds
+		orders[k][r.OrderID] = true
+	}
+	for k, g := range groups {
+		g.Orders = int64(len(orders[k]))
+	}
+	return groups
+}
+
+func shippingLabel(m string) string {
+	switch m {
+	case "pickup":
+		return "自取"
+	case "sevencv":
+		return "7-11 店到店"
+	case "home":
+		return "宅配"
+	}
+	return m
+}
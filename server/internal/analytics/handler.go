@@ -0,0 +1,144 @@
+package analytics
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/export"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/tz"
+)
+
+// Handler：後台營收報表
+// 共用參數：?from=2025-09-01&to=2025-09-30（台北時間、含當日，預設本月）&format=json|csv|xlsx
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// GET /api/admin/reports/summary
+func (h *Handler) AdminSummary(c *gin.Context) {
+	rg, ok := adminRange(c)
+	if !ok {
+		return
+	}
+	sum, err := h.svc.AdminSummary(c.Request.Context(), rg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	t := &export.Table{Sheet: "summary", Header: []string{"項目", "數值"}}
+	t.Add("訂單數", sum.Orders)
+	t.Add("件數", sum.Units)
+	t.Add("營收", sum.Gross)
+	t.Add("退款", sum.Refunds)
+	t.Add("淨營收", sum.Net)
+	t.Add("平均客單價", sum.AverageOrderValue)
+	t.Add("區間未收款筆數", sum.UnpaidOrders)
+	t.Add("區間未收款金額", sum.UnpaidAmount)
+	t.Add("累計未收款筆數", sum.OutstandingOrders)
+	t.Add("累計未收款金額", sum.OutstandingAmount)
+	if export.Send(c, c.Query("format"), "summary-"+rangeName(rg), t) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"from": rg.From, "to": rg.To, "summary": sum})
+}
+
+// GET /api/admin/reports/revenue?granularity=day|month
+func (h *Handler) AdminRevenue(c *gin.Context) {
+	rg, ok := adminRange(c)
+	if !ok {
+		return
+	}
+	by := c.DefaultQuery("granularity", ByDay)
+	if by != ByDay && by != ByMonth {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "granularity must be day or month"})
+		return
+	}
+	list, err := h.svc.Revenue(c.Request.Context(), rg, by)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	t := &export.Table{Sheet: "revenue", Header: []string{"期間", "訂單數", "件數", "營收", "退款", "淨營收"}}
+	for _, r := range list {
+		t.Add(r.Period, r.Orders, r.Units, r.Gross, r.Refunds, r.Net)
+	}
+	if export.Send(c, c.Query("format"), "revenue-"+by+"-"+rangeName(rg), t) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"granularity": by, "items": list})
+}
+
+// GET /api/admin/reports/categories
+func (h *Handler) AdminByCategory(c *gin.Context) {
+	h.grouped(c, "categories", "類別", h.svc.ByCategory)
+}
+
+// GET /api/admin/reports/vendors
+func (h *Handler) AdminByVendor(c *gin.Context) {
+	h.grouped(c, "vendors", "廠商", h.svc.ByVendor)
+}
+
+// GET /api/admin/reports/shipping
+func (h *Handler) AdminByShipping(c *gin.Context) {
+	h.grouped(c, "shipping", "寄送方式", h.svc.ByShipping)
+}
+
+func (h *Handler) grouped(c *gin.Context, name, label string, fn func(ctx context.Context, rg Range) ([]GroupRow, error)) {
+	rg, ok := adminRange(c)
+	if !ok {
+		return
+	}
+	list, err := fn(c.Request.Context(), rg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	t := &export.Table{Sheet: name, Header: []string{"代碼", label, "訂單數", "件數", "營收", "退款", "淨營收"}}
+	for _, r := range list {
+		t.Add(r.Key, r.Label, r.Orders, r.Units, r.Gross, r.Refunds, r.Net)
+	}
+	if export.Send(c, c.Query("format"), name+"-"+rangeName(rg), t) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
+
+// adminRange：以台北時間解析 from/to（含當日），預設本月；最長一年
+func adminRange(c *gin.Context) (Range, bool) {
+	now := time.Now().In(tz.Taipei)
+	rg := Range{From: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, tz.Taipei)}
+	rg.To = rg.From.AddDate(0, 1, 0)
+	if v := c.Query("from"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, tz.Taipei)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+			return rg, false
+		}
+		rg.From = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, tz.Taipei)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+			return rg, false
+		}
+		rg.To = t.AddDate(0, 0, 1)
+	}
+	if !rg.From.Before(rg.To) || rg.To.Sub(rg.From) > 366*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid range"})
+		return rg, false
+	}
+	return rg, true
+}
+
+// rangeName：下載檔名用，例 20250901-20250930
+func rangeName(rg Range) string {
+	return rg.From.Format("20060102") + "-" + rg.To.AddDate(0, 0, -1).Format("20060102")
+}
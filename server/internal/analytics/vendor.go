@@ -0,0 +1,253 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/cache"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/tz"
+)
+
+// 統計區間的時間粒度
+const (
+	ByDay   = "day"
+	ByWeek  = "week"
+	ByMonth = "month"
+)
+
+const cacheTTL = 10 * time.Minute
+
+type Service struct {
+	db  *gorm.DB
+	rdb *cache.Redis // 可為 nil（不快取）
+}
+
+func NewService(db *gorm.DB, rdb *cache.Redis) *Service {
+	return &Service{db: db, rdb: rdb}
+}
+
+// Range：查詢區間 [From, To)
+type Range struct {
+	From time.Time
+	To   time.Time
+}
+
+func (r Range) key() string {
+	return r.From.In(tz.Taipei).Format("20060102") + "-" + r.To.In(tz.Taipei).Format("20060102")
+}
+
+type Point struct {
+	Period  string `json:"period"` // 2025-09-14 / 2025-W37 / 2025-09
+	Revenue int64  `json:"revenue"`
+	Units   int64  `json:"units"`
+	Orders  int64  `json:"orders"`
+}
+
+type TopProduct struct {
+	ProductID uint64 `json:"productId"`
+	Name      string `json:"name"`
+	Units     int64  `json:"units"`
+	Revenue   int64  `json:"revenue"`
+}
+
+type Summary struct {
+	Revenue        int64   `json:"revenue"`
+	Units          int64   `json:"units"`
+	Orders         int64   `json:"orders"`
+	Views          int64   `json:"views"`
+	ConversionRate float64 `json:"conversionRate"` // 訂單數 / 瀏覽數
+	Refunds        int64   `json:"refunds"`
+	RefundRate     float64 `json:"refundRate"` // 退款金額 / 營收
+}
+
+// 廠商商品的訂單項目（只計已確認收款的訂單，缺貨項目不計）
+type saleRow struct {
+	OrderID      uint64
+	ProductID    uint64
+	ProductName  string
+	Quantity     int64
+	Subtotal     int64
+	RefundAmount int64
+	CreatedAt    time.Time
+}
+
+func (s *Service) sales(ctx context.Context, vendorID string, rg Range) ([]saleRow, error) {
+	var rows []saleRow
+	err := s.db.WithContext(ctx).Table("order_items AS oi").
+		Select("oi.order_id, oi.product_id, oi.product_name, oi.quantity, oi.subtotal, oi.refund_amount, o.created_at").
+		Joins("JOIN products p ON p.id = oi.product_id").
+		Joins("JOIN orders o ON o.id = oi.order_id").
+		Where("p.vendor_id = ?", vendorID).
+		Where("o.created_at >= ? AND o.created_at < ?", rg.From, rg.To).
+		Where("o.payment_status = ?", "paid").
+		Where("oi.status <> ?", "out_of_stock").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// Series：營收 / 件數 / 訂單數，依粒度分組（台北時間）
+func (s *Service) Series(ctx context.Context, vendorID string, rg Range, by string) ([]Point, error) {
+	var out []Point
+	key := fmt.Sprintf("analytics:vendor:%s:series:%s:%s", vendorID, by, rg.key())
+	if s.cached(ctx, key, &out) {
+		return out, nil
+	}
+	rows, err := s.sales(ctx, vendorID, rg)
+	if err != nil {
+		return nil, err
+	}
+
+	points := map[string]*Point{}
+	orders := map[string]map[uint64]bool{}
+	for _, r := range rows {
+		k := bucket(r.CreatedAt.In(tz.Taipei), by)
+		p := points[k]
+		if p == nil {
+			p = &Point{Period: k}
+			points[k] = p
+			orders[k] = map[uint64]bool{}
+		}
+		p.Revenue += r.Subtotal
+		p.Units += r.Quantity
+		orders[k][r.OrderID] = true
+	}
+	// 沒有銷售的區間補 0，前端畫圖比較方便
+	out = []Point{}
+	from, to := rg.From.In(tz.Taipei), rg.To.In(tz.Taipei)
+	for t := truncate(from, by); t.Before(to); t = next(t, by) {
+		k := bucket(t, by)
+		if p := points[k]; p != nil {
+			p.Orders = int64(len(orders[k]))
+			out = append(out, *p)
+		} else {
+			out = append(out, Point{Period: k})
+		}
+	}
+	s.store(ctx, key, out)
+	return out, nil
+}
+
+// TopProducts：依營收排序的前 limit 名商品
+func (s *Service) TopProducts(ctx context.Context, vendorID string, rg Range, limit int) ([]TopProduct, error) {
+	var out []TopProduct
+	key := fmt.Sprintf("analytics:vendor:%s:top:%d:%s", vendorID, limit, rg.key())
+	if s.cached(ctx, key, &out) {
+		return out, nil
+	}
+	rows, err := s.sales(ctx, vendorID, rg)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[uint64]*TopProduct{}
+	for _, r := range rows {
+		tp := byID[r.ProductID]
+		if tp == nil {
+			tp = &TopProduct{ProductID: r.ProductID, Name: r.ProductName}
+			byID[r.ProductID] = tp
+		}
+		tp.Units += r.Quantity
+		tp.Revenue += r.Subtotal
+	}
+	out = make([]TopProduct, 0, len(byID))
+	for _, tp := range byID {
+		out = append(out, *tp)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Revenue != out[j].Revenue {
+			return out[i].Revenue > out[j].Revenue
+		}
+		return out[i].ProductID < out[j].ProductID
+	})
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	s.store(ctx, key, out)
+	return out, nil
+}
+
+// Summary：區間合計、瀏覽轉換率與退款率
+func (s *Service) Summary(ctx context.Context, vendorID string, rg Range) (*Summary, error) {
+	out := &Summary{}
+	key := fmt.Sprintf("analytics:vendor:%s:summary:%s", vendorID, rg.key())
+	if s.cached(ctx, key, out) {
+		return out, nil
+	}
+	rows, err := s.sales(ctx, vendorID, rg)
+	if err != nil {
+		return nil, err
+	}
+	orders := map[uint64]bool{}
+	for _, r := range rows {
+		out.Revenue += r.Subtotal
+		out.Units += r.Quantity
+		out.Refunds += r.RefundAmount
+		orders[r.OrderID] = true
+	}
+	out.Orders = int64(len(orders))
+
+	if err := s.db.WithContext(ctx).Table("product_view_daily AS v").
+		Select("COALESCE(SUM(v.views), 0)").
+		Joins("JOIN products p ON p.id = v.product_id").
+		Where("p.vendor_id = ? AND v.day >= ? AND v.day < ?", vendorID, rg.From.In(tz.Taipei).Format("2006-01-02"), rg.To.In(tz.Taipei).Format("2006-01-02")).
+		Scan(&out.Views).Error; err != nil {
+		return nil, err
+	}
+	if out.Views > 0 {
+		out.ConversionRate = float64(out.Orders) / float64(out.Views)
+	}
+	if out.Revenue > 0 {
+		out.RefundRate = float64(out.Refunds) / float64(out.Revenue)
+	}
+	s.store(ctx, key, out)
+	return out, nil
+}
+
+// ---- helpers ----
+
+func (s *Service) cached(ctx context.Context, key string, v any) bool {
+	return s.rdb != nil && s.rdb.GetJSON(ctx, key, v)
+}
+
+func (s *Service) store(ctx context.Context, key string, v any) {
+	if s.rdb != nil {
+		s.rdb.SetJSON(ctx, key, v, cacheTTL)
+	}
+}
+
+func bucket(t time.Time, by string) string {
+	switch by {
+	case ByWeek:
+		y, w := t.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", y, w)
+	case ByMonth:
+		return t.Format("2006-01")
+	}
+	return t.Format("2006-01-02")
+}
+
+// truncate：對齊到所屬區間的起點（週一 / 月初）
+func truncate(t time.Time, by string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch by {
+	case ByWeek:
+		offset := (int(day.Weekday()) + 6) % 7 // 週一 = 0
+		return day.AddDate(0, 0, -offset)
+	case ByMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+	}
+	return day
+}
+
+func next(t time.Time, by string) time.Time {
+	switch by {
+	case ByWeek:
+		return t.AddDate(0, 0, 7)
+	case ByMonth:
+		return time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+	}
+	return t.AddDate(0, 0, 1)
+}
@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
 	"log"
 	"time"
 	"github.com/redis/go-redis/v9"
@@ -25,3 +26,17 @@ func (r *Redis) Allow(key string, limit int, window time.Duration) bool {
 	_, _ = pipe.Exec(ctx)
 	return int(incr.Val()) <= limit
 }
+
+// GetJSON: 讀取快取並反序列化；不存在或失敗回 false
+func (r *Redis) GetJSON(ctx context.Context, key string, v any) bool {
+	b, err := r.Get(ctx, key).Bytes()
+	if err != nil { return false }
+	return json.Unmarshal(b, v) == nil
+}
+
+// SetJSON: 序列化後寫入快取（失敗僅忽略，快取不是必要路徑）
+func (r *Redis) SetJSON(ctx context.Context, key string, v any, ttl time.Duration) {
+	b, err := json.Marshal(v)
+	if err != nil { return }
+	_ = r.Set(ctx, key, b, ttl).Err()
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -9,8 +10,53 @@ type Config struct {
 	Port        string
 	DBDSN       string
 	RedisAddr   string
-	AdminToken  string
+	AdminToken  string // 舊版共用金鑰：僅能用來建立後台帳號，建立後請移除
 	CORSOrigins []string
+
+	PublicBaseURL string // 前台對外網址（重設密碼等信件連結），例：https://shop.example.com
+
+	InvoiceProvider string // 電子發票加值中心：目前僅支援 file（本機假服務）
+	InvoiceDir      string // file provider 的輸出目錄
+
+	CommissionDefaultBP int // 廠商預設抽成（萬分比，1000 = 10%）
+
+	DataKey string // 敏感欄位（銀行帳號等）加密金鑰；正式環境必填
+
+	ProductModeration bool // 廠商商品需經後台審核才上線
+
+	CourierTemplates     string // 物流上傳格式設定檔（JSON）；空 = 內建黑貓 / 新竹 / 7-11 格式
+	CourierHome          string // 宅配預設物流代碼（tcat / hct）
+	CourierSenderName    string // 託運單寄件人
+	CourierSenderPhone   string
+	CourierSenderAddress string
+
+	LogisticsProvider string // 超商電子地圖：ecpay / fake（本機假選店頁，正式環境不可用）；正式環境必填
+	ECPayMapURL       string // 綠界電子地圖網址
+	ECPayMerchantID   string
+	ECPayHashKey      string
+	ECPayHashIV       string
+
+	AdminJWTSecret  string // 後台登入 JWT 簽章金鑰；正式環境必填
+	VendorJWTSecret string // 廠商登入 JWT 簽章金鑰（JWT_SECRET）；正式環境必填
+
+	ShopName      string // 通知信上的商店名稱
+	MailDriver    string // smtp / file（寫 .eml 到 MailOutboxDir）/ memory
+	MailFrom      string // 寄件人，例：ZeusShop <no-reply@example.com>
+	MailOutboxDir string
+	SMTPHost      string
+	SMTPPort      int // 465 = implicit TLS，其餘自動 STARTTLS
+	SMTPUsername  string
+	SMTPPassword  string
+
+	NotifyDriver     string // 簡訊 / LINE 通知：fake（只記錄）/ live / off
+	SMSGatewayURL    string // live：HTTP 簡訊閘道；空 = 不發簡訊
+	SMSGatewayKey    string
+	SMSSender        string
+	LineChannelToken string // live：LINE Messaging API channel access token；空 = 不發 LINE
+	LineLoginChannel string // LIFF 所屬的 LINE Login channel ID（驗證買家 ID token）；空 = 不接受 LINE 下單通知
+
+	LowStockThreshold   int  // 庫存降到此數量（含）以下發布 product.low_stock
+	WebhookAllowPrivate bool // 允許 webhook 送往 localhost / 內網位址（本機測試用，正式環境勿開）
 }
 
 func Load() Config {
@@ -18,11 +64,63 @@ func Load() Config {
 		Port:       getenv("PORT", "8080"),
 		DBDSN:      getenv("DB_DSN", "shop:shop@tcp(127.0.0.1:3306)/shop?parseTime=true&charset=utf8mb4"),
 		RedisAddr:  getenv("REDIS_ADDR", "127.0.0.1:6379"),
-		AdminToken: getenv("ADMIN_TOKEN", "change-me"),
+		AdminToken: os.Getenv("ADMIN_TOKEN"),
 		CORSOrigins: func() []string {
 			v := getenv("CORS_ORIGINS", "http://localhost:5173")
 			return strings.Split(v, ",")
 		}(),
+		PublicBaseURL:   strings.TrimRight(getenv("PUBLIC_BASE_URL", "http://localhost:5173"), "/"),
+		InvoiceProvider: getenv("INVOICE_PROVIDER", "file"),
+		InvoiceDir:      getenv("INVOICE_DIR", "./invoices"),
+		CommissionDefaultBP: func() int {
+			n, err := strconv.Atoi(getenv("COMMISSION_DEFAULT_BP", "1000"))
+			if err != nil { return 1000 }
+			return n
+		}(),
+		DataKey: os.Getenv("DATA_KEY"),
+		ProductModeration: func() bool {
+			v := strings.ToLower(getenv("PRODUCT_MODERATION", "false"))
+			return v == "1" || v == "true"
+		}(),
+		CourierTemplates:     os.Getenv("COURIER_TEMPLATES"),
+		CourierHome:          getenv("COURIER_HOME", "tcat"),
+		CourierSenderName:    os.Getenv("COURIER_SENDER_NAME"),
+		CourierSenderPhone:   os.Getenv("COURIER_SENDER_PHONE"),
+		CourierSenderAddress: os.Getenv("COURIER_SENDER_ADDRESS"),
+		LogisticsProvider:    os.Getenv("LOGISTICS_PROVIDER"),
+		ECPayMapURL:          getenv("ECPAY_MAP_URL", "https://logistics.ecpay.com.tw/Express/map"),
+		ECPayMerchantID:      os.Getenv("ECPAY_MERCHANT_ID"),
+		ECPayHashKey:         os.Getenv("ECPAY_HASH_KEY"),
+		ECPayHashIV:          os.Getenv("ECPAY_HASH_IV"),
+		AdminJWTSecret:       os.Getenv("ADMIN_JWT_SECRET"),
+		VendorJWTSecret:      os.Getenv("JWT_SECRET"),
+		ShopName:             getenv("SHOP_NAME", "ZeusShop"),
+		MailDriver:           getenv("MAIL_DRIVER", "file"),
+		MailFrom:             getenv("MAIL_FROM", "ZeusShop <no-reply@localhost>"),
+		MailOutboxDir:        getenv("MAIL_OUTBOX_DIR", "./mail-outbox"),
+		SMTPHost:             os.Getenv("SMTP_HOST"),
+		SMTPPort: func() int {
+			n, err := strconv.Atoi(getenv("SMTP_PORT", "587"))
+			if err != nil { return 587 }
+			return n
+		}(),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		SMTPPassword: os.Getenv("SMTP_PASSWORD"),
+		NotifyDriver:     getenv("NOTIFY_DRIVER", "fake"),
+		SMSGatewayURL:    os.Getenv("SMS_GATEWAY_URL"),
+		SMSGatewayKey:    os.Getenv("SMS_GATEWAY_KEY"),
+		SMSSender:        os.Getenv("SMS_SENDER"),
+		LineChannelToken: os.Getenv("LINE_CHANNEL_TOKEN"),
+		LineLoginChannel: os.Getenv("LINE_LOGIN_CHANNEL_ID"),
+		LowStockThreshold: func() int {
+			n, err := strconv.Atoi(getenv("LOW_STOCK_THRESHOLD", "5"))
+			if err != nil || n < 0 { return 5 }
+			return n
+		}(),
+		WebhookAllowPrivate: func() bool {
+			v := strings.ToLower(getenv("WEBHOOK_ALLOW_PRIVATE", "false"))
+			return v == "1" || v == "true"
+		}(),
 	}
 }
 
@@ -0,0 +1,159 @@
+package courier
+
+import (
+	"archive/zip"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/export"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/order"
+)
+
+// 回傳檔大小上限
+const maxImportSize = 5 << 20
+
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// 後台：可用的物流格式 GET /api/admin/courier/templates
+func (h *Handler) AdminTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"items": h.svc.Templates()})
+}
+
+// 後台：產生託運上傳檔 POST /api/admin/courier/export
+// {ids, carrier?, format?}
+//   - carrier 未指定：依寄送方式分組（宅配 → 預設物流、7-11 → 711）
+//   - format=json 只回分組結果；csv / xlsx 覆寫範本預設格式
+//
+// 單一檔案直接下載；多個物流時打包成 zip。無法匯出的訂單列在 X-Skipped-Orders
+func (h *Handler) AdminExport(c *gin.Context) {
+	var in struct {
+		IDs     []uint64 `json:"ids"`
+		Carrier string   `json:"carrier"`
+		Format  string   `json:"format"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if len(in.IDs) == 0 || len(in.IDs) > order.MaxBulk {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids must contain 1-" + strconv.Itoa(order.MaxBulk) + " orders"})
+		return
+	}
+	files, skipped, err := h.svc.Export(c.Request.Context(), in.Carrier, in.IDs)
+	if err != nil {
+		if errors.Is(err, ErrUnknownTemplate) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if in.Format == export.FormatJSON || len(files) == 0 {
+		c.JSON(http.StatusOK, gin.H{"files": files, "skipped": skipped})
+		return
+	}
+
+	ids := make([]string, 0, len(skipped))
+	for _, s := range skipped {
+		ids = append(ids, strconv.FormatUint(s.ID, 10))
+	}
+	c.Header("X-Skipped-Orders", strings.Join(ids, ","))
+
+	day := time.Now().Format("20060102")
+	if len(files) == 1 {
+		f := files[0]
+		export.Send(c, formatOf(f, in.Format), f.Carrier+"-"+day, f.Table)
+		return
+	}
+
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="courier-`+day+`.zip"`)
+	c.Status(http.StatusOK)
+	zw := zip.NewWriter(c.Writer)
+	for _, f := range files {
+		format := formatOf(f, in.Format)
+		w, err := zw.Create(f.Carrier + "-" + day + "." + format)
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+		if format == export.FormatXLSX {
+			err = export.WriteXLSX(w, f.Table)
+		} else {
+			err = export.WriteCSV(w, f.Table)
+		}
+		if err != nil {
+			_ = c.Error(err)
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+func formatOf(f File, override string) string {
+	switch override {
+	case export.FormatCSV, export.FormatXLSX:
+		return override
+	}
+	if f.Format == export.FormatXLSX {
+		return export.FormatXLSX
+	}
+	return export.FormatCSV
+}
+
+// 後台：匯入物流回傳檔 POST /api/admin/courier/:code/import（multipart: file，csv / xlsx）
+func (h *Handler) AdminImport(c *gin.Context) {
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file required"})
+		return
+	}
+	if fh.Size > maxImportSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file too large"})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+	t, err := export.ReadTable(fh.Filename, f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	results, err := h.svc.Import(c.Request.Context(), c.Param("code"), t)
+	if err != nil {
+		if errors.Is(err, ErrUnknownTemplate) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	failed := 0
+	for _, r := range results {
+		if !r.OK {
+			failed++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"results":   results,
+		"succeeded": len(results) - failed,
+		"failed":    failed,
+	})
+}
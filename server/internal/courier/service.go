@@ -0,0 +1,238 @@
+package courier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/export"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/order"
+)
+
+var ErrUnknownTemplate = errors.New("unknown courier template")
+
+// 品名摘要最長字數（各家託運單的品名欄都很短）
+const itemsMaxRunes = 50
+
+type Service struct {
+	db        *gorm.DB
+	repo      *order.Repo
+	templates []Template
+	sender    Sender
+	homeCode  string // 宅配預設使用的物流（未指定 carrier 時）
+}
+
+func NewService(db *gorm.DB, templates []Template, sender Sender, homeCode string) *Service {
+	return &Service{db: db, repo: order.NewRepo(db), templates: templates, sender: sender, homeCode: homeCode}
+}
+
+func (s *Service) Templates() []Template { return s.templates }
+
+func (s *Service) Template(code string) (Template, bool) {
+	for _, t := range s.templates {
+		if t.Code == code {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// File：一個物流業者的上傳檔
+type File struct {
+	Carrier string        `json:"carrier"`
+	Name    string        `json:"name"`
+	Format  string        `json:"format"`
+	Orders  int           `json:"orders"`
+	Table   *export.Table `json:"-"`
+}
+
+// Export：依寄送方式分組產生上傳檔
+// code 指定時只產生該業者的檔案，不適用的訂單列入 skipped；
+// 未指定時宅配走預設物流、7-11 走 711，自取訂單略過
+func (s *Service) Export(ctx context.Context, code string, ids []uint64) ([]File, []order.BulkResult, error) {
+	var orders []order.Order
+	if err := s.db.WithContext(ctx).Preload("Items").Where("id IN ?", ids).Find(&orders).Error; err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[uint64]order.Order, len(orders))
+	for _, o := range orders {
+		byID[o.ID] = o
+	}
+
+	var only *Template
+	if code != "" {
+		t, ok := s.Template(code)
+		if !ok {
+			return nil, nil, ErrUnknownTemplate
+		}
+		only = &t
+	}
+
+	files := map[string]*File{}
+	var skipped []order.BulkResult
+	for _, id := range ids {
+		o, ok := byID[id]
+		if !ok {
+			skipped = append(skipped, order.BulkResult{ID: id, Error: order.ErrOrderNotFound.Error()})
+			continue
+		}
+		t, ok := s.templateFor(only, o.ShippingMethod)
+		if !ok {
+			skipped = append(skipped, order.BulkResult{ID: id, Error: "no courier for shipping method " + string(o.ShippingMethod)})
+			continue
+		}
+		f := files[t.Code]
+		if f == nil {
+			f = &File{Carrier: t.Code, Name: t.Name, Format: t.Format, Table: &export.Table{Sheet: t.Code}}
+			for _, col := range t.Columns {
+				f.Table.Header = append(f.Table.Header, col.Header)
+			}
+			files[t.Code] = f
+		}
+		f.Table.Rows = append(f.Table.Rows, s.row(t, o))
+		f.Orders++
+	}
+
+	out := make([]File, 0, len(files))
+	for _, f := range files {
+		out = append(out, *f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Carrier < out[j].Carrier })
+	return out, skipped, nil
+}
+
+func (s *Service) templateFor(only *Template, m order.ShippingMethod) (Template, bool) {
+	if only != nil {
+		return *only, only.accepts(m)
+	}
+	switch m {
+	case order.ShippingHome:
+		return s.Template(s.homeCode)
+	case order.Shipping711:
+		return s.Template("711")
+	}
+	return Template{}, false
+}
+
+func (s *Service) row(t Template, o order.Order) []any {
+	yuan := o.TotalAmount / 100 // 金額以「分」儲存，託運單用元
+	row := make([]any, 0, len(t.Columns))
+	for _, col := range t.Columns {
+		var v any
+		switch col.Field {
+		case FieldOrderNo:
+			v = o.OrderNo
+		case FieldBuyerName:
+			v = o.BuyerName
+		case FieldBuyerPhone:
+			v = o.BuyerPhone
+		case FieldAddress:
+			v = o.Address
+		case FieldStoreCode:
+			v = o.StoreCode
+		case FieldStoreName:
+			v = o.StoreName
+		case FieldTotal:
+			v = yuan
+		case FieldCollect:
+			if o.PaymentStatus == order.PaymentPaid {
+				v = int64(0)
+			} else {
+				v = yuan
+			}
+		case FieldQuantity:
+			n := 0
+			for _, it := range o.Items {
+				n += it.Quantity
+			}
+			v = n
+		case FieldItems:
+			v = itemsSummary(o.Items)
+		case FieldSenderName:
+			v = s.sender.Name
+		case FieldSenderPhone:
+			v = s.sender.Phone
+		case FieldSenderAddress:
+			v = s.sender.Address
+		default:
+			v = col.Value
+		}
+		row = append(row, v)
+	}
+	return row
+}
+
+// itemsSummary：「品名 x2、品名」，超過長度截斷
+func itemsSummary(items []order.OrderItem) string {
+	parts := make([]string, 0, len(items))
+	for _, it := range items {
+		if it.Status == order.ItemOutOfStock {
+			continue
+		}
+		p := it.ProductName
+		if it.Quantity > 1 {
+			p += " x" + strconv.Itoa(it.Quantity)
+		}
+		parts = append(parts, p)
+	}
+	s := []rune(strings.Join(parts, "、"))
+	if len(s) > itemsMaxRunes {
+		s = append(s[:itemsMaxRunes-1], '…')
+	}
+	return string(s)
+}
+
+// ImportResult：回傳檔的一列
+type ImportResult struct {
+	Row        int    `json:"row"` // 檔案中的列號（表頭為第 1 列）
+	OrderNo    string `json:"orderNo"`
+	TrackingNo string `json:"trackingNo"`
+	OK         bool   `json:"ok"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Import：讀業者回傳檔，依訂單編號寫入託運單號並標記出貨
+func (s *Service) Import(ctx context.Context, code string, t *export.Table) ([]ImportResult, error) {
+	tpl, ok := s.Template(code)
+	if !ok {
+		return nil, ErrUnknownTemplate
+	}
+	noCol, trCol := t.Col(tpl.OrderNoHeader), t.Col(tpl.TrackingHeader)
+	if noCol < 0 || trCol < 0 {
+		return nil, fmt.Errorf("missing column %q or %q", tpl.OrderNoHeader, tpl.TrackingHeader)
+	}
+
+	out := make([]ImportResult, 0, len(t.Rows))
+	for i := range t.Rows {
+		res := ImportResult{Row: i + 2, OrderNo: t.Cell(i, noCol), TrackingNo: t.Cell(i, trCol)}
+		if res.OrderNo == "" && res.TrackingNo == "" {
+			continue // 空白列
+		}
+		if err := s.attach(ctx, tpl.Code, res.OrderNo, res.TrackingNo); err != nil {
+			res.Error = err.Error()
+		} else {
+			res.OK = true
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+func (s *Service) attach(ctx context.Context, carrier, orderNo, trackingNo string) error {
+	if orderNo == "" {
+		return fmt.Errorf("order number required")
+	}
+	var o order.Order
+	if err := s.db.WithContext(ctx).Select("id").Where("order_no = ?", orderNo).First(&o).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return order.ErrOrderNotFound
+		}
+		return err
+	}
+	return s.repo.AdminAssignShipment(o.ID, carrier, trackingNo)
+}
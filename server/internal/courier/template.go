@@ -0,0 +1,157 @@
+// Package courier 產生物流業者（黑貓 / 新竹物流 / 7-11）的大量託運上傳檔，
+// 並讀取業者回傳的檔案把託運單號寫回訂單
+package courier
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/order"
+)
+
+// 可用的欄位來源（Column.Field）
+const (
+	FieldOrderNo       = "order_no"
+	FieldBuyerName     = "buyer_name"
+	FieldBuyerPhone    = "buyer_phone"
+	FieldAddress       = "address"
+	FieldStoreCode     = "store_code"
+	FieldStoreName     = "store_name"
+	FieldTotal         = "total"    // 訂單金額（元）
+	FieldCollect       = "collect"  // 代收金額（元）：未確認收款的訂單 = 訂單金額，其餘 0
+	FieldQuantity      = "quantity" // 商品總件數
+	FieldItems         = "items"    // 品名摘要
+	FieldSenderName    = "sender_name"
+	FieldSenderPhone   = "sender_phone"
+	FieldSenderAddress = "sender_address"
+	FieldValue         = "value" // 固定值（Column.Value）
+)
+
+// Column：上傳檔的一欄
+type Column struct {
+	Header string `json:"header"`
+	Field  string `json:"field"`
+	Value  string `json:"value,omitempty"`
+}
+
+// Template：一家物流的上傳格式與回傳檔的欄位對應
+type Template struct {
+	Code    string                 `json:"code"`
+	Name    string                 `json:"name"`
+	Methods []order.ShippingMethod `json:"methods"` // 適用的寄送方式
+	Format  string                 `json:"format"`  // 預設輸出格式 csv | xlsx
+	Columns []Column               `json:"columns"`
+	// 回傳檔：以訂單編號對應託運單號
+	OrderNoHeader  string `json:"orderNoHeader"`
+	TrackingHeader string `json:"trackingHeader"`
+}
+
+func (t Template) accepts(m order.ShippingMethod) bool {
+	for _, x := range t.Methods {
+		if x == m {
+			return true
+		}
+	}
+	return false
+}
+
+// Sender：寄件人資料（出現在託運單上）
+type Sender struct {
+	Name    string
+	Phone   string
+	Address string
+}
+
+// DefaultTemplates：內建格式（欄位名稱依各業者大量託運範本；若業者改版可用設定檔覆寫）
+func DefaultTemplates() []Template {
+	return []Template{
+		{
+			Code:    "tcat",
+			Name:    "黑貓宅急便",
+			Methods: []order.ShippingMethod{order.ShippingHome},
+			Format:  "csv",
+			Columns: []Column{
+				{Header: "訂單編號", Field: FieldOrderNo},
+				{Header: "收件人姓名", Field: FieldBuyerName},
+				{Header: "收件人電話", Field: FieldBuyerPhone},
+				{Header: "收件人地址", Field: FieldAddress},
+				{Header: "代收金額", Field: FieldCollect},
+				{Header: "品名", Field: FieldItems},
+				{Header: "件數", Field: FieldValue, Value: "1"},
+				{Header: "寄件人姓名", Field: FieldSenderName},
+				{Header: "寄件人電話", Field: FieldSenderPhone},
+				{Header: "寄件人地址", Field: FieldSenderAddress},
+			},
+			OrderNoHeader:  "訂單編號",
+			TrackingHeader: "託運單號",
+		},
+		{
+			Code:    "hct",
+			Name:    "新竹物流",
+			Methods: []order.ShippingMethod{order.ShippingHome},
+			Format:  "xlsx",
+			Columns: []Column{
+				{Header: "訂單編號", Field: FieldOrderNo},
+				{Header: "收貨人名稱", Field: FieldBuyerName},
+				{Header: "收貨人電話", Field: FieldBuyerPhone},
+				{Header: "收貨人地址", Field: FieldAddress},
+				{Header: "件數", Field: FieldValue, Value: "1"},
+				{Header: "代收款", Field: FieldCollect},
+				{Header: "品名", Field: FieldItems},
+				{Header: "備註", Field: FieldValue},
+			},
+			OrderNoHeader:  "訂單編號",
+			TrackingHeader: "查貨號碼",
+		},
+		{
+			Code:    "711",
+			Name:    "7-11 交貨便",
+			Methods: []order.ShippingMethod{order.Shipping711},
+			Format:  "csv",
+			Columns: []Column{
+				{Header: "訂單編號", Field: FieldOrderNo},
+				{Header: "取件人姓名", Field: FieldBuyerName},
+				{Header: "取件人手機", Field: FieldBuyerPhone},
+				{Header: "取件門市店號", Field: FieldStoreCode},
+				{Header: "取件門市名稱", Field: FieldStoreName},
+				{Header: "商品金額", Field: FieldTotal},
+				{Header: "代收金額", Field: FieldCollect},
+				{Header: "商品名稱", Field: FieldItems},
+			},
+			OrderNoHeader:  "訂單編號",
+			TrackingHeader: "配送編號",
+		},
+	}
+}
+
+// LoadTemplates：path 為空則用內建格式；否則讀 JSON 陣列，同 code 覆寫內建、新 code 則新增
+func LoadTemplates(path string) ([]Template, error) {
+	list := DefaultTemplates()
+	if path == "" {
+		return list, nil
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var custom []Template
+	if err := json.Unmarshal(b, &custom); err != nil {
+		return nil, fmt.Errorf("courier templates: %w", err)
+	}
+	for _, t := range custom {
+		if t.Code == "" || len(t.Columns) == 0 {
+			return nil, fmt.Errorf("courier templates: code and columns are required")
+		}
+		replaced := false
+		for i := range list {
+			if list[i].Code == t.Code {
+				list[i], replaced = t, true
+			}
+		}
+		if !replaced {
+			list = append(list, t)
+		}
+	}
+	return list, nil
+}
@@ -6,9 +6,14 @@ import (
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/admin"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/invoice"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/notify"
 	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/order"
 	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/product"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/settlement"
 	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/webhook"
 )
 
 func MustOpen(dsn string) *gorm.DB {
@@ -17,16 +22,45 @@ func MustOpen(dsn string) *gorm.DB {
 		log.Fatalf("db open: %v", err)
 	}
 
+	// 舊版明碼重設 token 欄位須在 AutoMigrate 前移除
+	if err := models.DropPlainResetTokens(gdb); err != nil {
+		log.Fatalf("db migrate: %v", err)
+	}
+
 	// 啟動時自動建表/更新結構（保留原本並擴充）
 	if err := gdb.AutoMigrate(
 		&product.Product{},
 		&product.ProductImage{}, // ★ 新增：多圖
+		&product.ProductRevision{},
+		&product.ProductViewDaily{},
 		&order.Order{},
 		&order.OrderItem{},
 		&order.OrderCounter{},
+		&order.SubOrder{},
+		&order.OrderHistory{},
+		&order.Migration{},
 		// ★ 廠商登入/重設密碼
 		&models.Vendor{},
 		&models.VendorPasswordReset{},
+		&models.VendorSession{},
+		&models.VendorLoginAudit{},
+		&models.VendorStaff{},
+		&models.VendorAPIKey{},
+		// ★ 電子發票
+		&invoice.Invoice{},
+		&invoice.Allowance{},
+		// ★ 廠商結算
+		&settlement.CommissionRate{},
+		&settlement.Statement{},
+		&settlement.StatementLine{},
+		&settlement.ScheduledRun{},
+		&settlement.GenerateLock{},
+		&admin.User{},
+		&admin.AuditLog{},
+		&admin.Setting{},
+		&notify.Delivery{},
+		&webhook.Subscription{},
+		&webhook.Delivery{},
 	); err != nil {
 		log.Fatalf("db migrate: %v", err)
 	}
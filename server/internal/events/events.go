@@ -0,0 +1,75 @@
+// Package events 行程內的事件匯流排：訂單等業務狀態變更後發布事件，
+// 寄信、簡訊、webhook 等周邊功能以訂閱方式掛上，失敗或變慢都不影響原本的請求
+package events
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// 事件類型
+const (
+	OrderCreated = "order.created" // 顧客下單成功
+	OrderPaid    = "order.paid"    // 後台確認收款
+	OrderShipped = "order.shipped" // 整張訂單出貨（已有物流單號）
+
+	ProductLowStock = "product.low_stock" // 庫存降到低庫存門檻（含）以下
+)
+
+// Types：所有事件類型（webhook 訂閱時檢查用）
+var Types = []string{OrderCreated, OrderPaid, OrderShipped, ProductLowStock}
+
+type Event struct {
+	Type      string
+	OrderID   uint64 // 訂單事件
+	ProductID uint64 // 商品事件
+	At        time.Time
+}
+
+// Handler：訂閱者；在獨立 goroutine 執行
+type Handler func(ctx context.Context, e Event)
+
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[string][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: map[string][]Handler{}}
+}
+
+// Subscribe：typ 為空字串代表訂閱所有事件
+func (b *Bus) Subscribe(typ string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[typ] = append(b.subs[typ], h)
+}
+
+// Publish：非同步通知所有訂閱者，不等待結果
+func (b *Bus) Publish(e Event) {
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	b.mu.RLock()
+	hs := append(append([]Handler(nil), b.subs[e.Type]...), b.subs[""]...)
+	b.mu.RUnlock()
+	for _, h := range hs {
+		go func(h Handler) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("events: %s handler panic: %v", e.Type, r)
+				}
+			}()
+			h(context.Background(), e)
+		}(h)
+	}
+}
+
+// 全站共用的匯流排（訂單 Repo 在多處建立，統一發布到這裡）
+var std = NewBus()
+
+func Subscribe(typ string, h Handler) { std.Subscribe(typ, h) }
+
+func Publish(e Event) { std.Publish(e) }
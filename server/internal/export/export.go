@@ -0,0 +1,97 @@
+// Package export 將表格資料輸出為 CSV 或 XLSX（報表、物流託運單等共用）
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 支援的輸出格式
+const (
+	FormatJSON = "json"
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// Table：第一列為表頭；儲存格可為字串或數字（XLSX 會以數值儲存）
+type Table struct {
+	Sheet  string
+	Header []string
+	Rows   [][]any
+}
+
+func (t *Table) Add(cells ...any) { t.Rows = append(t.Rows, cells) }
+
+// WriteCSV：UTF-8 BOM + CSV，Excel 直接開啟不會亂碼
+func WriteCSV(w io.Writer, t *Table) error {
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if len(t.Header) > 0 {
+		if err := cw.Write(t.Header); err != nil {
+			return err
+		}
+	}
+	rec := make([]string, 0, len(t.Header))
+	for _, row := range t.Rows {
+		rec = rec[:0]
+		for _, cell := range row {
+			rec = append(rec, cellString(cell))
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Send：依 format 回傳下載檔（csv / xlsx）；其他格式回 false 由呼叫端自行輸出 JSON
+func Send(c *gin.Context, format, filename string, t *Table) bool {
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`.csv"`)
+		c.Status(http.StatusOK)
+		if err := WriteCSV(c.Writer, t); err != nil {
+			_ = c.Error(err)
+		}
+		return true
+	case FormatXLSX:
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`.xlsx"`)
+		c.Status(http.StatusOK)
+		if err := WriteXLSX(c.Writer, t); err != nil {
+			_ = c.Error(err)
+		}
+		return true
+	}
+	return false
+}
+
+func cellString(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case int:
+		return strconv.Itoa(x)
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case uint64:
+		return strconv.FormatUint(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case fmt.Stringer:
+		return x.String()
+	}
+	return fmt.Sprint(v)
+}
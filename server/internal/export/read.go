@@ -0,0 +1,185 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// ReadTable：讀取上傳的 CSV / XLSX（依副檔名判斷），第一列視為表頭
+// CSV 若不是 UTF-8 則當作 Big5（物流業者系統匯出的檔案常見）
+func ReadTable(filename string, r io.Reader) (*Table, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var rows [][]string
+	if strings.EqualFold(path.Ext(filename), ".xlsx") {
+		rows, err = readXLSX(data)
+	} else {
+		rows, err = readCSV(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t := &Table{}
+	for i, row := range rows {
+		if i == 0 {
+			for _, h := range row {
+				t.Header = append(t.Header, strings.TrimSpace(h))
+			}
+			continue
+		}
+		cells := make([]any, len(row))
+		for j, v := range row {
+			cells[j] = strings.TrimSpace(v)
+		}
+		t.Rows = append(t.Rows, cells)
+	}
+	if len(t.Header) == 0 {
+		return nil, fmt.Errorf("empty file")
+	}
+	return t, nil
+}
+
+// Col：表頭名稱所在欄位（找不到回 -1）
+func (t *Table) Col(header string) int {
+	for i, h := range t.Header {
+		if h == header {
+			return i
+		}
+	}
+	return -1
+}
+
+// Cell：第 row 列第 col 欄的字串值（越界回空字串）
+func (t *Table) Cell(row, col int) string {
+	if row < 0 || row >= len(t.Rows) || col < 0 || col >= len(t.Rows[row]) {
+		return ""
+	}
+	return cellString(t.Rows[row][col])
+}
+
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))
+	if !utf8.Valid(data) {
+		dec, err := traditionalchinese.Big5.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported encoding: %w", err)
+		}
+		data = dec
+	}
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+	return cr.ReadAll()
+}
+
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline string `xml:"is>t"`
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		Cells []xlsxCell `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type xlsxShared struct {
+	Items []struct {
+		Text string   `xml:"t"`
+		Runs []string `xml:"r>t"`
+	} `xml:"si"`
+}
+
+// readXLSX：只讀第一個工作表的文字值（公式取快取結果）
+func readXLSX(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid xlsx: %w", err)
+	}
+	var shared []string
+	var sheets []*zip.File
+	for _, f := range zr.File {
+		switch {
+		case f.Name == "xl/sharedStrings.xml":
+			var ss xlsxShared
+			if err := decodeZipXML(f, &ss); err != nil {
+				return nil, err
+			}
+			for _, it := range ss.Items {
+				shared = append(shared, it.Text+strings.Join(it.Runs, ""))
+			}
+		case strings.HasPrefix(f.Name, "xl/worksheets/") && strings.HasSuffix(f.Name, ".xml"):
+			sheets = append(sheets, f)
+		}
+	}
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("invalid xlsx: no worksheet")
+	}
+	sort.Slice(sheets, func(i, j int) bool { return sheets[i].Name < sheets[j].Name })
+
+	var sh xlsxSheet
+	if err := decodeZipXML(sheets[0], &sh); err != nil {
+		return nil, err
+	}
+	rows := make([][]string, 0, len(sh.Rows))
+	for _, r := range sh.Rows {
+		var row []string
+		for i, c := range r.Cells {
+			col := i
+			if c.Ref != "" {
+				col = colIndex(c.Ref)
+			}
+			for len(row) <= col {
+				row = append(row, "")
+			}
+			switch c.Type {
+			case "s":
+				if n, err := strconv.Atoi(c.Value); err == nil && n >= 0 && n < len(shared) {
+					row[col] = shared[n]
+				}
+			case "inlineStr":
+				row[col] = c.Inline
+			default:
+				row[col] = c.Value
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func decodeZipXML(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// colIndex：儲存格參照（例 AB12）的欄位索引，A = 0
+func colIndex(ref string) int {
+	n := 0
+	for _, ch := range ref {
+		if ch < 'A' || ch > 'Z' {
+			break
+		}
+		n = n*26 + int(ch-'A'+1)
+	}
+	return n - 1
+}
@@ -0,0 +1,101 @@
+package invoice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileProvider：本機開發用的假加值中心，把每次開立/作廢/折讓寫成 JSON 檔
+type FileProvider struct {
+	dir string
+	mu  sync.Mutex
+}
+
+func NewFileProvider(dir string) *FileProvider {
+	return &FileProvider{dir: dir}
+}
+
+type fileState struct {
+	NextInvoice   int `json:"nextInvoice"`
+	NextAllowance int `json:"nextAllowance"`
+}
+
+func (p *FileProvider) Issue(ctx context.Context, req IssueRequest) (*IssueResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	st, err := p.loadState()
+	if err != nil {
+		return nil, err
+	}
+	st.NextInvoice++
+	res := &IssueResult{
+		Number:     fmt.Sprintf("ZS%08d", st.NextInvoice),
+		RandomCode: fmt.Sprintf("%04d", rand.Intn(10000)),
+		IssuedAt:   time.Now(),
+	}
+	if err := p.write("issue-"+res.Number, map[string]any{"request": req, "result": res}); err != nil {
+		return nil, err
+	}
+	return res, p.saveState(st)
+}
+
+func (p *FileProvider) Void(ctx context.Context, req VoidRequest) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.write("void-"+req.Number, map[string]any{"request": req, "voidedAt": time.Now()})
+}
+
+func (p *FileProvider) Allowance(ctx context.Context, req AllowanceRequest) (*AllowanceResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	st, err := p.loadState()
+	if err != nil {
+		return nil, err
+	}
+	st.NextAllowance++
+	res := &AllowanceResult{Number: fmt.Sprintf("AL%s%06d", time.Now().Format("20060102"), st.NextAllowance)}
+	if err := p.write("allowance-"+res.Number, map[string]any{"request": req, "result": res}); err != nil {
+		return nil, err
+	}
+	return res, p.saveState(st)
+}
+
+// ---- helpers ----
+
+func (p *FileProvider) loadState() (*fileState, error) {
+	st := &fileState{}
+	b, err := os.ReadFile(filepath.Join(p.dir, "state.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return st, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(b, st); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+func (p *FileProvider) saveState(st *fileState) error {
+	return p.write("state", st)
+}
+
+func (p *FileProvider) write(name string, v any) error {
+	if err := os.MkdirAll(p.dir, 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(p.dir, name+".json"), b, 0o644)
+}
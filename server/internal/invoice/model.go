@@ -0,0 +1,69 @@
+package invoice
+
+import "time"
+
+// 發票類型
+type Type string
+
+const (
+	TypeMember   Type = "member"   // 會員載具（未指定時的預設）
+	TypeMobile   Type = "mobile"   // 手機條碼載具
+	TypeCitizen  Type = "citizen"  // 自然人憑證載具
+	TypeDonation Type = "donation" // 捐贈（愛心碼）
+	TypeCompany  Type = "company"  // 三聯式（統一編號 + 抬頭）
+)
+
+// 發票狀態
+const (
+	StatusIssued = "issued"
+	StatusVoid   = "void"
+)
+
+// 結帳時前端送來的發票資訊；同時以 embedded 方式存在 orders 表（invoice_ 前綴）
+type Request struct {
+	Type       Type   `gorm:"size:16" json:"type"`
+	Carrier    string `gorm:"size:64" json:"carrier"`   // 手機條碼 / 自然人憑證號碼
+	DonateCode string `gorm:"size:7" json:"donateCode"` // 愛心碼
+	TaxID      string `gorm:"size:8" json:"taxId"`      // 統一編號
+	Title      string `gorm:"size:120" json:"title"`    // 公司抬頭
+}
+
+// 已開立的發票（金額皆為新台幣元，不同於訂單的「分」）
+type Invoice struct {
+	ID          uint64      `gorm:"primaryKey" json:"id"`
+	OrderID     uint64      `gorm:"index;not null" json:"orderId"`
+	OrderNo     string      `gorm:"size:32;index" json:"orderNo"`
+	Number      string      `gorm:"size:10;uniqueIndex" json:"number"` // 字軌號碼，例：ZS00000001
+	RandomCode  string      `gorm:"size:4" json:"randomCode"`
+	Type        Type        `gorm:"size:16" json:"type"`
+	Carrier     string      `gorm:"size:64" json:"carrier"`
+	DonateCode  string      `gorm:"size:7" json:"donateCode"`
+	TaxID       string      `gorm:"size:8" json:"taxId"`
+	Title       string      `gorm:"size:120" json:"title"`
+	SalesAmount int64       `json:"salesAmount"` // 未稅金額
+	TaxAmount   int64       `json:"taxAmount"`
+	TotalAmount int64       `json:"totalAmount"`
+	Allowed     int64       `json:"allowed"` // 已折讓金額
+	Status      string      `gorm:"size:16;index" json:"status"`
+	VoidReason  string      `gorm:"size:255" json:"voidReason"`
+	IssuedAt    time.Time   `json:"issuedAt"`
+	VoidedAt    *time.Time  `json:"voidedAt"`
+	CreatedAt   time.Time   `json:"createdAt"`
+	UpdatedAt   time.Time   `json:"updatedAt"`
+	Allowances  []Allowance `json:"allowances,omitempty"`
+}
+
+func (Invoice) TableName() string { return "invoices" }
+
+// 折讓單
+type Allowance struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	InvoiceID uint64    `gorm:"index;not null" json:"invoiceId"`
+	Number    string    `gorm:"size:32;uniqueIndex" json:"number"`
+	Amount    int64     `json:"amount"`
+	TaxAmount int64     `json:"taxAmount"`
+	Reason    string    `gorm:"size:255" json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func (Allowance) TableName() string { return "invoice_allowances" }
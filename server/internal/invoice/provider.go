@@ -0,0 +1,53 @@
+package invoice
+
+import (
+	"context"
+	"time"
+)
+
+// Provider：加值中心 / 財政部平台的抽象；正式串接時實作此介面即可
+type Provider interface {
+	Issue(ctx context.Context, req IssueRequest) (*IssueResult, error)
+	Void(ctx context.Context, req VoidRequest) error
+	Allowance(ctx context.Context, req AllowanceRequest) (*AllowanceResult, error)
+}
+
+type Item struct {
+	Name      string `json:"name"`
+	UnitPrice int64  `json:"unitPrice"`
+	Quantity  int    `json:"quantity"`
+	Amount    int64  `json:"amount"`
+}
+
+type IssueRequest struct {
+	OrderNo     string  `json:"orderNo"`
+	BuyerName   string  `json:"buyerName"`
+	BuyerPhone  string  `json:"buyerPhone"`
+	Invoice     Request `json:"invoice"`
+	Items       []Item  `json:"items"`
+	SalesAmount int64   `json:"salesAmount"`
+	TaxAmount   int64   `json:"taxAmount"`
+	TotalAmount int64   `json:"totalAmount"`
+}
+
+type IssueResult struct {
+	Number     string    `json:"number"`
+	RandomCode string    `json:"randomCode"`
+	IssuedAt   time.Time `json:"issuedAt"`
+}
+
+type VoidRequest struct {
+	Number string `json:"number"`
+	Reason string `json:"reason"`
+}
+
+type AllowanceRequest struct {
+	InvoiceNumber string `json:"invoiceNumber"`
+	Amount        int64  `json:"amount"`
+	TaxAmount     int64  `json:"taxAmount"`
+	Reason        string `json:"reason"`
+}
+
+type AllowanceResult struct {
+	Number string `json:"number"`
+}
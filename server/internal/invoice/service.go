@@ -0,0 +1,233 @@
+package invoice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrAlreadyIssued = errors.New("invoice already issued")
+	ErrNotIssued     = errors.New("invoice is not in issued state")
+	ErrHasAllowances = errors.New("invoice has allowances")
+	ErrInvalidAmount = errors.New("invalid allowance amount")
+	ErrOrderNotFound = errors.New("order not found")
+	ErrProvider      = errors.New("invoice provider error")
+)
+
+type Service struct {
+	db       *gorm.DB
+	provider Provider
+}
+
+func NewService(db *gorm.DB, provider Provider) *Service {
+	return &Service{db: db, provider: provider}
+}
+
+// 只讀需要的欄位，避免 invoice 依賴 order 套件
+type orderRow struct {
+	ID          uint64
+	OrderNo     string
+	BuyerName   string
+	BuyerPhone  string
+	TotalAmount int64
+	Invoice     Request `gorm:"embedded;embeddedPrefix:invoice_"`
+}
+
+type orderItemRow struct {
+	ProductName string
+	UnitPrice   int64
+	Quantity    int
+	Subtotal    int64
+}
+
+// IssueForOrder：依訂單上的發票資訊開立；同一訂單只能有一張有效發票
+// 鎖住訂單列直到寫入完成，避免同時兩個請求都向加值中心開立
+func (s *Service) IssueForOrder(ctx context.Context, orderID uint64) (*Invoice, error) {
+	var out *Invoice
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var o orderRow
+		if err := tx.Table("orders").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", orderID).Take(&o).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrOrderNotFound
+			}
+			return err
+		}
+
+		var n int64
+		if err := tx.Model(&Invoice{}).
+			Where("order_id = ? AND status = ?", orderID, StatusIssued).
+			Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return ErrAlreadyIssued
+		}
+
+		// 舊訂單沒有發票資訊時以會員載具開立
+		req := o.Invoice
+		if err := req.Validate(); err != nil {
+			return err
+		}
+
+		var rows []orderItemRow
+		if err := tx.Table("order_items").Where("order_id = ?", orderID).Order("id ASC").Find(&rows).Error; err != nil {
+			return err
+		}
+		// 訂單以「分」儲存，發票金額與稅額一律為新台幣元
+		items := make([]Item, 0, len(rows))
+		for _, it := range rows {
+			items = append(items, Item{Name: it.ProductName, UnitPrice: toNTD(it.UnitPrice), Quantity: it.Quantity, Amount: toNTD(it.Subtotal)})
+		}
+
+		total := toNTD(o.TotalAmount)
+		sales, tax := splitTax(total)
+		res, err := s.provider.Issue(ctx, IssueRequest{
+			OrderNo:     o.OrderNo,
+			BuyerName:   o.BuyerName,
+			BuyerPhone:  o.BuyerPhone,
+			Invoice:     req,
+			Items:       items,
+			SalesAmount: sales,
+			TaxAmount:   tax,
+			TotalAmount: total,
+		})
+		if err != nil {
+			return fmt.Errorf("%w: issue: %v", ErrProvider, err)
+		}
+
+		inv := &Invoice{
+			OrderID:     o.ID,
+			OrderNo:     o.OrderNo,
+			Number:      res.Number,
+			RandomCode:  res.RandomCode,
+			Type:        req.Type,
+			Carrier:     req.Carrier,
+			DonateCode:  req.DonateCode,
+			TaxID:       req.TaxID,
+			Title:       req.Title,
+			SalesAmount: sales,
+			TaxAmount:   tax,
+			TotalAmount: total,
+			Status:      StatusIssued,
+			IssuedAt:    res.IssuedAt,
+		}
+		if err := tx.Create(inv).Error; err != nil {
+			return err
+		}
+		out = inv
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Void：作廢發票；已開過折讓的發票不可作廢（須先作廢折讓）。
+// 與 AddAllowance 一樣鎖住發票列直到寫入完成，兩者不會交錯
+func (s *Service) Void(ctx context.Context, id uint64, reason string) (*Invoice, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, fmt.Errorf("reason required")
+	}
+	var inv Invoice
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&inv, id).Error; err != nil {
+			return err
+		}
+		if inv.Status != StatusIssued {
+			return ErrNotIssued
+		}
+		if inv.Allowed > 0 {
+			return ErrHasAllowances
+		}
+		if err := s.provider.Void(ctx, VoidRequest{Number: inv.Number, Reason: reason}); err != nil {
+			return fmt.Errorf("%w: void: %v", ErrProvider, err)
+		}
+		now := time.Now()
+		if err := tx.Model(&inv).Updates(map[string]any{
+			"status":      StatusVoid,
+			"void_reason": reason,
+			"voided_at":   now,
+		}).Error; err != nil {
+			return err
+		}
+		inv.Status, inv.VoidReason, inv.VoidedAt = StatusVoid, reason, &now
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &inv, nil
+}
+
+// AddAllowance：開立折讓（累計不可超過發票金額）
+func (s *Service) AddAllowance(ctx context.Context, id uint64, amount int64, reason string) (*Allowance, error) {
+	var out *Allowance
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var inv Invoice
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&inv, id).Error; err != nil {
+			return err
+		}
+		if inv.Status != StatusIssued {
+			return ErrNotIssued
+		}
+		if amount <= 0 || inv.Allowed+amount > inv.TotalAmount {
+			return ErrInvalidAmount
+		}
+		_, tax := splitTax(amount)
+		res, err := s.provider.Allowance(ctx, AllowanceRequest{
+			InvoiceNumber: inv.Number,
+			Amount:        amount,
+			TaxAmount:     tax,
+			Reason:        reason,
+		})
+		if err != nil {
+			return fmt.Errorf("%w: allowance: %v", ErrProvider, err)
+		}
+		a := &Allowance{InvoiceID: inv.ID, Number: res.Number, Amount: amount, TaxAmount: tax, Reason: reason}
+		if err := tx.Create(a).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&inv).Update("allowed", gorm.Expr("allowed + ?", amount)).Error; err != nil {
+			return err
+		}
+		out = a
+		return nil
+	})
+	return out, err
+}
+
+// ListByOrder：某訂單的所有發票（含折讓）
+func (s *Service) ListByOrder(ctx context.Context, orderID uint64) ([]Invoice, error) {
+	var list []Invoice
+	if err := s.db.WithContext(ctx).
+		Preload("Allowances").
+		Where("order_id = ?", orderID).
+		Order("id DESC").
+		Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// 含稅總額（新台幣元）拆成未稅金額與 5% 營業稅（四捨五入）
+func splitTax(total int64) (sales, tax int64) {
+	sales = (total*200 + 105) / 210
+	return sales, total - sales
+}
+
+// toNTD：訂單金額（分）→ 新台幣元，四捨五入
+func toNTD(cents int64) int64 {
+	if cents < 0 {
+		return -toNTD(-cents)
+	}
+	return (cents + 50) / 100
+}
@@ -0,0 +1,43 @@
+package invoice
+
+import "testing"
+
+func TestSplitTax(t *testing.T) {
+	cases := []struct{ total, sales, tax int64 }{
+		{0, 0, 0},
+		{1, 1, 0},
+		{10, 10, 0},
+		{11, 10, 1},
+		{21, 20, 1},
+		{105, 100, 5},
+		{100, 95, 5},
+		{1000, 952, 48},
+		{1234, 1175, 59},
+		{99999, 95237, 4762},
+	}
+	for _, tc := range cases {
+		sales, tax := splitTax(tc.total)
+		if sales != tc.sales || tax != tc.tax {
+			t.Errorf("splitTax(%d) = %d, %d; want %d, %d", tc.total, sales, tax, tc.sales, tc.tax)
+		}
+		if sales+tax != tc.total {
+			t.Errorf("splitTax(%d): %d + %d != total", tc.total, sales, tax)
+		}
+	}
+}
+
+func TestToNTD(t *testing.T) {
+	cases := []struct{ cents, want int64 }{
+		{0, 0},
+		{100, 1},
+		{123400, 1234},
+		{149, 1},
+		{150, 2},
+		{-150, -2},
+	}
+	for _, tc := range cases {
+		if got := toNTD(tc.cents); got != tc.want {
+			t.Errorf("toNTD(%d) = %d, want %d", tc.cents, got, tc.want)
+		}
+	}
+}
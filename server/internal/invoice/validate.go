@@ -0,0 +1,81 @@
+package invoice
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	reMobile   = regexp.MustCompile(`^/[0-9A-Z.+\-]{7}$`)  // 手機條碼：/ + 7 碼
+	reCitizen  = regexp.MustCompile(`^[A-Z]{2}[0-9]{14}$`) // 自然人憑證：2 英 + 14 數
+	reDonation = regexp.MustCompile(`^[0-9]{3,7}$`)        // 愛心碼：3~7 碼數字
+	reTaxID    = regexp.MustCompile(`^[0-9]{8}$`)
+)
+
+// Normalize：去空白、載具轉大寫；未指定類型時視為會員載具
+func (r *Request) Normalize() {
+	r.Type = Type(strings.ToLower(strings.TrimSpace(string(r.Type))))
+	if r.Type == "" {
+		r.Type = TypeMember
+	}
+	r.Carrier = strings.ToUpper(strings.TrimSpace(r.Carrier))
+	r.DonateCode = strings.TrimSpace(r.DonateCode)
+	r.TaxID = strings.TrimSpace(r.TaxID)
+	r.Title = strings.TrimSpace(r.Title)
+}
+
+// Validate：依類型檢查必要欄位與格式，並清掉不相關的欄位
+func (r *Request) Validate() error {
+	r.Normalize()
+	switch r.Type {
+	case TypeMember:
+		r.Carrier, r.DonateCode, r.TaxID, r.Title = "", "", "", ""
+	case TypeMobile:
+		if !reMobile.MatchString(r.Carrier) {
+			return fmt.Errorf("invalid mobile barcode")
+		}
+		r.DonateCode, r.TaxID, r.Title = "", "", ""
+	case TypeCitizen:
+		if !reCitizen.MatchString(r.Carrier) {
+			return fmt.Errorf("invalid citizen certificate")
+		}
+		r.DonateCode, r.TaxID, r.Title = "", "", ""
+	case TypeDonation:
+		if !reDonation.MatchString(r.DonateCode) {
+			return fmt.Errorf("invalid donate code")
+		}
+		r.Carrier, r.TaxID, r.Title = "", "", ""
+	case TypeCompany:
+		if !ValidTaxID(r.TaxID) {
+			return fmt.Errorf("invalid tax id")
+		}
+		if r.Title == "" || utf8.RuneCountInString(r.Title) > 60 {
+			return fmt.Errorf("invalid company title")
+		}
+		r.Carrier, r.DonateCode = "", ""
+	default:
+		return fmt.Errorf("invalid invoice type")
+	}
+	return nil
+}
+
+// ValidTaxID：統一編號檢查碼
+// 權數 1,2,1,2,1,2,4,1，各位乘積的十位數與個位數相加後加總，可被 5 整除即為有效；
+// 第 7 碼為 7 時，加總 +1 可被 5 整除亦有效。
+func ValidTaxID(id string) bool {
+	if !reTaxID.MatchString(id) {
+		return false
+	}
+	weights := [8]int{1, 2, 1, 2, 1, 2, 4, 1}
+	sum := 0
+	for i := 0; i < 8; i++ {
+		p := int(id[i]-'0') * weights[i]
+		sum += p/10 + p%10
+	}
+	if sum%5 == 0 {
+		return true
+	}
+	return id[6] == '7' && (sum+1)%5 == 0
+}
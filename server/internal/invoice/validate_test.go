@@ -0,0 +1,62 @@
+package invoice
+
+import "testing"
+
+func TestValidTaxID(t *testing.T) {
+	cases := []struct {
+		id   string
+		want bool
+	}{
+		{"22099131", true},  // 加總 30
+		{"04541302", true},  // 加總 20
+		{"00000074", true},  // 第 7 碼為 7：加總 14，+1 可被 5 整除
+		{"00000073", false}, // 第 7 碼為 7 但 +1 仍不可整除
+		{"00000084", false}, // 第 7 碼不是 7 不適用 +1
+		{"22099132", false},
+		{"2209913", false},
+		{"220991311", false},
+		{"2209913A", false},
+		{"", false},
+	}
+	for _, tc := range cases {
+		if got := ValidTaxID(tc.id); got != tc.want {
+			t.Errorf("ValidTaxID(%q) = %v, want %v", tc.id, got, tc.want)
+		}
+	}
+}
+
+func TestRequestValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		in   Request
+		ok   bool
+		want Request // ok 時正規化後的結果
+	}{
+		{"default member", Request{Carrier: "/ABC1234"}, true, Request{Type: TypeMember}},
+		{"mobile upper-cased", Request{Type: " Mobile ", Carrier: " /abc+.-1 ", TaxID: "22099131"}, true, Request{Type: TypeMobile, Carrier: "/ABC+.-1"}},
+		{"mobile missing slash", Request{Type: TypeMobile, Carrier: "ABC12345"}, false, Request{}},
+		{"mobile too short", Request{Type: TypeMobile, Carrier: "/ABC123"}, false, Request{}},
+		{"mobile bad char", Request{Type: TypeMobile, Carrier: "/ABC_123"}, false, Request{}},
+		{"citizen", Request{Type: TypeCitizen, Carrier: "ab12345678901234"}, true, Request{Type: TypeCitizen, Carrier: "AB12345678901234"}},
+		{"citizen too short", Request{Type: TypeCitizen, Carrier: "AB1234567890123"}, false, Request{}},
+		{"donation", Request{Type: TypeDonation, DonateCode: " 168 ", Carrier: "/ABC1234"}, true, Request{Type: TypeDonation, DonateCode: "168"}},
+		{"donation too long", Request{Type: TypeDonation, DonateCode: "12345678"}, false, Request{}},
+		{"donation letters", Request{Type: TypeDonation, DonateCode: "12A"}, false, Request{}},
+		{"company", Request{Type: TypeCompany, TaxID: "22099131", Title: " 台灣積體電路製造股份有限公司 "}, true, Request{Type: TypeCompany, TaxID: "22099131", Title: "台灣積體電路製造股份有限公司"}},
+		{"company bad tax id", Request{Type: TypeCompany, TaxID: "22099132", Title: "X"}, false, Request{}},
+		{"company no title", Request{Type: TypeCompany, TaxID: "22099131"}, false, Request{}},
+		{"unknown type", Request{Type: "paper"}, false, Request{}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := tc.in
+			err := r.Validate()
+			if (err == nil) != tc.ok {
+				t.Fatalf("Validate() = %v, want ok = %v", err, tc.ok)
+			}
+			if tc.ok && r != tc.want {
+				t.Fatalf("normalized = %+v, want %+v", r, tc.want)
+			}
+		})
+	}
+}
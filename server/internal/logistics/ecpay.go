@@ -0,0 +1,107 @@
+package logistics
+
+import (
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ECPayProvider：綠界超商電子地圖（物流 API，CheckMacValue 以 MD5 計算）
+type ECPayProvider struct {
+	MapURL     string // 正式：https://logistics.ecpay.com.tw/Express/map；測試：https://logistics-stage.ecpay.com.tw/Express/map
+	MerchantID string
+	HashKey    string
+	HashIV     string
+}
+
+func NewECPayProvider(mapURL, merchantID, hashKey, hashIV string) *ECPayProvider {
+	return &ECPayProvider{MapURL: mapURL, MerchantID: merchantID, HashKey: hashKey, HashIV: hashIV}
+}
+
+func (p *ECPayProvider) MapRequest(brand, state, callbackURL string) (*MapRequest, error) {
+	sub, ok := subTypes[brand]
+	if !ok {
+		return nil, ErrUnknownBrand
+	}
+	return &MapRequest{
+		Action: p.MapURL,
+		Fields: map[string]string{
+			"MerchantID":       p.MerchantID,
+			"MerchantTradeNo":  newTradeNo(),
+			"LogisticsType":    "CVS",
+			"LogisticsSubType": sub,
+			"IsCollection":     "N",
+			"ServerReplyURL":   callbackURL,
+			"ExtraData":        state,
+		},
+	}, nil
+}
+
+// Verify：電子地圖的回傳不帶 CheckMacValue，只能確認 MerchantID 與 MerchantTradeNo；
+// 真正的信任來源是 ExtraData（Redis 中的 state）與其記下的 MerchantTradeNo，由 Service.Complete 比對
+func (p *ECPayProvider) Verify(form url.Values) error {
+	if form.Get("MerchantID") != p.MerchantID || form.Get("MerchantTradeNo") == "" {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+func (p *ECPayProvider) ParseReply(form url.Values) (string, *Store, error) {
+	return parseECPayReply(form)
+}
+
+// CheckMacValue：綠界檢查碼。
+// 參數依名稱排序 → 前後加上 HashKey / HashIV → .NET 風格 URL encode → 轉小寫 → MD5 → 轉大寫
+func CheckMacValue(form url.Values, hashKey, hashIV string) string {
+	sum := md5.Sum([]byte(macSource(form, hashKey, hashIV)))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// macSource：CheckMacValue 雜湊前的字串（已 encode 並轉小寫）
+func macSource(form url.Values, hashKey, hashIV string) string {
+	keys := make([]string, 0, len(form))
+	for k := range form {
+		if k != "CheckMacValue" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return strings.ToLower(keys[i]) < strings.ToLower(keys[j]) })
+
+	var b strings.Builder
+	b.WriteString("HashKey=" + hashKey)
+	for _, k := range keys {
+		b.WriteString("&" + k + "=" + form.Get(k))
+	}
+	b.WriteString("&HashIV=" + hashIV)
+	return strings.ToLower(dotNetURLEncode(b.String()))
+}
+
+// dotNetURLEncode：與綠界範例（HttpUtility.UrlEncode）一致，- _ . ! * ( ) 不編碼
+var dotNetUnescape = strings.NewReplacer(
+	"%2D", "-", "%5F", "_", "%2E", ".", "%21", "!", "%2A", "*", "%28", "(", "%29", ")",
+	"~", "%7E",
+)
+
+func dotNetURLEncode(s string) string {
+	return dotNetUnescape.Replace(url.QueryEscape(s))
+}
+
+// newTradeNo：電子地圖要求的 MerchantTradeNo（英數字，20 字以內）
+func newTradeNo() string {
+	return "CVS" + strings.ToUpper(randomHex(8))
+}
+
+func verifyCheckMac(form url.Values, hashKey, hashIV string) error {
+	got := strings.ToUpper(form.Get("CheckMacValue"))
+	if got == "" {
+		return ErrBadSignature
+	}
+	want := CheckMacValue(form, hashKey, hashIV)
+	if subtle.ConstantTimeCompare([]byte(got), []byte(want)) != 1 {
+		return ErrBadSignature
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package logistics
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// 綠界技術文件「檢查碼機制」的範例（全方位金流，SHA256）：排序、encode、轉小寫的規則與物流 MD5 相同
+func TestMacSourceECPayExample(t *testing.T) {
+	form := url.Values{
+		"ChoosePayment":     {"ALL"},
+		"EncryptType":       {"1"},
+		"ItemName":          {"Apple iphone 15"},
+		"MerchantID":        {"3002607"},
+		"MerchantTradeDate": {"2023/03/12 15:30:23"},
+		"MerchantTradeNo":   {"ecpay20230312153023"},
+		"PaymentType":       {"aio"},
+		"ReturnURL":         {"https://www.ecpay.com.tw/receive.php"},
+		"TotalAmount":       {"30000"},
+		"TradeDesc":         {"促銷方案"},
+		"CheckMacValue":     {"ignored"},
+	}
+	const wantSource = "hashkey%3dpwfhcqoqzgmho4w6%26choosepayment%3dall%26encrypttype%3d1%26itemname%3dapple+iphone+15" +
+		"%26merchantid%3d3002607%26merchanttradedate%3d2023%2f03%2f12+15%3a30%3a23%26merchanttradeno%3decpay20230312153023" +
+		"%26paymenttype%3daio%26returnurl%3dhttps%3a%2f%2fwww.ecpay.com.tw%2freceive.php%26totalamount%3d30000" +
+		"%26tradedesc%3d%e4%bf%83%e9%8a%b7%e6%96%b9%e6%a1%88%26hashiv%3dekrm7ift261dpevs"
+	const wantSHA256 = "6C51C9E6888DE861FD62FB1DD17029FC742634498FD813DC43D4243B5685B840"
+
+	src := macSource(form, "pwFHCqoQZGmho4w6", "EkRm7iFT261dpevs")
+	if src != wantSource {
+		t.Fatalf("macSource =\n%s\nwant\n%s", src, wantSource)
+	}
+	sum := sha256.Sum256([]byte(src))
+	if got := strings.ToUpper(hex.EncodeToString(sum[:])); got != wantSHA256 {
+		t.Fatalf("sha256 = %s, want %s", got, wantSHA256)
+	}
+}
+
+func TestDotNetURLEncode(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"-_.!*()", "-_.!*()"},
+		{"a b", "a+b"},
+		{"~", "%7E"},
+		{"a&b=c", "a%26b%3Dc"},
+		{"https://x.tw/a?b", "https%3A%2F%2Fx.tw%2Fa%3Fb"},
+		{"門市", "%E9%96%80%E5%B8%82"},
+	}
+	for _, tc := range cases {
+		if got := dotNetURLEncode(tc.in); got != tc.want {
+			t.Errorf("dotNetURLEncode(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestCheckMacValueRoundTrip(t *testing.T) {
+	form := url.Values{"MerchantID": {"2000132"}, "CVSStoreID": {"991182"}, "CVSStoreName": {"測試門市"}}
+	form.Set("CheckMacValue", CheckMacValue(form, "5294y06JbISpM5x9", "v77hoKGq4kWxNNIS"))
+	if err := verifyCheckMac(form, "5294y06JbISpM5x9", "v77hoKGq4kWxNNIS"); err != nil {
+		t.Fatalf("verify own mac: %v", err)
+	}
+	form.Set("CVSStoreID", "991183")
+	if err := verifyCheckMac(form, "5294y06JbISpM5x9", "v77hoKGq4kWxNNIS"); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("tampered form: err = %v, want ErrBadSignature", err)
+	}
+}
+
+// 電子地圖回傳不帶 CheckMacValue：只比對 MerchantID 與 MerchantTradeNo
+func TestECPayMapRequestAndVerify(t *testing.T) {
+	p := NewECPayProvider("https://logistics-stage.ecpay.com.tw/Express/map", "2000132", "k", "iv")
+	req, err := p.MapRequest(Brand711, "state-1", "https://shop.example/api/logistics/cvs/callback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tradeNo := req.Fields["MerchantTradeNo"]
+	if tradeNo == "" || len(tradeNo) > 20 {
+		t.Fatalf("MerchantTradeNo = %q", tradeNo)
+	}
+
+	reply := url.Values{
+		"MerchantID":       {"2000132"},
+		"MerchantTradeNo":  {tradeNo},
+		"LogisticsSubType": {"UNIMART"},
+		"CVSStoreID":       {"991182"},
+		"ExtraData":        {"state-1"},
+	}
+	if err := p.Verify(reply); err != nil {
+		t.Fatalf("Verify(real reply) = %v", err)
+	}
+	reply.Set("MerchantID", "3002607")
+	if err := p.Verify(reply); !errors.Is(err, ErrBadSignature) {
+		t.Fatalf("Verify(other merchant) = %v, want ErrBadSignature", err)
+	}
+}
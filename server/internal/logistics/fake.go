@@ -0,0 +1,146 @@
+package logistics
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FakeProvider：本機假電子地圖，欄位與綠界選店回傳相同，可離線測試完整流程。
+// 另外以 CheckMacValue 簽章（金鑰每次啟動隨機產生），只有本程序產生的假選店頁能通過驗證
+type FakeProvider struct {
+	MapURL  string // 假選店頁網址，例：http://localhost:8080/api/logistics/fake-map
+	hashKey string
+	hashIV  string
+}
+
+func NewFakeProvider(mapURL string) *FakeProvider {
+	return &FakeProvider{MapURL: mapURL, hashKey: randomHex(8), hashIV: randomHex(8)}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func (p *FakeProvider) Verify(form url.Values) error {
+	return verifyCheckMac(form, p.hashKey, p.hashIV)
+}
+
+func (p *FakeProvider) MapRequest(brand, state, callbackURL string) (*MapRequest, error) {
+	sub, ok := subTypes[brand]
+	if !ok {
+		return nil, ErrUnknownBrand
+	}
+	return &MapRequest{
+		Action: p.MapURL,
+		Fields: map[string]string{
+			"MerchantTradeNo":  newTradeNo(),
+			"LogisticsSubType": sub,
+			"ServerReplyURL":   callbackURL,
+			"ExtraData":        state,
+		},
+	}, nil
+}
+
+func (p *FakeProvider) ParseReply(form url.Values) (string, *Store, error) {
+	return parseECPayReply(form)
+}
+
+// parseECPayReply：綠界格式的選店回傳（CVSStoreID / CVSStoreName / CVSAddress …）
+func parseECPayReply(form url.Values) (string, *Store, error) {
+	state := strings.TrimSpace(form.Get("ExtraData"))
+	st := &Store{
+		Brand:   brandOf(form.Get("LogisticsSubType")),
+		Code:    strings.TrimSpace(form.Get("CVSStoreID")),
+		Name:    strings.TrimSpace(form.Get("CVSStoreName")),
+		Address: strings.TrimSpace(form.Get("CVSAddress")),
+		Phone:   strings.TrimSpace(form.Get("CVSTelephone")),
+	}
+	if state == "" || st.Brand == "" || st.Code == "" {
+		return "", nil, ErrInvalidReply
+	}
+	return state, st, nil
+}
+
+// 假門市資料（僅供測試）
+var fakeStores = map[string][]Store{
+	Brand711: {
+		{Code: "991182", Name: "測試門市一號店", Address: "台北市信義區測試路一段 1 號", Phone: "02-00000001"},
+		{Code: "991183", Name: "測試門市二號店", Address: "新北市板橋區測試街 22 號", Phone: "02-00000002"},
+		{Code: "991184", Name: "測試門市三號店", Address: "台中市西屯區測試大道 333 號", Phone: "04-00000003"},
+	},
+	BrandFamily: {
+		{Code: "F00001", Name: "測試全家一號店", Address: "台北市中山區測試路 10 號", Phone: "02-00000010"},
+	},
+	BrandHiLife: {
+		{Code: "H00001", Name: "測試萊爾富一號店", Address: "高雄市前鎮區測試路 5 號", Phone: "07-00000005"},
+	},
+}
+
+var fakeMapPage = template.Must(template.New("map").Parse(`<!doctype html>
+<html lang="zh-Hant"><head><meta charset="utf-8"><title>測試電子地圖</title>
+<style>body{font-family:sans-serif;max-width:560px;margin:24px auto}form{border:1px solid #ddd;border-radius:8px;padding:12px;margin:8px 0}</style>
+</head><body>
+<h2>測試電子地圖（{{.SubType}}）</h2>
+<p>此頁為本機假服務，選擇門市後會回傳至商店。</p>
+{{range .Stores}}
+<form method="post" action="{{$.Reply}}">
+  <input type="hidden" name="MerchantTradeNo" value="{{$.TradeNo}}">
+  <input type="hidden" name="LogisticsSubType" value="{{$.SubType}}">
+  <input type="hidden" name="ExtraData" value="{{$.State}}">
+  <input type="hidden" name="CVSStoreID" value="{{.Code}}">
+  <input type="hidden" name="CVSStoreName" value="{{.Name}}">
+  <input type="hidden" name="CVSAddress" value="{{.Address}}">
+  <input type="hidden" name="CVSTelephone" value="{{.Phone}}">
+  <input type="hidden" name="CheckMacValue" value="{{.Mac}}">
+  <strong>{{.Name}}</strong>（{{.Code}}）<br>{{.Address}}<br>
+  <button type="submit">選擇此門市</button>
+</form>
+{{else}}
+<p>沒有可選的門市</p>
+{{end}}
+</body></html>`))
+
+// ServeMap：假選店頁（GET / POST 皆可）
+func (p *FakeProvider) ServeMap(c *gin.Context) {
+	sub := c.Request.FormValue("LogisticsSubType")
+	reply := c.Request.FormValue("ServerReplyURL")
+	if reply == "" {
+		c.String(http.StatusBadRequest, "ServerReplyURL required")
+		return
+	}
+	state := c.Request.FormValue("ExtraData")
+	tradeNo := c.Request.FormValue("MerchantTradeNo")
+	type signedStore struct {
+		Store
+		Mac string
+	}
+	var stores []signedStore
+	for _, st := range fakeStores[brandOf(sub)] {
+		form := url.Values{
+			"MerchantTradeNo":  {tradeNo},
+			"LogisticsSubType": {sub},
+			"ExtraData":        {state},
+			"CVSStoreID":       {st.Code},
+			"CVSStoreName":     {st.Name},
+			"CVSAddress":       {st.Address},
+			"CVSTelephone":     {st.Phone},
+		}
+		stores = append(stores, signedStore{Store: st, Mac: CheckMacValue(form, p.hashKey, p.hashIV)})
+	}
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	_ = fakeMapPage.Execute(c.Writer, gin.H{
+		"SubType": sub,
+		"Reply":   reply,
+		"State":   state,
+		"TradeNo": tradeNo,
+		"Stores":  stores,
+	})
+}
@@ -0,0 +1,106 @@
+package logistics
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	svc     *Service
+	baseURL string // 對外網址（PUBLIC_BASE_URL）：組回傳位址，並限制只能導回本站，避免被當成 open redirect
+}
+
+func NewHandler(svc *Service, baseURL string) *Handler {
+	return &Handler{svc: svc, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+var autoSubmit = template.Must(template.New("redirect").Parse(`<!doctype html>
+<html><head><meta charset="utf-8"><title>前往電子地圖…</title></head>
+<body onload="document.forms[0].submit()">
+<form method="post" action="{{.Action}}">
+{{range $k, $v := .Fields}}<input type="hidden" name="{{$k}}" value="{{$v}}">
+{{end}}<noscript><button type="submit">前往選擇門市</button></noscript>
+</form></body></html>`))
+
+// 顧客：開啟電子地圖 GET /api/logistics/cvs/map?brand=711&returnUrl=http://localhost:5173/checkout
+func (h *Handler) Map(c *gin.Context) {
+	brand := c.DefaultQuery("brand", Brand711)
+	returnURL, ok := h.returnURL(c.Query("returnUrl"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid returnUrl"})
+		return
+	}
+	req, err := h.svc.Start(c.Request.Context(), brand, returnURL, h.baseURL+"/api/logistics/cvs/callback")
+	if err != nil {
+		if errors.Is(err, ErrUnknownBrand) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Cache-Control", "no-store")
+	_ = autoSubmit.Execute(c.Writer, req)
+}
+
+// 電子地圖回傳 POST /api/logistics/cvs/callback（form）
+// 記下門市後導回前端：returnUrl?cvsState=<state>
+func (h *Handler) Callback(c *gin.Context) {
+	if err := c.Request.ParseForm(); err != nil {
+		c.String(http.StatusBadRequest, "invalid form")
+		return
+	}
+	state, sess, err := h.svc.Complete(c.Request.Context(), c.Request.PostForm)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrBadSignature), errors.Is(err, ErrInvalidReply), errors.Is(err, ErrStateNotFound):
+			c.String(http.StatusBadRequest, err.Error())
+		default:
+			c.String(http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	u, _ := url.Parse(sess.ReturnURL)
+	q := u.Query()
+	q.Set("cvsState", state)
+	u.RawQuery = q.Encode()
+	c.Redirect(http.StatusSeeOther, u.String())
+}
+
+// 顧客：查詢選定的門市 GET /api/logistics/cvs/store/:state
+func (h *Handler) Store(c *gin.Context) {
+	st, err := h.svc.Lookup(c.Request.Context(), c.Param("state"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// returnURL：returnUrl 可為本站路徑（/checkout）或 PUBLIC_BASE_URL 底下的完整網址
+func (h *Handler) returnURL(raw string) (string, bool) {
+	base, err := url.Parse(h.baseURL)
+	if err != nil || base.Host == "" {
+		return "", false
+	}
+	u, err := url.Parse(raw)
+	if err != nil || raw == "" {
+		return "", false
+	}
+	if u.Scheme == "" && u.Host == "" {
+		if !strings.HasPrefix(u.Path, "/") {
+			return "", false
+		}
+		return base.ResolveReference(u).String(), true
+	}
+	if u.Scheme != base.Scheme || u.Host != base.Host {
+		return "", false
+	}
+	return u.String(), true
+}
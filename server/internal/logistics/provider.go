@@ -0,0 +1,65 @@
+// Package logistics 處理超商取貨的電子地圖選店流程：
+// 導向業者選店頁 → 業者 POST 回傳門市 → 暫存於 Redis → 下單時以 state 取回門市資料
+package logistics
+
+import (
+	"errors"
+	"net/url"
+)
+
+// 超商品牌
+const (
+	Brand711    = "711"
+	BrandFamily = "family"
+	BrandHiLife = "hilife"
+)
+
+var (
+	ErrUnknownBrand   = errors.New("unknown cvs brand")
+	ErrInvalidReply   = errors.New("invalid store map reply")
+	ErrStateNotFound  = errors.New("store selection expired or not found")
+	ErrStoreNotChosen = errors.New("store not selected yet")
+	ErrBadSignature   = errors.New("invalid store map signature")
+)
+
+// Store：顧客選定的取貨門市
+type Store struct {
+	Brand   string `json:"brand"`
+	Code    string `json:"code"` // 門市店號
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	Phone   string `json:"phone,omitempty"`
+}
+
+// MapRequest：導向選店頁所需的表單（業者多半要求 POST，故一律以自動送出表單導向）
+type MapRequest struct {
+	Action string
+	Fields map[string]string
+}
+
+// Provider：電子地圖服務（綠界等金流物流商；本機為 FakeProvider）
+type Provider interface {
+	// MapRequest：state 需原樣帶回；callbackURL 為選店完成後瀏覽器 POST 的位址
+	MapRequest(brand, state, callbackURL string) (*MapRequest, error)
+	// Verify：業者層級的檢查（綠界電子地圖不回傳 CheckMacValue，只比對 MerchantID），不符回傳 ErrBadSignature。
+	// 回傳是否屬於本站發起的選店，由 Service.Complete 以 Redis 中的 state 與 MerchantTradeNo 判斷
+	Verify(form url.Values) error
+	// ParseReply：解析選店完成的 POST 表單，回傳 state 與門市（須先通過 Verify）
+	ParseReply(form url.Values) (string, *Store, error)
+}
+
+// 綠界的超商代碼（LogisticsSubType），FakeProvider 沿用同一組欄位
+var subTypes = map[string]string{
+	Brand711:    "UNIMART",
+	BrandFamily: "FAMI",
+	BrandHiLife: "HILIFE",
+}
+
+func brandOf(subType string) string {
+	for b, s := range subTypes {
+		if s == subType {
+			return b
+		}
+	}
+	return ""
+}
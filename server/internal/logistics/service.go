@@ -0,0 +1,98 @@
+package logistics
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/cache"
+)
+
+// 選店結果保留時間（顧客選完門市後須在此時間內完成下單）
+const sessionTTL = 30 * time.Minute
+
+// Session：一次選店流程（以 state 為 key 存在 Redis）
+type Session struct {
+	Brand     string `json:"brand"`
+	ReturnURL string `json:"returnUrl"`
+	TradeNo   string `json:"tradeNo,omitempty"` // 送出的 MerchantTradeNo，回傳須一致
+	Store     *Store `json:"store,omitempty"`
+}
+
+type Service struct {
+	rdb      *cache.Redis
+	provider Provider
+}
+
+func NewService(rdb *cache.Redis, provider Provider) *Service {
+	return &Service{rdb: rdb, provider: provider}
+}
+
+func sessionKey(state string) string { return "cvs:map:" + state }
+
+// Start：建立選店流程，回傳導向選店頁的表單
+func (s *Service) Start(ctx context.Context, brand, returnURL, callbackURL string) (*MapRequest, error) {
+	if _, ok := subTypes[brand]; !ok {
+		return nil, ErrUnknownBrand
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	state := hex.EncodeToString(b)
+	req, err := s.provider.MapRequest(brand, state, callbackURL)
+	if err != nil {
+		return nil, err
+	}
+	sess := Session{Brand: brand, ReturnURL: returnURL, TradeNo: req.Fields["MerchantTradeNo"]}
+	if err := s.save(ctx, state, &sess); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// Complete：驗證並處理選店回傳，記下門市後回傳 state 與原本的 returnUrl
+func (s *Service) Complete(ctx context.Context, form url.Values) (string, *Session, error) {
+	if err := s.provider.Verify(form); err != nil {
+		return "", nil, err
+	}
+	state, st, err := s.provider.ParseReply(form)
+	if err != nil {
+		return "", nil, err
+	}
+	var sess Session
+	if !s.rdb.GetJSON(ctx, sessionKey(state), &sess) {
+		return "", nil, ErrStateNotFound
+	}
+	if st.Brand != sess.Brand || form.Get("MerchantTradeNo") != sess.TradeNo {
+		return "", nil, ErrInvalidReply
+	}
+	sess.Store = st
+	if err := s.save(ctx, state, &sess); err != nil {
+		return "", nil, err
+	}
+	return state, &sess, nil
+}
+
+// Lookup：以 state 取回已選定的門市（下單時使用）
+func (s *Service) Lookup(ctx context.Context, state string) (*Store, error) {
+	var sess Session
+	if state == "" || !s.rdb.GetJSON(ctx, sessionKey(state), &sess) {
+		return nil, ErrStateNotFound
+	}
+	if sess.Store == nil {
+		return nil, ErrStoreNotChosen
+	}
+	return sess.Store, nil
+}
+
+func (s *Service) save(ctx context.Context, state string, sess *Session) error {
+	b, err := json.Marshal(sess)
+	if err != nil {
+		return err
+	}
+	return s.rdb.Set(ctx, sessionKey(state), b, sessionTTL).Err()
+}
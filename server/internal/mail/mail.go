@@ -0,0 +1,93 @@
+// Package mail 交易通知信：Mailer 介面（SMTP / 開發用 outbox）、內嵌 zh-TW 範本、
+// 背景佇列重試。寄信一律非同步，郵件伺服器故障不會讓 API 請求失敗
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+var ErrNoRecipient = errors.New("mail: no recipient")
+
+// Message：一封信（HTML 與純文字兩種版本）
+type Message struct {
+	To       string `json:"to"`
+	Subject  string `json:"subject"`
+	Text     string `json:"text"`
+	HTML     string `json:"html"`
+	Template string `json:"template"` // 產生此信的範本名稱（記錄用）
+}
+
+type Mailer interface {
+	Send(ctx context.Context, from string, msg Message) error
+}
+
+// build：組成 multipart/alternative 的 RFC 5322 原始信件
+func build(from string, msg Message, now time.Time) ([]byte, error) {
+	if strings.TrimSpace(msg.To) == "" {
+		return nil, ErrNoRecipient
+	}
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ typ, content string }{
+		{"text/plain", msg.Text},
+		{"text/html", msg.HTML},
+	} {
+		if part.content == "" {
+			continue
+		}
+		w, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.typ + "; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "From: %s\r\n", from)
+	fmt.Fprintf(&out, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&out, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	fmt.Fprintf(&out, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	fmt.Fprintf(&out, "Message-ID: <%s@%s>\r\n", messageID(), domainOf(from))
+	out.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&out, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", mw.Boundary())
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+func messageID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// domainOf："ZeusShop <no-reply@example.com>" → example.com
+func domainOf(addr string) string {
+	addr = strings.TrimSuffix(strings.TrimSpace(addr), ">")
+	if i := strings.LastIndex(addr, "@"); i >= 0 && i < len(addr)-1 {
+		return addr[i+1:]
+	}
+	return "localhost"
+}
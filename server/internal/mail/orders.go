@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"context"
+	"log"
+
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/events"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/order"
+)
+
+// SubscribeOrders：訂單成立 / 確認收款 / 出貨 → 寄通知信給有留 Email 的買家
+func (s *Service) SubscribeOrders(db *gorm.DB) {
+	repo := order.NewRepo(db)
+	notify := func(tpl string) events.Handler {
+		return func(_ context.Context, e events.Event) {
+			o, err := repo.AdminGet(e.OrderID)
+			if err != nil {
+				log.Printf("mail: %s order %d: %v", e.Type, e.OrderID, err)
+				return
+			}
+			if o.BuyerEmail == "" {
+				return
+			}
+			if err := s.Send(o.BuyerEmail, tpl, map[string]any{
+				"Order":    o,
+				"Shipping": o.ShippingMethod.Label(),
+				"Carrier":  order.CarrierLabel(o.Carrier),
+			}); err != nil {
+				log.Printf("mail: %s order %d: %v", e.Type, e.OrderID, err)
+			}
+		}
+	}
+	events.Subscribe(events.OrderCreated, notify(TplOrderConfirmation))
+	events.Subscribe(events.OrderPaid, notify(TplPaymentConfirmed))
+	events.Subscribe(events.OrderShipped, notify(TplOrderShipped))
+}
@@ -0,0 +1,64 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileOutbox：開發用，每封信寫成一個 .eml 檔（可直接用郵件軟體開啟）
+type FileOutbox struct {
+	Dir string
+}
+
+func NewFileOutbox(dir string) *FileOutbox { return &FileOutbox{Dir: dir} }
+
+func (o *FileOutbox) Send(_ context.Context, from string, msg Message) error {
+	now := time.Now()
+	raw, err := build(from, msg, now)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(o.Dir, 0o755); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s-%s.eml", now.Format("20060102-150405"), msg.Template, messageID()[:8])
+	return os.WriteFile(filepath.Join(o.Dir, name), raw, 0o644)
+}
+
+// MemoryOutbox：開發用，保留最近 limit 封信在記憶體
+type MemoryOutbox struct {
+	mu    sync.Mutex
+	limit int
+	msgs  []Message
+}
+
+func NewMemoryOutbox(limit int) *MemoryOutbox {
+	if limit <= 0 {
+		limit = 100
+	}
+	return &MemoryOutbox{limit: limit}
+}
+
+func (o *MemoryOutbox) Send(_ context.Context, _ string, msg Message) error {
+	if msg.To == "" {
+		return ErrNoRecipient
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.msgs = append(o.msgs, msg)
+	if len(o.msgs) > o.limit {
+		o.msgs = o.msgs[len(o.msgs)-o.limit:]
+	}
+	return nil
+}
+
+// Messages：最近的信（新的在後）
+func (o *MemoryOutbox) Messages() []Message {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return append([]Message(nil), o.msgs...)
+}
@@ -0,0 +1,91 @@
+package mail
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const (
+	maxAttempts = 6
+	baseBackoff = 10 * time.Second // 10s, 20s, 40s … 最多 10 分鐘
+	maxBackoff  = 10 * time.Minute
+)
+
+type job struct {
+	msg     Message
+	attempt int
+}
+
+// Queue：記憶體內的寄信佇列；失敗以指數退避重試，超過次數只記 log
+type Queue struct {
+	mailer Mailer
+	from   string
+	jobs   chan job
+}
+
+func NewQueue(mailer Mailer, from string, size int) *Queue {
+	if size <= 0 {
+		size = 1000
+	}
+	return &Queue{mailer: mailer, from: from, jobs: make(chan job, size)}
+}
+
+// Enqueue：不會阻塞；佇列滿時丟棄並回 false
+func (q *Queue) Enqueue(msg Message) bool {
+	return q.push(job{msg: msg})
+}
+
+func (q *Queue) push(j job) bool {
+	select {
+	case q.jobs <- j:
+		return true
+	default:
+		log.Printf("mail: queue full, dropped %s to %s", j.msg.Template, j.msg.To)
+		return false
+	}
+}
+
+// Run：啟動 workers 個寄信 goroutine，直到 ctx 結束
+func (q *Queue) Run(ctx context.Context, workers int) {
+	if workers <= 0 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go q.work(ctx)
+	}
+	<-ctx.Done()
+}
+
+func (q *Queue) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-q.jobs:
+			q.deliver(ctx, j)
+		}
+	}
+}
+
+func (q *Queue) deliver(ctx context.Context, j job) {
+	err := q.mailer.Send(ctx, q.from, j.msg)
+	if err == nil {
+		return
+	}
+	j.attempt++
+	if j.attempt >= maxAttempts || err == ErrNoRecipient {
+		log.Printf("mail: give up %s to %s after %d attempts: %v", j.msg.Template, j.msg.To, j.attempt, err)
+		return
+	}
+	delay := baseBackoff << (j.attempt - 1)
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	log.Printf("mail: send %s to %s failed (attempt %d, retry in %s): %v", j.msg.Template, j.msg.To, j.attempt, delay, err)
+	time.AfterFunc(delay, func() {
+		if ctx.Err() == nil {
+			q.push(j)
+		}
+	})
+}
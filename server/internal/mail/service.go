@@ -0,0 +1,82 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Service：依範本產生信件並放進背景佇列
+type Service struct {
+	queue *Queue
+	tpl   *Templates
+	shop  string
+}
+
+func NewService(queue *Queue, shop string) (*Service, error) {
+	tpl, err := LoadTemplates()
+	if err != nil {
+		return nil, err
+	}
+	return &Service{queue: queue, tpl: tpl, shop: shop}, nil
+}
+
+// Run：啟動背景寄信（阻塞到 ctx 結束）
+func (s *Service) Run(ctx context.Context, workers int) { s.queue.Run(ctx, workers) }
+
+// Send：套用範本後排入佇列；只有範本錯誤會回傳 error（寄送失敗由佇列重試）
+func (s *Service) Send(to, tpl string, data map[string]any) error {
+	if to == "" {
+		return ErrNoRecipient
+	}
+	if data == nil {
+		data = map[string]any{}
+	}
+	data["Shop"] = s.shop
+	msg, err := s.tpl.Render(tpl, data)
+	if err != nil {
+		return err
+	}
+	msg.To = to
+	s.queue.Enqueue(msg)
+	return nil
+}
+
+// PasswordReset：廠商重設密碼連結
+func (s *Service) PasswordReset(to, name, link string, ttl time.Duration) error {
+	return s.Send(to, TplPasswordReset, map[string]any{
+		"Name":      name,
+		"Link":      link,
+		"ExpiresIn": durationText(ttl),
+	})
+}
+
+// AccountLocked：登入失敗過多、帳號暫時鎖定，附上立即解鎖連結
+func (s *Service) AccountLocked(to, name, link string, lockFor time.Duration) error {
+	return s.Send(to, TplAccountLocked, map[string]any{
+		"Name":     name,
+		"Link":     link,
+		"LockedIn": durationText(lockFor),
+	})
+}
+
+// StaffInvite：廠商邀請員工子帳號，連結內設定密碼後即可登入
+func (s *Service) StaffInvite(to, name, vendorName, link string, ttl time.Duration) error {
+	return s.Send(to, TplStaffInvite, map[string]any{
+		"Name":       name,
+		"VendorName": vendorName,
+		"Link":       link,
+		"ExpiresIn":  durationText(ttl),
+	})
+}
+
+// durationText：30m → 30 分鐘、2h → 2 小時、168h → 7 天
+func durationText(d time.Duration) string {
+	if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%d 天", int(d/(24*time.Hour)))
+	}
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d 小時", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%d 分鐘", int(d/time.Minute))
+}
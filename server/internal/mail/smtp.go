@@ -0,0 +1,96 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPMailer：port 465 走 implicit TLS，其餘若伺服器支援則 STARTTLS
+type SMTPMailer struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	Timeout  time.Duration // 單封信連線 + 傳送上限，0 = 30 秒
+}
+
+func NewSMTPMailer(host string, port int, username, password string) *SMTPMailer {
+	return &SMTPMailer{Host: host, Port: port, Username: username, Password: password}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, from string, msg Message) error {
+	raw, err := build(from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	sender, err := netmail.ParseAddress(from)
+	if err != nil {
+		return err
+	}
+	rcpt, err := netmail.ParseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+
+	timeout := m.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(m.Host, strconv.Itoa(m.Port))
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(dl)
+	}
+	tlsCfg := &tls.Config{ServerName: m.Host}
+	if m.Port == 465 {
+		conn = tls.Client(conn, tlsCfg)
+	}
+	c, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if m.Port != 465 {
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(tlsCfg); err != nil {
+				return err
+			}
+		}
+	}
+	if m.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(sender.Address); err != nil {
+		return err
+	}
+	if err := c.Rcpt(rcpt.Address); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
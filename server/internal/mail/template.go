@@ -0,0 +1,99 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/order"
+)
+
+// 範本名稱（templates/<name>.html + .txt）
+const (
+	TplPasswordReset     = "password_reset"
+	TplOrderConfirmation = "order_confirmation"
+	TplPaymentConfirmed  = "payment_confirmed"
+	TplOrderShipped      = "order_shipped"
+	TplAccountLocked     = "account_locked"
+	TplStaffInvite       = "staff_invite"
+)
+
+//go:embed templates/*
+var templateFS embed.FS
+
+var taipei = func() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Taipei"); err == nil {
+		return loc
+	}
+	return time.FixedZone("CST", 8*3600)
+}()
+
+var funcs = map[string]any{
+	"money": order.FormatAmount,
+	"date": func(v any) string {
+		switch t := v.(type) {
+		case time.Time:
+			return t.In(taipei).Format("2006/01/02 15:04")
+		case *time.Time:
+			if t != nil {
+				return t.In(taipei).Format("2006/01/02 15:04")
+			}
+		}
+		return ""
+	},
+}
+
+type pair struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+// Templates：啟動時解析所有內嵌範本，語法錯誤會在啟動時就失敗
+type Templates struct {
+	byName map[string]pair
+}
+
+func LoadTemplates() (*Templates, error) {
+	t := &Templates{byName: map[string]pair{}}
+	for _, name := range []string{TplPasswordReset, TplOrderConfirmation, TplPaymentConfirmed, TplOrderShipped, TplAccountLocked, TplStaffInvite} {
+		h, err := htmltemplate.New(name).Funcs(funcs).ParseFS(templateFS,
+			"templates/layout.html", "templates/items.html", "templates/"+name+".html")
+		if err != nil {
+			return nil, fmt.Errorf("mail template %s.html: %w", name, err)
+		}
+		x, err := texttemplate.New(name+".txt").Funcs(funcs).ParseFS(templateFS, "templates/"+name+".txt")
+		if err != nil {
+			return nil, fmt.Errorf("mail template %s.txt: %w", name, err)
+		}
+		t.byName[name] = pair{html: h, text: x}
+	}
+	return t, nil
+}
+
+// Render：產生主旨 / 純文字 / HTML（收件人由呼叫端填）
+func (t *Templates) Render(name string, data any) (Message, error) {
+	p, ok := t.byName[name]
+	if !ok {
+		return Message{}, fmt.Errorf("mail: unknown template %q", name)
+	}
+	var subject, text, html bytes.Buffer
+	if err := p.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := p.text.ExecuteTemplate(&text, name+".txt", data); err != nil {
+		return Message{}, err
+	}
+	if err := p.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		Subject:  strings.TrimSpace(subject.String()),
+		Text:     strings.TrimSpace(text.String()) + "\n",
+		HTML:     html.String(),
+		Template: name,
+	}, nil
+}
@@ -0,0 +1,33 @@
+// Package notify 買家簡訊 / LINE 通知：訂閱訂單事件，依買家留下的聯絡方式挑選通道，
+// 每次發送都寫入 notify_deliveries 以便後台查詢與重送
+package notify
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+// 通道名稱
+const (
+	ChannelSMS  = "sms"
+	ChannelLINE = "line"
+)
+
+var ErrNoRecipient = errors.New("notify: no recipient")
+
+// Channel：一種發送管道。to 為該通道的收件識別（手機號碼 / LINE userId）
+type Channel interface {
+	Name() string
+	// Send 回傳服務商的訊息編號（沒有則為空字串）
+	Send(ctx context.Context, to, text string) (string, error)
+}
+
+// normalizePhone：09xxxxxxxx → +8869xxxxxxxx（已是國際格式則原樣）
+func normalizePhone(p string) string {
+	p = strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(p))
+	if strings.HasPrefix(p, "09") && len(p) == 10 {
+		return "+886" + p[1:]
+	}
+	return p
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Sent：FakeChannel 記錄的一則訊息
+type Sent struct {
+	To   string    `json:"to"`
+	Text string    `json:"text"`
+	At   time.Time `json:"at"`
+}
+
+// FakeChannel：本機開發 / 測試用，不實際發送，只記錄在記憶體並寫 log
+type FakeChannel struct {
+	name string
+	mu   sync.Mutex
+	sent []Sent
+	seq  int
+}
+
+func NewFakeChannel(name string) *FakeChannel { return &FakeChannel{name: name} }
+
+func (f *FakeChannel) Name() string { return f.name }
+
+func (f *FakeChannel) Send(_ context.Context, to, text string) (string, error) {
+	if to == "" {
+		return "", ErrNoRecipient
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.seq++
+	f.sent = append(f.sent, Sent{To: to, Text: text, At: time.Now()})
+	log.Printf("notify[fake %s] to %s: %s", f.name, to, text)
+	return fmt.Sprintf("fake-%s-%d", f.name, f.seq), nil
+}
+
+// Sent：目前為止記錄的訊息（舊的在前）
+func (f *FakeChannel) Sent() []Sent {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Sent(nil), f.sent...)
+}
+
+// Reset：清空紀錄
+func (f *FakeChannel) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = nil
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// 後台：通知發送紀錄 GET /api/admin/notifications?orderId=&status=&channel=&limit=&offset=
+func (h *Handler) AdminList(c *gin.Context) {
+	q := ListQuery{
+		Status:  strings.TrimSpace(c.Query("status")),
+		Channel: strings.TrimSpace(c.Query("channel")),
+	}
+	q.OrderID, _ = strconv.ParseUint(c.Query("orderId"), 10, 64)
+	q.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if q.Limit < 1 || q.Limit > 200 {
+		q.Limit = 50
+	}
+	q.Offset, _ = strconv.Atoi(c.Query("offset"))
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	list, total, err := h.svc.List(c.Request.Context(), q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": q.Limit, "offset": q.Offset})
+}
+
+// 後台：重送 POST /api/admin/notifications/:id/resend
+func (h *Handler) AdminResend(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	d, err := h.svc.Resend(c.Request.Context(), id)
+	if err != nil {
+		code := http.StatusBadRequest
+		if errors.Is(err, ErrNotFound) {
+			code = http.StatusNotFound
+		}
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const lineVerifyURL = "https://api.line.me/oauth2/v2.1/verify"
+
+var ErrInvalidIDToken = errors.New("notify: invalid LINE id token")
+
+// LIFFVerifier：向 LINE 驗證 LIFF 取得的 ID token（liff.getIDToken()），
+// 回傳 token 中的 sub（LINE userId）。LINE 端會一併檢查簽章、效期與 aud = ChannelID
+type LIFFVerifier struct {
+	ChannelID string // LIFF 所屬的 LINE Login channel ID
+	URL       string // 預設 lineVerifyURL（測試可換成假伺服器）
+	Client    *http.Client
+}
+
+func NewLIFFVerifier(channelID string) *LIFFVerifier {
+	return &LIFFVerifier{ChannelID: channelID, URL: lineVerifyURL, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (v *LIFFVerifier) Verify(ctx context.Context, idToken string) (string, error) {
+	if idToken == "" {
+		return "", ErrInvalidIDToken
+	}
+	form := url.Values{"id_token": {idToken}, "client_id": {v.ChannelID}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	res, err := v.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode == http.StatusBadRequest {
+		return "", ErrInvalidIDToken
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("line verify: %s", res.Status)
+	}
+	var claims struct {
+		Sub string `json:"sub"`
+		Aud string `json:"aud"`
+	}
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return "", fmt.Errorf("line verify: %w", err)
+	}
+	if claims.Sub == "" || claims.Aud != v.ChannelID {
+		return "", ErrInvalidIDToken
+	}
+	return claims.Sub, nil
+}
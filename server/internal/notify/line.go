@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const linePushURL = "https://api.line.me/v2/bot/message/push"
+
+// LINEPush：LINE Messaging API push message（需官方帳號 channel access token，
+// 買家須已加入官方帳號好友，to 為其 userId）
+type LINEPush struct {
+	Token  string
+	URL    string // 預設 linePushURL（測試可換成假伺服器）
+	Client *http.Client
+}
+
+func NewLINEPush(token string) *LINEPush {
+	return &LINEPush{Token: token, URL: linePushURL, Client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+func (l *LINEPush) Name() string { return ChannelLINE }
+
+func (l *LINEPush) Send(ctx context.Context, to, text string) (string, error) {
+	if to == "" {
+		return "", ErrNoRecipient
+	}
+	body, _ := json.Marshal(map[string]any{
+		"to":       to,
+		"messages": []map[string]string{{"type": "text", "text": text}},
+	})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+l.Token)
+	res, err := l.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		raw, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+		var e struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(raw, &e) == nil && e.Message != "" {
+			return "", fmt.Errorf("line push: %s: %s", res.Status, e.Message)
+		}
+		return "", fmt.Errorf("line push: %s", res.Status)
+	}
+	return res.Header.Get("X-Line-Request-Id"), nil
+}
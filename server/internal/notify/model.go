@@ -0,0 +1,29 @@
+package notify
+
+import "time"
+
+// 發送狀態
+const (
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+)
+
+// Delivery：一則通知的發送紀錄（重試次數、服務商訊息編號、最後錯誤）
+type Delivery struct {
+	ID         uint64     `gorm:"primaryKey" json:"id"`
+	OrderID    uint64     `gorm:"index" json:"orderId"`
+	Event      string     `gorm:"size:32" json:"event"`
+	Channel    string     `gorm:"size:16;index" json:"channel"`
+	Recipient  string     `gorm:"size:64" json:"recipient"`
+	Message    string     `gorm:"type:text" json:"message"`
+	Status     string     `gorm:"size:16;index" json:"status"`
+	Attempts   int        `json:"attempts"`
+	ProviderID string     `gorm:"size:64" json:"providerId"`
+	Error      string     `gorm:"size:255" json:"error"`
+	SentAt     *time.Time `json:"sentAt"`
+	CreatedAt  time.Time  `gorm:"index" json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+func (Delivery) TableName() string { return "notify_deliveries" }
@@ -0,0 +1,186 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"log"
+	"strings"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/events"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/order"
+)
+
+var ErrNotFound = errors.New("notify: delivery not found")
+
+// 事件 → 範本（templates/<name>.txt）
+var eventTemplates = map[string]string{
+	events.OrderCreated: "order_received",
+	events.OrderPaid:    "payment_confirmed",
+	events.OrderShipped: "order_shipped",
+}
+
+// 失敗重試間隔（第一次立即發送）
+var retryDelays = []time.Duration{0, 10 * time.Second, time.Minute}
+
+//go:embed templates/*.txt
+var templateFS embed.FS
+
+type Service struct {
+	db       *gorm.DB
+	repo     *order.Repo
+	channels map[string]Channel
+	tpl      *template.Template
+	shop     string
+}
+
+func NewService(db *gorm.DB, shop string, channels ...Channel) (*Service, error) {
+	tpl, err := template.New("").Funcs(template.FuncMap{"money": order.FormatAmount}).
+		ParseFS(templateFS, "templates/*.txt")
+	if err != nil {
+		return nil, err
+	}
+	s := &Service{db: db, repo: order.NewRepo(db), channels: map[string]Channel{}, tpl: tpl, shop: shop}
+	for _, ch := range channels {
+		s.channels[ch.Name()] = ch
+	}
+	return s, nil
+}
+
+// Subscribe：訂閱訂單事件（成立 / 確認收款 / 出貨）
+func (s *Service) Subscribe() {
+	for ev := range eventTemplates {
+		events.Subscribe(ev, s.handle)
+	}
+}
+
+func (s *Service) handle(ctx context.Context, e events.Event) {
+	o, err := s.repo.AdminGet(e.OrderID)
+	if err != nil {
+		log.Printf("notify: %s order %d: %v", e.Type, e.OrderID, err)
+		return
+	}
+	ch, to := s.route(o)
+	if ch == nil {
+		return
+	}
+	text, err := s.render(eventTemplates[e.Type], o)
+	if err != nil {
+		log.Printf("notify: render %s: %v", e.Type, err)
+		return
+	}
+	d := &Delivery{
+		OrderID:   o.ID,
+		Event:     e.Type,
+		Channel:   ch.Name(),
+		Recipient: to,
+		Message:   text,
+		Status:    StatusPending,
+	}
+	if err := s.db.WithContext(ctx).Create(d).Error; err != nil {
+		log.Printf("notify: save delivery: %v", err)
+		return
+	}
+	for _, wait := range retryDelays {
+		time.Sleep(wait)
+		if s.attempt(ctx, ch, d) {
+			return
+		}
+	}
+	log.Printf("notify: %s to %s failed after %d attempts: %s", d.Event, d.Channel, d.Attempts, d.Error)
+}
+
+// route：有 LINE userId 且已設定 LINE 通道就用 LINE，否則用手機簡訊
+func (s *Service) route(o *order.Order) (Channel, string) {
+	if ch, ok := s.channels[ChannelLINE]; ok && o.BuyerLineID != "" {
+		return ch, o.BuyerLineID
+	}
+	if ch, ok := s.channels[ChannelSMS]; ok && o.BuyerPhone != "" {
+		return ch, o.BuyerPhone
+	}
+	return nil, ""
+}
+
+func (s *Service) render(name string, o *order.Order) (string, error) {
+	var buf bytes.Buffer
+	err := s.tpl.ExecuteTemplate(&buf, name+".txt", map[string]any{
+		"Shop":     s.shop,
+		"Order":    o,
+		"Shipping": o.ShippingMethod.Label(),
+		"Carrier":  order.CarrierLabel(o.Carrier),
+	})
+	return strings.TrimSpace(buf.String()), err
+}
+
+// attempt：發送一次並更新紀錄
+func (s *Service) attempt(ctx context.Context, ch Channel, d *Delivery) bool {
+	sendCtx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	providerID, err := ch.Send(sendCtx, d.Recipient, d.Message)
+	cancel()
+
+	d.Attempts++
+	updates := map[string]any{"attempts": d.Attempts}
+	if err != nil {
+		d.Status, d.Error = StatusFailed, truncate(err.Error(), 255)
+		updates["status"], updates["error"] = d.Status, d.Error
+	} else {
+		now := time.Now()
+		d.Status, d.Error, d.ProviderID, d.SentAt = StatusSent, "", providerID, &now
+		updates["status"], updates["error"], updates["provider_id"], updates["sent_at"] = d.Status, "", providerID, now
+	}
+	if err := s.db.WithContext(ctx).Model(&Delivery{ID: d.ID}).Updates(updates).Error; err != nil {
+		log.Printf("notify: update delivery %d: %v", d.ID, err)
+	}
+	return d.Status == StatusSent
+}
+
+// ListQuery：後台發送紀錄篩選
+type ListQuery struct {
+	OrderID uint64
+	Status  string
+	Channel string
+	Limit   int
+	Offset  int
+}
+
+func (s *Service) List(ctx context.Context, q ListQuery) ([]Delivery, int64, error) {
+	tx := s.db.WithContext(ctx).Model(&Delivery{})
+	if q.OrderID != 0 {
+		tx = tx.Where("order_id = ?", q.OrderID)
+	}
+	if q.Status != "" {
+		tx = tx.Where("status = ?", q.Status)
+	}
+	if q.Channel != "" {
+		tx = tx.Where("channel = ?", q.Channel)
+	}
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []Delivery
+	err := tx.Order("id DESC").Limit(q.Limit).Offset(q.Offset).Find(&list).Error
+	return list, total, err
+}
+
+// Resend：後台手動重送（同一則訊息、同一通道，只嘗試一次）
+func (s *Service) Resend(ctx context.Context, id uint64) (*Delivery, error) {
+	var d Delivery
+	if err := s.db.WithContext(ctx).First(&d, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	ch, ok := s.channels[d.Channel]
+	if !ok {
+		return nil, errors.New("notify: channel " + d.Channel + " is not configured")
+	}
+	s.attempt(ctx, ch, &d)
+	return &d, nil
+}
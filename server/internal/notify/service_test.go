@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/order"
+)
+
+const testLineUser = "U0123456789abcdef0123456789abcdef"
+
+func TestRoute(t *testing.T) {
+	sms, line := NewFakeChannel(ChannelSMS), NewFakeChannel(ChannelLINE)
+	s, err := NewService(nil, "Zeusshop", sms, line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	smsOnly, err := NewService(nil, "Zeusshop", sms)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		svc     *Service
+		order   order.Order
+		channel string
+		to      string
+	}{
+		{"verified line user", s, order.Order{BuyerPhone: "0912345678", BuyerLineID: testLineUser}, ChannelLINE, testLineUser},
+		{"no line user", s, order.Order{BuyerPhone: "0912345678"}, ChannelSMS, "0912345678"},
+		{"line not configured", smsOnly, order.Order{BuyerPhone: "0912345678", BuyerLineID: testLineUser}, ChannelSMS, "0912345678"},
+		{"no contact", s, order.Order{}, "", ""},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			sms.Reset()
+			line.Reset()
+			ch, to := tc.svc.route(&tc.order)
+			if tc.channel == "" {
+				if ch != nil {
+					t.Fatalf("route = %s, want none", ch.Name())
+				}
+				return
+			}
+			if ch == nil || ch.Name() != tc.channel || to != tc.to {
+				t.Fatalf("route = %v %q, want %s %q", ch, to, tc.channel, tc.to)
+			}
+			if _, err := ch.Send(context.Background(), to, "hello"); err != nil {
+				t.Fatal(err)
+			}
+			got, other := sms.Sent(), line.Sent()
+			if tc.channel == ChannelLINE {
+				got, other = other, got
+			}
+			if len(got) != 1 || got[0].To != tc.to || len(other) != 0 {
+				t.Fatalf("sent on %s = %+v, other channel = %+v", tc.channel, got, other)
+			}
+		})
+	}
+}
+
+func TestLIFFVerifier(t *testing.T) {
+	const channelID = "1650000000"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.FormValue("client_id") != channelID {
+			http.Error(w, `{"error":"invalid_request"}`, http.StatusBadRequest)
+			return
+		}
+		switch r.FormValue("id_token") {
+		case "good":
+			w.Write([]byte(`{"iss":"https://access.line.me","sub":"` + testLineUser + `","aud":"` + channelID + `"}`))
+		case "other-channel":
+			w.Write([]byte(`{"iss":"https://access.line.me","sub":"` + testLineUser + `","aud":"1659999999"}`))
+		default:
+			http.Error(w, `{"error":"invalid_request","error_description":"Invalid IdToken."}`, http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	v := NewLIFFVerifier(channelID)
+	v.URL = srv.URL
+
+	sub, err := v.Verify(context.Background(), "good")
+	if err != nil || sub != testLineUser {
+		t.Fatalf("Verify(good) = %q, %v", sub, err)
+	}
+	for _, tok := range []string{"", "forged", "other-channel"} {
+		if _, err := v.Verify(context.Background(), tok); !errors.Is(err, ErrInvalidIDToken) {
+			t.Errorf("Verify(%q) err = %v, want ErrInvalidIDToken", tok, err)
+		}
+	}
+}
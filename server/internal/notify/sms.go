@@ -0,0 +1,68 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// SMSGateway：通用 HTTP 簡訊閘道
+// POST {URL}  Authorization: Bearer {APIKey}
+//
+//	{"to": "+886912345678", "message": "...", "sender": "..."}
+//
+// 2xx 視為成功，回應中的 id（若有）記為服務商訊息編號
+type SMSGateway struct {
+	URL    string
+	APIKey string
+	Sender string
+	Client *http.Client
+}
+
+func NewSMSGateway(url, apiKey, sender string) *SMSGateway {
+	return &SMSGateway{URL: url, APIKey: apiKey, Sender: sender, Client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+func (g *SMSGateway) Name() string { return ChannelSMS }
+
+func (g *SMSGateway) Send(ctx context.Context, to, text string) (string, error) {
+	to = normalizePhone(to)
+	if to == "" {
+		return "", ErrNoRecipient
+	}
+	body, _ := json.Marshal(map[string]string{"to": to, "message": text, "sender": g.Sender})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.URL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if g.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+g.APIKey)
+	}
+	res, err := g.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	raw, _ := io.ReadAll(io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode/100 != 2 {
+		return "", fmt.Errorf("sms gateway: %s: %s", res.Status, truncate(string(raw), 200))
+	}
+	var out struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(raw, &out)
+	return out.ID, nil
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
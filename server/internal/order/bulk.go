@@ -0,0 +1,160 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/events"
+)
+
+// 單次批次最多處理的訂單數
+const MaxBulk = 200
+
+var ErrOrderNotFound = errors.New("order not found")
+
+// BulkResult：批次操作中單一訂單的結果（失敗不影響其他訂單）
+type BulkResult struct {
+	ID    uint64 `json:"id"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// Shipment：指定一張訂單的物流業者與單號
+type Shipment struct {
+	ID         uint64 `json:"id"`
+	Carrier    string `json:"carrier"`
+	TrackingNo string `json:"trackingNo"`
+}
+
+// runBulk：逐筆執行（每筆各自一個交易），收集結果
+func runBulk(ids []uint64, fn func(id uint64) error) []BulkResult {
+	out := make([]BulkResult, 0, len(ids))
+	seen := map[uint64]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		res := BulkResult{ID: id, OK: true}
+		if err := fn(id); err != nil {
+			res.OK = false
+			res.Error = err.Error()
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				res.Error = ErrOrderNotFound.Error()
+			}
+		}
+		out = append(out, res)
+	}
+	return out
+}
+
+func (r *Repo) BulkUpdateStatus(ids []uint64, status string) []BulkResult {
+	return runBulk(ids, func(id uint64) error {
+		return r.AdminUpdateStatus(id, status)
+	})
+}
+
+func (r *Repo) BulkConfirmPayment(ids []uint64, paid bool, note string) []BulkResult {
+	return runBulk(ids, func(id uint64) error {
+		_, err := r.AdminConfirmPayment(id, paid, note)
+		return err
+	})
+}
+
+func (r *Repo) BulkAssignShipments(list []Shipment) []BulkResult {
+	byID := make(map[uint64]Shipment, len(list))
+	ids := make([]uint64, 0, len(list))
+	for _, s := range list {
+		byID[s.ID] = s
+		ids = append(ids, s.ID)
+	}
+	return runBulk(ids, func(id uint64) error {
+		s := byID[id]
+		return r.AdminAssignShipment(id, s.Carrier, s.TrackingNo)
+	})
+}
+
+// AdminAssignShipment：後台整張訂單出貨（平台統一寄送）
+// 主訂單記錄物流業者 / 單號；尚未出貨的子訂單與項目一併標記為已出貨（缺貨、已完成者不動）
+func (r *Repo) AdminAssignShipment(id uint64, carrier, trackingNo string) error {
+	carrier = strings.TrimSpace(carrier)
+	trackingNo = strings.TrimSpace(trackingNo)
+	if trackingNo == "" {
+		return ErrTrackingRequired
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var o Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&o, id).Error; err != nil {
+			return err
+		}
+		switch o.Status {
+		case StatusCompleted, StatusOutOfStock:
+			return fmt.Errorf("%w: order is %s", ErrInvalidTransition, o.Status)
+		}
+		now := time.Now()
+		if err := tx.Model(&Order{ID: id}).Updates(map[string]any{
+			"carrier":     carrier,
+			"tracking_no": trackingNo,
+			"status":      StatusShipped,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&OrderItem{}).
+			Where("order_id = ? AND status IN ?", id, []string{ItemPending, ItemAcknowledged, ""}).
+			Updates(map[string]any{"status": ItemShipped, "tracking_no": trackingNo}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&SubOrder{}).
+			Where("order_id = ? AND status IN ?", id, []string{StatusPending, StatusProcessing}).
+			Updates(map[string]any{"status": StatusShipped, "tracking_no": trackingNo}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&SubOrder{}).
+			Where("order_id = ? AND status = ? AND shipped_at IS NULL", id, StatusShipped).
+			Update("shipped_at", now).Error; err != nil {
+			return err
+		}
+		return recordHistory(tx, OrderHistory{
+			OrderID:    id,
+			Actor:      "admin",
+			Action:     "ship",
+			FromStatus: o.Status,
+			ToStatus:   StatusShipped,
+			Note:       strings.TrimSpace(carrier + " " + trackingNo),
+		})
+	})
+	if err != nil {
+		return err
+	}
+	// 補登 / 更正單號也通知（顧客拿到的是最新單號）
+	events.Publish(events.Event{Type: events.OrderShipped, OrderID: id})
+	return nil
+}
+
+// PrintDocuments：依傳入順序取得訂單（含項目與子訂單），找不到的以結果回報
+func (r *Repo) PrintDocuments(ids []uint64) ([]Order, []BulkResult, error) {
+	var list []Order
+	if err := r.db.Preload("Items").Preload("SubOrders.Items").
+		Where("id IN ?", ids).Find(&list).Error; err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[uint64]Order, len(list))
+	for _, o := range list {
+		byID[o.ID] = o
+	}
+	orders := make([]Order, 0, len(list))
+	results := runBulk(ids, func(id uint64) error {
+		o, ok := byID[id]
+		if !ok {
+			return ErrOrderNotFound
+		}
+		orders = append(orders, o)
+		return nil
+	})
+	return orders, results, nil
+}
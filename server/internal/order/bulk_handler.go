@@ -0,0 +1,151 @@
+package order
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/export"
+)
+
+// bindIDs：共用的 ids 檢查（1 ~ MaxBulk 筆）
+func bindIDs(c *gin.Context, ids []uint64) bool {
+	if len(ids) == 0 || len(ids) > MaxBulk {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ids must contain 1-" + strconv.Itoa(MaxBulk) + " orders"})
+		return false
+	}
+	return true
+}
+
+func sendBulk(c *gin.Context, results []BulkResult) {
+	failed := 0
+	for _, r := range results {
+		if !r.OK {
+			failed++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"results":   results,
+		"succeeded": len(results) - failed,
+		"failed":    failed,
+	})
+}
+
+// 後台：批次改狀態 POST /api/admin/orders/bulk/status {ids, status}
+func (h *Handler) AdminBulkStatus(c *gin.Context) {
+	var in struct {
+		IDs    []uint64 `json:"ids"`
+		Status string   `json:"status"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil || in.Status == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if !bindIDs(c, in.IDs) {
+		return
+	}
+	sendBulk(c, h.repo.BulkUpdateStatus(in.IDs, in.Status))
+}
+
+// 後台：批次確認收款 POST /api/admin/orders/bulk/payment {ids, paid, note}
+func (h *Handler) AdminBulkPayment(c *gin.Context) {
+	var in struct {
+		IDs  []uint64 `json:"ids"`
+		Paid *bool    `json:"paid"`
+		Note string   `json:"note"`
+	}
+	// paid 必填：漏帶欄位不能被當成「已收款」
+	if err := c.ShouldBindJSON(&in); err != nil || in.Paid == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if !bindIDs(c, in.IDs) {
+		return
+	}
+	sendBulk(c, h.repo.BulkConfirmPayment(in.IDs, *in.Paid, in.Note))
+}
+
+// 後台：批次出貨 POST /api/admin/orders/bulk/shipments
+// {shipments: [{id, carrier, trackingNo}]}
+func (h *Handler) AdminBulkShipments(c *gin.Context) {
+	var in struct {
+		Shipments []Shipment `json:"shipments"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	ids := make([]uint64, 0, len(in.Shipments))
+	for _, s := range in.Shipments {
+		ids = append(ids, s.ID)
+	}
+	if !bindIDs(c, ids) {
+		return
+	}
+	sendBulk(c, h.repo.BulkAssignShipments(in.Shipments))
+}
+
+// 後台：單筆出貨 PUT /api/admin/orders/:id/shipment {carrier, trackingNo}
+func (h *Handler) AdminAssignShipment(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var in Shipment
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if err := h.repo.AdminAssignShipment(id, in.Carrier, in.TrackingNo); err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		case errors.Is(err, ErrTrackingRequired), errors.Is(err, ErrInvalidTransition):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// 後台：批次列印（揀貨單 / 出貨單）POST /api/admin/orders/bulk/print {ids}
+// 預設回 JSON 供前端排版列印；?format=csv|xlsx 下載揀貨明細
+func (h *Handler) AdminBulkPrint(c *gin.Context) {
+	var in struct {
+		IDs []uint64 `json:"ids"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	if !bindIDs(c, in.IDs) {
+		return
+	}
+	orders, results, err := h.repo.PrintDocuments(in.IDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	t := &export.Table{Sheet: "picking", Header: []string{
+		"訂單編號", "子訂單", "買家", "電話", "寄送方式", "門市", "地址", "商品", "數量", "項目狀態",
+	}}
+	for _, o := range orders {
+		for _, it := range o.Items {
+			subNo := ""
+			for _, so := range o.SubOrders {
+				if so.ID == it.SubOrderID {
+					subNo = so.SubOrderNo
+				}
+			}
+			t.Add(o.OrderNo, subNo, o.BuyerName, o.BuyerPhone, string(o.ShippingMethod),
+				o.StoreCode, o.Address, it.ProductName, it.Quantity, it.Status)
+		}
+	}
+	if export.Send(c, c.Query("format"), "picking-list", t) {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"orders": orders, "results": results})
+}
@@ -0,0 +1,166 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/events"
+)
+
+var (
+	ErrItemNotFound      = errors.New("order item not found")
+	ErrInvalidTransition = errors.New("invalid status transition")
+	ErrTrackingRequired  = errors.New("tracking number required")
+)
+
+// 廠商對訂單項目的操作
+const (
+	ActionAcknowledge = "acknowledge"
+	ActionShip        = "ship"
+	ActionOutOfStock  = "out_of_stock"
+)
+
+// VendorUpdateItem：廠商更新自己商品的訂單項目（確認 / 出貨 / 缺貨），
+// 並連動子訂單與主訂單狀態、寫入異動紀錄
+func (r *Repo) VendorUpdateItem(vendorID string, itemID uint64, action, trackingNo, note string) (*OrderItem, error) {
+	trackingNo = strings.TrimSpace(trackingNo)
+	var (
+		it      OrderItem
+		shipped bool
+	)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("vendor_id = ?", vendorID).
+			First(&it, itemID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrItemNotFound
+			}
+			return err
+		}
+
+		from := it.Status
+		if from == "" {
+			from = ItemPending
+		}
+		if from != ItemPending && from != ItemAcknowledged {
+			return ErrInvalidTransition
+		}
+
+		updates := map[string]any{}
+		switch action {
+		case ActionAcknowledge:
+			if from != ItemPending {
+				return ErrInvalidTransition
+			}
+			it.Status = ItemAcknowledged
+		case ActionShip:
+			if trackingNo == "" {
+				return ErrTrackingRequired
+			}
+			it.Status = ItemShipped
+			it.TrackingNo = trackingNo
+			updates["tracking_no"] = trackingNo
+			if note == "" {
+				note = trackingNo
+			}
+		case ActionOutOfStock:
+			it.Status = ItemOutOfStock
+		default:
+			return fmt.Errorf("invalid action")
+		}
+		updates["status"] = it.Status
+		if err := tx.Model(&OrderItem{ID: it.ID}).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if err := recalcSubOrder(tx, it.SubOrderID, trackingNo); err != nil {
+			return err
+		}
+		var err error
+		if shipped, err = recalcOrderStatus(tx, it.OrderID); err != nil {
+			return err
+		}
+		return recordHistory(tx, OrderHistory{
+			OrderID:    it.OrderID,
+			SubOrderID: it.SubOrderID,
+			ItemID:     it.ID,
+			Actor:      "vendor",
+			ActorID:    vendorID,
+			Action:     action,
+			FromStatus: from,
+			ToStatus:   it.Status,
+			Note:       note,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if shipped {
+		events.Publish(events.Event{Type: events.OrderShipped, OrderID: it.OrderID})
+	}
+	return &it, nil
+}
+
+// recalcSubOrder：依項目狀態推算子訂單狀態；首次出貨時記下物流單號與時間
+func recalcSubOrder(tx *gorm.DB, subOrderID uint64, trackingNo string) error {
+	var so SubOrder
+	if err := tx.First(&so, subOrderID).Error; err != nil {
+		return err
+	}
+	if so.Status == StatusCompleted {
+		return nil
+	}
+	var statuses []string
+	if err := tx.Model(&OrderItem{}).Where("sub_order_id = ?", subOrderID).Pluck("status", &statuses).Error; err != nil {
+		return err
+	}
+	updates := map[string]any{"status": deriveStatus(statuses)}
+	if trackingNo != "" && so.TrackingNo == "" {
+		updates["tracking_no"] = trackingNo
+	}
+	if updates["status"] == StatusShipped && so.ShippedAt == nil {
+		updates["shipped_at"] = time.Now()
+	}
+	return tx.Model(&so).Updates(updates).Error
+}
+
+// syncItems：後台直接改子訂單 / 主訂單狀態時，項目（缺貨除外）一併對齊，
+// 否則廠商下次更新項目時 recalcSubOrder 會依舊的項目狀態把後台的修改推回去
+func syncItems(tx *gorm.DB, status, trackingNo string, query string, args ...any) error {
+	updates := map[string]any{}
+	switch status {
+	case StatusPending:
+		updates["status"] = ItemPending
+	case StatusProcessing:
+		updates["status"] = ItemAcknowledged
+	case StatusShipped, StatusCompleted:
+		updates["status"] = ItemShipped
+		if trackingNo != "" {
+			updates["tracking_no"] = trackingNo
+		}
+	default:
+		return nil
+	}
+	return tx.Model(&OrderItem{}).
+		Where(query, args...).
+		Where("status <> ?", ItemOutOfStock).
+		Updates(updates).Error
+}
+
+// History：訂單異動紀錄（新到舊）
+func (r *Repo) History(orderID uint64) ([]OrderHistory, error) {
+	var list []OrderHistory
+	if err := r.db.Where("order_id = ?", orderID).Order("id DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func recordHistory(tx *gorm.DB, h OrderHistory) error {
+	return tx.Create(&h).Error
+}
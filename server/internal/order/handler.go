@@ -1,22 +1,44 @@
 package order
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/events"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/logistics"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/tz"
 )
 
 type Handler struct {
-	db   *gorm.DB
-	repo *Repo
+	db    *gorm.DB
+	repo  *Repo
+	store StoreLookup
+	line  LineVerifier
 }
 
+// StoreLookup：以電子地圖的 state 取回顧客選定的門市
+type StoreLookup func(ctx context.Context, state string) (*logistics.Store, error)
+
+// LineVerifier：驗證 LIFF ID token，回傳其中的 LINE userId（sub）
+type LineVerifier func(ctx context.Context, idToken string) (string, error)
+
 func NewHandler(db *gorm.DB) *Handler {
 	return &Handler{db: db, repo: NewRepo(db)}
 }
 
+// SetStoreLookup：啟用超商電子地圖選店（未設定時只接受手動輸入的 storeCode）
+func (h *Handler) SetStoreLookup(fn StoreLookup) { h.store = fn }
+
+// SetLineVerifier：啟用 LIFF 下單的 LINE 通知（未設定時忽略 lineIdToken，一律走簡訊）
+func (h *Handler) SetLineVerifier(fn LineVerifier) { h.line = fn }
+
 // 客戶下單：交易中呼叫 repo.Create(tx, in)，成功回傳 orderNo
 func (h *Handler) Create(c *gin.Context) {
 	var in CreateOrderInput
@@ -24,6 +46,32 @@ func (h *Handler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if in.StoreToken != "" && h.store != nil {
+		st, err := h.store(c.Request.Context(), in.StoreToken)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		in.store = st
+	}
+	if in.LineIDToken != "" && h.line != nil {
+		sub, err := h.line(c.Request.Context(), in.LineIDToken)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid line id token"})
+			return
+		}
+		in.lineUserID = sub
+	}
+	if in.ShippingMethod == Shipping711 {
+		if in.store != nil && in.store.Brand != logistics.Brand711 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "store is not a 7-11"})
+			return
+		}
+		if in.store == nil && strings.TrimSpace(in.StoreCode) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "store required"})
+			return
+		}
+	}
 
 	var out *Order
 	if err := h.db.Transaction(func(tx *gorm.DB) error {
@@ -34,6 +82,7 @@ func (h *Handler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	events.Publish(events.Event{Type: events.OrderCreated, OrderID: out.ID})
 
 	c.JSON(http.StatusCreated, gin.H{
 		"orderId": out.ID,
@@ -43,13 +92,65 @@ func (h *Handler) Create(c *gin.Context) {
 }
 
 // 後台：訂單列表
+// 支援：
+//
+//	?status=pending|processing|shipped|completed|out_of_stock
+//	?payment=unpaid|reported|paid
+//	?shipping=pickup|sevencv|home
+//	?vendor=<vendorId>|platform
+//	?from=2025-09-01&to=2025-09-30   -> 建立日期（台北時間、含當日）
+//	?q=keyword                        -> 訂單編號 / 電話（前綴）、姓名、匯款後五碼
+//	?sort=new(預設)|old|total_desc|total_asc
+//	?limit=50&offset=0
 func (h *Handler) AdminList(c *gin.Context) {
-	items, err := h.repo.AdminList()
+	q := AdminListQuery{
+		Status:         strings.TrimSpace(c.Query("status")),
+		PaymentStatus:  strings.TrimSpace(c.Query("payment")),
+		ShippingMethod: strings.TrimSpace(c.Query("shipping")),
+		VendorID:       strings.TrimSpace(c.Query("vendor")),
+		Keyword:        strings.TrimSpace(c.Query("q")),
+		Sort:           strings.ToLower(strings.TrimSpace(c.Query("sort"))),
+	}
+	var err error
+	if q.From, err = parseDay(c.Query("from")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return
+	}
+	if q.To, err = parseDay(c.Query("to")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return
+	}
+	if !q.To.IsZero() {
+		q.To = q.To.AddDate(0, 0, 1)
+	}
+	q.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if q.Limit < 1 || q.Limit > 200 {
+		q.Limit = 50
+	}
+	q.Offset, _ = strconv.Atoi(c.Query("offset"))
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	items, total, err := h.repo.AdminList(q)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"items": items})
+	c.JSON(http.StatusOK, gin.H{
+		"items":  items,
+		"total":  total,
+		"limit":  q.Limit,
+		"offset": q.Offset,
+	})
+}
+
+// parseDay：YYYY-MM-DD（台北時間）；空字串回零值
+func parseDay(v string) (time.Time, error) {
+	if v = strings.TrimSpace(v); v == "" {
+		return time.Time{}, nil
+	}
+	return time.ParseInLocation("2006-01-02", v, tz.Taipei)
 }
 
 // 後台：單筆訂單（含 Items）
@@ -63,7 +164,18 @@ func (h *Handler) AdminGet(c *gin.Context) {
 	c.JSON(http.StatusOK, o)
 }
 
-// 後台：更新狀態（pending/shipped/completed）
+// 後台：訂單異動紀錄
+func (h *Handler) AdminHistory(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	list, err := h.repo.History(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
+
+// 後台：更新狀態（pending/processing/shipped/completed）
 func (h *Handler) AdminUpdateStatus(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 	var in struct {
@@ -80,6 +192,76 @@ func (h *Handler) AdminUpdateStatus(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// 後台：更新子訂單狀態（pending/processing/shipped/completed，可附物流單號）
+func (h *Handler) AdminUpdateSubOrder(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var in struct {
+		Status     string `json:"status"`
+		TrackingNo string `json:"trackingNo"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil || in.Status == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+	so, err := h.repo.AdminUpdateSubOrder(id, in.Status, strings.TrimSpace(in.TrackingNo))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "sub order not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, so)
+}
+
+// 後台：訂單項目退款
+func (h *Handler) AdminRefundItem(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("itemId"), 10, 64)
+	var in struct {
+		Amount int64  `json:"amount"`
+		Reason string `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	it, err := h.repo.AdminRefundItem(id, in.Amount, strings.TrimSpace(in.Reason))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "item not found"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, it)
+}
+
+// 後台：確認收款 {paid: true|false, note}
+func (h *Handler) AdminConfirmPayment(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var in struct {
+		Paid *bool  `json:"paid"`
+		Note string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	paid := in.Paid == nil || *in.Paid
+	o, err := h.repo.AdminConfirmPayment(id, paid, in.Note)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, o)
+}
+
 // 後台：刪除訂單（含項目）
 func (h *Handler) AdminDelete(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
@@ -90,29 +272,62 @@ func (h *Handler) AdminDelete(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-// 客戶回填匯款後五碼
+// 客戶回填匯款後五碼（公開 API）
+// 須帶訂單編號或訂購人電話核對身分；只有未付款的訂單可以回填，已付款 / 已回填者不可覆寫
 func (h *Handler) UpdateRemit(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 	var in struct {
-		Last5 string `json:"last5"`
-		Note  string `json:"note"`
+		OrderNo string `json:"orderNo"`
+		Phone   string `json:"phone"`
+		Last5   string `json:"last5"`
+		Note    string `json:"note"`
 	}
 	if err := c.ShouldBindJSON(&in); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
 		return
 	}
 	// 基本檢查（5 碼數字）
-	if len(in.Last5) != 5 {
+	if len(in.Last5) != 5 || strings.Trim(in.Last5, "0123456789") != "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "last5 must be 5 digits"})
 		return
 	}
+	orderNo, phone := strings.TrimSpace(in.OrderNo), strings.TrimSpace(in.Phone)
+	if orderNo == "" && phone == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "orderNo or phone required"})
+		return
+	}
 
-	if err := h.db.Model(&Order{ID: id}).Updates(map[string]any{
-		"remit_last5":  in.Last5,
-		"payment_note": in.Note,
-	}).Error; err != nil {
+	// 身分不符與訂單不存在同樣回 404，避免被拿來猜訂單
+	owner := h.db.Where("id = ?", id)
+	if orderNo != "" {
+		owner = owner.Where("order_no = ?", orderNo)
+	} else {
+		owner = owner.Where("buyer_phone = ?", phone)
+	}
+	var o Order
+	if err := owner.Select("id", "payment_status").Take(&o).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
+
+	res := h.db.Model(&Order{}).
+		Where("id = ? AND payment_status = ?", o.ID, PaymentUnpaid).
+		Updates(map[string]any{
+			"remit_last5":    in.Last5,
+			"payment_note":   in.Note,
+			"payment_status": PaymentReported,
+		})
+	if res.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": res.Error.Error()})
+		return
+	}
+	if res.RowsAffected == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "payment already reported or confirmed"})
+		return
+	}
 	c.Status(http.StatusNoContent)
 }
@@ -0,0 +1,48 @@
+package order
+
+import "fmt"
+
+// Label：寄送方式中文名稱（通知信 / 簡訊用）
+func (m ShippingMethod) Label() string {
+	switch m {
+	case ShippingPickup:
+		return "自取"
+	case Shipping711:
+		return "7-11 店到店"
+	case ShippingHome:
+		return "宅配"
+	}
+	return string(m)
+}
+
+// CarrierLabel：物流業者代碼 → 中文名稱（未知代碼原樣回傳）
+func CarrierLabel(code string) string {
+	switch code {
+	case "tcat":
+		return "黑貓宅急便"
+	case "hct":
+		return "新竹物流"
+	case "711":
+		return "7-ELEVEN 交貨便"
+	}
+	return code
+}
+
+// FormatAmount：金額以「分」儲存 → NT$1,234（有零頭才顯示小數）
+func FormatAmount(cents int64) string {
+	neg := cents < 0
+	if neg {
+		cents = -cents
+	}
+	s := fmt.Sprint(cents / 100)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	if c := cents % 100; c != 0 {
+		s += fmt.Sprintf(".%02d", c)
+	}
+	if neg {
+		s = "-" + s
+	}
+	return "NT$" + s
+}
@@ -0,0 +1,77 @@
+package order
+
+import (
+	"strings"
+	"time"
+)
+
+// AdminListQuery：後台訂單列表的篩選 / 排序 / 分頁條件（零值 = 不篩選）
+type AdminListQuery struct {
+	Status         string
+	PaymentStatus  string
+	ShippingMethod string
+	VendorID       string // 含該廠商子訂單的訂單；"platform" = 平台自營
+	From           time.Time
+	To             time.Time // 不含
+	Keyword        string    // 訂單編號 / 買家姓名 / 電話 / 匯款後五碼
+	Sort           string    // new（預設）| old | total_desc | total_asc
+	Limit          int
+	Offset         int
+}
+
+// 排序一律以 id 做最後的比較，分頁時順序才不會跳動
+var adminListSorts = map[string]string{
+	"new":        "id DESC",
+	"old":        "id ASC",
+	"total_desc": "total_amount DESC, id DESC",
+	"total_asc":  "total_amount ASC, id DESC",
+}
+
+// 列表（不 preload items），回傳符合條件的總筆數
+func (r *Repo) AdminList(q AdminListQuery) ([]Order, int64, error) {
+	tx := r.db.Model(&Order{})
+	if q.Status != "" {
+		tx = tx.Where("status = ?", q.Status)
+	}
+	if q.PaymentStatus != "" {
+		tx = tx.Where("payment_status = ?", q.PaymentStatus)
+	}
+	if q.ShippingMethod != "" {
+		tx = tx.Where("shipping_method = ?", q.ShippingMethod)
+	}
+	switch q.VendorID {
+	case "":
+	case "platform":
+		tx = tx.Where("id IN (SELECT order_id FROM sub_orders WHERE vendor_id = '')")
+	default:
+		tx = tx.Where("id IN (SELECT order_id FROM sub_orders WHERE vendor_id = ?)", q.VendorID)
+	}
+	if !q.From.IsZero() {
+		tx = tx.Where("created_at >= ?", q.From)
+	}
+	if !q.To.IsZero() {
+		tx = tx.Where("created_at < ?", q.To)
+	}
+	if kw := strings.TrimSpace(q.Keyword); kw != "" {
+		like := "%" + kw + "%"
+		// 訂單編號 / 電話多半是輸入開頭，前綴比對可走索引
+		prefix := kw + "%"
+		tx = tx.Where("order_no LIKE ? OR buyer_phone LIKE ? OR buyer_name LIKE ? OR remit_last5 = ?",
+			prefix, prefix, like, kw)
+	}
+
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	orderBy, ok := adminListSorts[q.Sort]
+	if !ok {
+		orderBy = adminListSorts["new"]
+	}
+	var os []Order
+	if err := tx.Order(orderBy).Limit(q.Limit).Offset(q.Offset).Find(&os).Error; err != nil {
+		return nil, 0, err
+	}
+	return os, total, nil
+}
@@ -0,0 +1,40 @@
+package order
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration：一次性資料修正的執行紀錄
+type Migration struct {
+	Name      string `gorm:"primaryKey;size:64"`
+	CreatedAt time.Time
+}
+
+func (Migration) TableName() string { return "order_migrations" }
+
+const migrationLegacyPayments = "legacy_completed_paid"
+
+// BackfillPayments：付款狀態上線前的訂單一律是 unpaid，其中已完成者一次性標為已付款，
+// 否則會被營運報表算成未收款。只執行一次，之後完成但未收款的訂單維持原狀
+func BackfillPayments(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var n int64
+		if err := tx.Model(&Migration{}).Where("name = ?", migrationLegacyPayments).Count(&n).Error; err != nil {
+			return err
+		}
+		if n > 0 {
+			return nil
+		}
+		if err := tx.Model(&Order{}).
+			Where("status = ? AND payment_status = ?", StatusCompleted, PaymentUnpaid).
+			UpdateColumns(map[string]any{
+				"payment_status": PaymentPaid,
+				"paid_at":        gorm.Expr("COALESCE(paid_at, updated_at)"),
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&Migration{Name: migrationLegacyPayments}).Error
+	})
+}
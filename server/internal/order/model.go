@@ -1,6 +1,11 @@
 package order
 
-import "time"
+import (
+	"time"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/invoice"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/logistics"
+)
 
 // 寄送方式
 type ShippingMethod string
@@ -11,11 +16,28 @@ const (
 	ShippingHome   ShippingMethod = "home"
 )
 
-// 訂單狀態
+// 訂單 / 子訂單狀態
 const (
-	StatusPending   = "pending"
-	StatusShipped   = "shipped"
-	StatusCompleted = "completed"
+	StatusPending    = "pending"
+	StatusProcessing = "processing" // 部分項目已由廠商確認或出貨
+	StatusShipped    = "shipped"
+	StatusCompleted  = "completed"
+	StatusOutOfStock = "out_of_stock" // 所有項目皆缺貨
+)
+
+// 付款狀態（ATM 匯款：客戶回填後五碼 → 後台對帳確認）
+const (
+	PaymentUnpaid   = "unpaid"
+	PaymentReported = "reported" // 客戶已回填後五碼，待對帳
+	PaymentPaid     = "paid"
+)
+
+// 訂單項目（廠商出貨）狀態
+const (
+	ItemPending      = "pending"
+	ItemAcknowledged = "acknowledged"
+	ItemShipped      = "shipped"
+	ItemOutOfStock   = "out_of_stock"
 )
 
 // 前端直傳的商品資訊（不再查 DB）
@@ -27,12 +49,19 @@ type ItemInput struct {
 }
 
 type CreateOrderInput struct {
-	BuyerName      string         `json:"buyerName" binding:"required"`
-	BuyerPhone     string         `json:"buyerPhone" binding:"required"`
-	ShippingMethod ShippingMethod `json:"shippingMethod" binding:"required"` // pickup | sevencv | home
-	StoreCode      string         `json:"storeCode"`
-	Address        string         `json:"address"`
-	Items          []ItemInput    `json:"items" binding:"required"`
+	BuyerName      string          `json:"buyerName" binding:"required"`
+	BuyerPhone     string          `json:"buyerPhone" binding:"required"`
+	BuyerEmail     string          `json:"buyerEmail" binding:"omitempty,email"`
+	LineIDToken    string          `json:"lineIdToken"`                       // LIFF 內下單時帶 liff.getIDToken()，伺服器驗證後取 LINE userId
+	ShippingMethod ShippingMethod  `json:"shippingMethod" binding:"required"` // pickup | sevencv | home
+	StoreCode      string          `json:"storeCode"`
+	StoreToken     string          `json:"storeToken"` // 電子地圖選店的 cvsState（有帶則以選店結果為準）
+	Address        string          `json:"address"`
+	Invoice        invoice.Request `json:"invoice"` // 電子發票（未帶則為會員載具）
+	Items          []ItemInput     `json:"items" binding:"required"`
+
+	store      *logistics.Store // 由 StoreToken 取回的門市（handler 填入）
+	lineUserID string           // 由 LineIDToken 驗證出的 LINE userId（handler 填入）
 }
 
 type OrderCounter struct {
@@ -41,28 +70,80 @@ type OrderCounter struct {
 }
 
 type Order struct {
+	ID             uint64          `gorm:"primaryKey" json:"id"`
+	OrderNo        string          `gorm:"index;size:32" json:"orderNo"`
+	BuyerName      string          `gorm:"size:100" json:"buyerName"`
+	BuyerPhone     string          `gorm:"size:32;index" json:"buyerPhone"`
+	BuyerEmail     string          `gorm:"size:190" json:"buyerEmail"`
+	BuyerLineID    string          `gorm:"size:64" json:"buyerLineId"`
+	ShippingMethod ShippingMethod  `gorm:"size:16;index" json:"shippingMethod"`
+	StoreCode      string          `json:"storeCode"`
+	StoreBrand     string          `gorm:"size:16" json:"storeBrand"`
+	StoreName      string          `gorm:"size:64" json:"storeName"`
+	StoreAddress   string          `gorm:"size:255" json:"storeAddress"`
+	Address        string          `json:"address"`
+	Carrier        string          `gorm:"size:32" json:"carrier"` // 物流業者（tcat / hct / 711 …）
+	TrackingNo     string          `gorm:"size:64" json:"trackingNo"`
+	Status         string          `gorm:"size:16;default:pending;index" json:"status"`
+	TotalAmount    int64           `json:"totalAmount"`
+	RemitLast5     string          `gorm:"size:5" json:"remitLast5"`
+	PaymentNote    string          `gorm:"size:255" json:"paymentNote"`
+	PaymentStatus  string          `gorm:"size:16;default:unpaid;index" json:"paymentStatus"`
+	PaidAt         *time.Time      `json:"paidAt"`
+	Invoice        invoice.Request `gorm:"embedded;embeddedPrefix:invoice_" json:"invoice"`
+	CreatedAt      time.Time       `gorm:"index" json:"createdAt"`
+	UpdatedAt      time.Time       `json:"updatedAt"`
+	Items          []OrderItem     `json:"items"`
+	SubOrders      []SubOrder      `json:"subOrders,omitempty"`
+}
+
+// 廠商子訂單：一張訂單依商品所屬廠商拆開，各自出貨、各自計算金額
+type SubOrder struct {
 	ID             uint64         `gorm:"primaryKey" json:"id"`
-	OrderNo        string         `gorm:"index;size:32" json:"orderNo"`
-	BuyerName      string         `json:"buyerName"`
-	BuyerPhone     string         `json:"buyerPhone"`
-	ShippingMethod ShippingMethod `json:"shippingMethod"`
-	StoreCode      string         `json:"storeCode"`
-	Address        string         `json:"address"`
-	Status         string         `gorm:"default:pending" json:"status"`
-	TotalAmount    int64          `json:"totalAmount"`
-	RemitLast5     string         `gorm:"size:5" json:"remitLast5"`
-	PaymentNote    string         `gorm:"size:255" json:"paymentNote"`
+	OrderID        uint64         `gorm:"index;not null" json:"orderId"`
+	SubOrderNo     string         `gorm:"size:40;index" json:"subOrderNo"` // 主訂單號-序號，例：1140914001-2
+	VendorID       string         `gorm:"size:36;index" json:"vendorId"`   // 空字串 = 平台自營商品
+	Status         string         `gorm:"size:16;default:pending" json:"status"`
+	ShippingMethod ShippingMethod `gorm:"size:16" json:"shippingMethod"`
+	ShippingFee    int64          `json:"shippingFee"` // 訂單目前不收運費，子訂單不分攤，恆為 0
+	TrackingNo     string         `gorm:"size:64" json:"trackingNo"`
+	ShippedAt      *time.Time     `json:"shippedAt"`
+	CompletedAt    *time.Time     `gorm:"index" json:"completedAt"` // 廠商結算以此為準
+	ItemsTotal     int64          `json:"itemsTotal"`
+	Total          int64          `json:"total"` // = ItemsTotal + ShippingFee
 	CreatedAt      time.Time      `json:"createdAt"`
 	UpdatedAt      time.Time      `json:"updatedAt"`
-	Items          []OrderItem    `json:"items"`
+	Items          []OrderItem    `gorm:"foreignKey:SubOrderID" json:"items"`
 }
 
 type OrderItem struct {
 	ID          uint64 `gorm:"primaryKey" json:"id"`
 	OrderID     uint64 `json:"orderId"`
+	SubOrderID  uint64 `gorm:"index" json:"subOrderId"`
+	VendorID    string `gorm:"size:36;index" json:"vendorId"`
 	ProductID   uint64 `json:"productId"`
 	ProductName string `json:"productName"`
 	UnitPrice   int64  `json:"unitPrice"`
 	Quantity    int    `json:"quantity"`
 	Subtotal    int64  `json:"subtotal"`
+	Status      string `gorm:"size:16;default:pending" json:"status"`
+	TrackingNo  string `gorm:"size:64" json:"trackingNo"`
+	// 退款（累計，不可超過小計）
+	RefundAmount int64      `json:"refundAmount"`
+	RefundedAt   *time.Time `json:"refundedAt"`
+}
+
+// 訂單異動紀錄（誰、何時、把什麼從哪個狀態改成哪個狀態）
+type OrderHistory struct {
+	ID         uint64    `gorm:"primaryKey" json:"id"`
+	OrderID    uint64    `gorm:"index;not null" json:"orderId"`
+	SubOrderID uint64    `json:"subOrderId"`
+	ItemID     uint64    `json:"itemId"`
+	Actor      string    `gorm:"size:16" json:"actor"` // admin / vendor
+	ActorID    string    `gorm:"size:36" json:"actorId"`
+	Action     string    `gorm:"size:32" json:"action"`
+	FromStatus string    `gorm:"size:16" json:"fromStatus"`
+	ToStatus   string    `gorm:"size:16" json:"toStatus"`
+	Note       string    `gorm:"size:255" json:"note"`
+	CreatedAt  time.Time `json:"createdAt"`
 }
@@ -3,12 +3,18 @@ package order
 import (
 	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/events"
 )
 
+var lineUserID = regexp.MustCompile(`^U[0-9a-f]{32}$`)
+
 type Repo struct{ db *gorm.DB }
 
 func NewRepo(db *gorm.DB) *Repo { return &Repo{db: db} }
@@ -19,6 +25,16 @@ func (r *Repo) Create(tx *gorm.DB, in CreateOrderInput) (*Order, error) {
 		return nil, fmt.Errorf("no items")
 	}
 
+	// LINE userId（由 LIFF ID token 驗證取得，有則以 LINE 通知）：U + 32 碼十六進位
+	if in.lineUserID != "" && !lineUserID.MatchString(in.lineUserID) {
+		return nil, fmt.Errorf("invalid line user id")
+	}
+
+	// 電子發票格式檢查（同時正規化欄位）
+	if err := in.Invoice.Validate(); err != nil {
+		return nil, err
+	}
+
 	var items []OrderItem
 	var total int64
 
@@ -40,6 +56,7 @@ func (r *Repo) Create(tx *gorm.DB, in CreateOrderInput) (*Order, error) {
 			UnitPrice:   it.UnitPrice,
 			Quantity:    it.Quantity,
 			Subtotal:    int64(it.Quantity) * it.UnitPrice,
+			Status:      ItemPending,
 		}
 		items = append(items, oi)
 		total += oi.Subtotal
@@ -48,12 +65,18 @@ func (r *Repo) Create(tx *gorm.DB, in CreateOrderInput) (*Order, error) {
 	o := &Order{
 		BuyerName:      in.BuyerName,
 		BuyerPhone:     in.BuyerPhone,
+		BuyerEmail:     strings.TrimSpace(in.BuyerEmail),
+		BuyerLineID:    in.lineUserID,
 		ShippingMethod: in.ShippingMethod,
 		StoreCode:      in.StoreCode,
 		Address:        in.Address,
+		Invoice:        in.Invoice,
 		Status:         StatusPending,
+		PaymentStatus:  PaymentUnpaid,
 		TotalAmount:    total,
-		Items:          items,
+	}
+	if st := in.store; st != nil {
+		o.StoreCode, o.StoreBrand, o.StoreName, o.StoreAddress = st.Code, st.Brand, st.Name, st.Address
 	}
 	if err := tx.Create(o).Error; err != nil {
 		return nil, err
@@ -84,22 +107,18 @@ func (r *Repo) Create(tx *gorm.DB, in CreateOrderInput) (*Order, error) {
 		return nil, err
 	}
 
-	return o, nil
-}
-
-// 列表（不 preload items）
-func (r *Repo) AdminList() ([]Order, error) {
-	var os []Order
-	if err := r.db.Order("id DESC").Find(&os).Error; err != nil {
+	// 依廠商拆子訂單（items 於此一併寫入）
+	if err := splitSubOrders(tx, o, items); err != nil {
 		return nil, err
 	}
-	return os, nil
+
+	return o, nil
 }
 
-// 單筆（含 items）
+// 單筆（含 items 與子訂單）
 func (r *Repo) AdminGet(id uint64) (*Order, error) {
 	var o Order
-	if err := r.db.Preload("Items").First(&o, id).Error; err != nil {
+	if err := r.db.Preload("Items").Preload("SubOrders.Items").First(&o, id).Error; err != nil {
 		return nil, err
 	}
 	return &o, nil
@@ -107,11 +126,181 @@ func (r *Repo) AdminGet(id uint64) (*Order, error) {
 
 func (r *Repo) AdminUpdateStatus(id uint64, status string) error {
 	switch status {
-	case StatusPending, StatusShipped, StatusCompleted:
+	case StatusPending, StatusProcessing, StatusShipped, StatusCompleted:
 	default:
 		return fmt.Errorf("invalid status")
 	}
-	return r.db.Model(&Order{ID: id}).Update("status", status).Error
+	// 後台直接改主訂單時，子訂單一併同步（缺貨的子訂單維持原狀）
+	var o Order
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id", "status").First(&o, id).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&Order{ID: id}).Update("status", status).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&SubOrder{}).
+			Where("order_id = ? AND status <> ?", id, StatusOutOfStock).
+			Update("status", status).Error; err != nil {
+			return err
+		}
+		if err := syncItems(tx, status, "", "order_id = ?", id); err != nil {
+			return err
+		}
+		if status == StatusCompleted {
+			if err := tx.Model(&SubOrder{}).
+				Where("order_id = ? AND status = ? AND completed_at IS NULL", id, StatusCompleted).
+				Update("completed_at", time.Now()).Error; err != nil {
+				return err
+			}
+		}
+		return recordHistory(tx, OrderHistory{
+			OrderID:    id,
+			Actor:      "admin",
+			Action:     "status",
+			FromStatus: o.Status,
+			ToStatus:   status,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if status == StatusShipped && o.Status != StatusShipped {
+		events.Publish(events.Event{Type: events.OrderShipped, OrderID: id})
+	}
+	return nil
+}
+
+// 更新單一子訂單狀態 / 物流單號，並重新推算主訂單狀態
+func (r *Repo) AdminUpdateSubOrder(id uint64, status, trackingNo string) (*SubOrder, error) {
+	switch status {
+	case StatusPending, StatusProcessing, StatusShipped, StatusCompleted:
+	default:
+		return nil, fmt.Errorf("invalid status")
+	}
+	var (
+		so      SubOrder
+		shipped bool
+	)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&so, id).Error; err != nil {
+			return err
+		}
+		updates := map[string]any{"status": status}
+		if trackingNo != "" {
+			updates["tracking_no"] = trackingNo
+		}
+		if status == StatusShipped && so.ShippedAt == nil {
+			updates["shipped_at"] = time.Now()
+		}
+		if status == StatusCompleted && so.CompletedAt == nil {
+			updates["completed_at"] = time.Now()
+		}
+		from := so.Status
+		if err := tx.Model(&so).Updates(updates).Error; err != nil {
+			return err
+		}
+		if err := syncItems(tx, status, trackingNo, "sub_order_id = ?", so.ID); err != nil {
+			return err
+		}
+		var err error
+		if shipped, err = recalcOrderStatus(tx, so.OrderID); err != nil {
+			return err
+		}
+		return recordHistory(tx, OrderHistory{
+			OrderID:    so.OrderID,
+			SubOrderID: so.ID,
+			Actor:      "admin",
+			Action:     "status",
+			FromStatus: from,
+			ToStatus:   status,
+			Note:       trackingNo,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if shipped {
+		events.Publish(events.Event{Type: events.OrderShipped, OrderID: so.OrderID})
+	}
+	return &so, nil
+}
+
+// 後台：訂單項目退款（可分次，累計不得超過小計）
+func (r *Repo) AdminRefundItem(itemID uint64, amount int64, reason string) (*OrderItem, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("invalid refund amount")
+	}
+	var it OrderItem
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&it, itemID).Error; err != nil {
+			return err
+		}
+		if it.RefundAmount+amount > it.Subtotal {
+			return fmt.Errorf("refund exceeds subtotal")
+		}
+		now := time.Now()
+		it.RefundAmount += amount
+		it.RefundedAt = &now
+		if err := tx.Model(&OrderItem{ID: it.ID}).Updates(map[string]any{
+			"refund_amount": it.RefundAmount,
+			"refunded_at":   now,
+		}).Error; err != nil {
+			return err
+		}
+		return recordHistory(tx, OrderHistory{
+			OrderID:    it.OrderID,
+			SubOrderID: it.SubOrderID,
+			ItemID:     it.ID,
+			Actor:      "admin",
+			Action:     "refund",
+			Note:       fmt.Sprintf("%d %s", amount, reason),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &it, nil
+}
+
+// 後台：確認收款（paid=false 可撤回為未付款）
+func (r *Repo) AdminConfirmPayment(id uint64, paid bool, note string) (*Order, error) {
+	var (
+		o    Order
+		from string
+	)
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&o, id).Error; err != nil {
+			return err
+		}
+		from = o.PaymentStatus
+		o.PaymentStatus, o.PaidAt = PaymentUnpaid, nil
+		if paid {
+			now := time.Now()
+			o.PaymentStatus, o.PaidAt = PaymentPaid, &now
+		}
+		if err := tx.Model(&Order{ID: o.ID}).Updates(map[string]any{
+			"payment_status": o.PaymentStatus,
+			"paid_at":        o.PaidAt,
+		}).Error; err != nil {
+			return err
+		}
+		return recordHistory(tx, OrderHistory{
+			OrderID:    o.ID,
+			Actor:      "admin",
+			Action:     "payment",
+			FromStatus: from,
+			ToStatus:   o.PaymentStatus,
+			Note:       note,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if paid && from != PaymentPaid {
+		events.Publish(events.Event{Type: events.OrderPaid, OrderID: o.ID})
+	}
+	return &o, nil
 }
 
 func (r *Repo) AdminDelete(id uint64) error {
@@ -119,6 +308,12 @@ func (r *Repo) AdminDelete(id uint64) error {
 		if err := tx.Where("order_id = ?", id).Delete(&OrderItem{}).Error; err != nil {
 			return err
 		}
+		if err := tx.Where("order_id = ?", id).Delete(&SubOrder{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("order_id = ?", id).Delete(&OrderHistory{}).Error; err != nil {
+			return err
+		}
 		if err := tx.Delete(&Order{}, id).Error; err != nil {
 			return err
 		}
@@ -0,0 +1,176 @@
+package order
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/product"
+)
+
+// splitSubOrders：依商品的 vendor_id 分組，每組建立一張子訂單。
+// items 若尚未寫入（ID = 0）會一併建立；已存在的（舊資料回填）則只更新歸屬。
+// 訂單本身不收運費（TotalAmount 只含商品），子訂單也就不分攤運費：ShippingFee 為 0，Total = ItemsTotal。
+func splitSubOrders(tx *gorm.DB, o *Order, items []OrderItem) error {
+	vendorOf, err := lookupVendors(tx, items)
+	if err != nil {
+		return err
+	}
+
+	// 保留商品在購物車中的先後順序
+	var keys []string
+	groups := map[string][]OrderItem{}
+	for _, it := range items {
+		vid := vendorOf[it.ProductID]
+		if _, ok := groups[vid]; !ok {
+			keys = append(keys, vid)
+		}
+		groups[vid] = append(groups[vid], it)
+	}
+
+	o.Items = nil
+	o.SubOrders = nil
+	for i, vid := range keys {
+		so := SubOrder{
+			OrderID:        o.ID,
+			SubOrderNo:     fmt.Sprintf("%s-%d", o.OrderNo, i+1),
+			VendorID:       vid,
+			Status:         o.Status,
+			ShippingMethod: o.ShippingMethod,
+		}
+		for _, it := range groups[vid] {
+			so.ItemsTotal += it.Subtotal
+		}
+		so.Total = so.ItemsTotal // 不含運費，見上方說明
+		if err := tx.Create(&so).Error; err != nil {
+			return err
+		}
+
+		group := groups[vid]
+		for j := range group {
+			group[j].OrderID = o.ID
+			group[j].SubOrderID = so.ID
+			group[j].VendorID = vid
+			if group[j].ID == 0 {
+				if err := tx.Create(&group[j]).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Model(&OrderItem{ID: group[j].ID}).Updates(map[string]any{
+				"sub_order_id": so.ID,
+				"vendor_id":    vid,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		so.Items = group
+		o.SubOrders = append(o.SubOrders, so)
+		o.Items = append(o.Items, group...)
+	}
+	return nil
+}
+
+// 查出各商品所屬廠商（查不到或 productId = 0 視為平台自營）
+func lookupVendors(tx *gorm.DB, items []OrderItem) (map[uint64]string, error) {
+	ids := make([]uint64, 0, len(items))
+	for _, it := range items {
+		if it.ProductID != 0 {
+			ids = append(ids, it.ProductID)
+		}
+	}
+	out := map[uint64]string{}
+	if len(ids) == 0 {
+		return out, nil
+	}
+	var rows []product.Product
+	if err := tx.Model(&product.Product{}).Select("id", "vendor_id").Where("id IN ?", ids).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, p := range rows {
+		out[p.ID] = p.VendorID
+	}
+	return out, nil
+}
+
+// BackfillSubOrders：啟動時為尚未拆分的舊訂單補上子訂單
+func BackfillSubOrders(db *gorm.DB) error {
+	var ids []uint64
+	if err := db.Model(&Order{}).
+		Where("NOT EXISTS (SELECT 1 FROM sub_orders so WHERE so.order_id = orders.id)").
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			var o Order
+			if err := tx.Preload("Items").First(&o, id).Error; err != nil {
+				return err
+			}
+			if len(o.Items) == 0 {
+				return nil
+			}
+			return splitSubOrders(tx, &o, o.Items)
+		}); err != nil {
+			return fmt.Errorf("backfill order %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// recalcOrderStatus：子訂單狀態變動後重新推算主訂單狀態（缺貨的子訂單不列入計算）。
+// shipped 表示主訂單因此轉為已出貨，呼叫端應在 commit 後發布 events.OrderShipped
+func recalcOrderStatus(tx *gorm.DB, orderID uint64) (shipped bool, err error) {
+	var statuses []string
+	if err := tx.Model(&SubOrder{}).Where("order_id = ?", orderID).Pluck("status", &statuses).Error; err != nil {
+		return false, err
+	}
+	if len(statuses) == 0 {
+		return false, nil
+	}
+	var o Order
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").First(&o, orderID).Error; err != nil {
+		return false, err
+	}
+	next := deriveStatus(statuses)
+	if next == o.Status {
+		return false, nil
+	}
+	if err := tx.Model(&Order{ID: orderID}).Update("status", next).Error; err != nil {
+		return false, err
+	}
+	return next == StatusShipped, nil
+}
+
+// deriveStatus：由下層（項目或子訂單）狀態推出上層狀態。
+// 全部缺貨 → out_of_stock；其餘全部完成 → completed；全部已出貨 → shipped；
+// 有任何進度 → processing；否則 pending。
+func deriveStatus(statuses []string) string {
+	live, shipped, completed, started := 0, 0, 0, 0
+	for _, st := range statuses {
+		switch st {
+		case StatusOutOfStock:
+			continue
+		case StatusCompleted:
+			completed++
+			shipped++
+		case StatusShipped:
+			shipped++
+		case StatusProcessing, ItemAcknowledged:
+			started++
+		}
+		live++
+	}
+	switch {
+	case live == 0:
+		return StatusOutOfStock
+	case completed == live:
+		return StatusCompleted
+	case shipped == live:
+		return StatusShipped
+	case shipped+started > 0:
+		return StatusProcessing
+	}
+	return StatusPending
+}
@@ -1,6 +1,8 @@
 package product
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"sort"
 	"strconv"
@@ -26,22 +28,59 @@ func NewHandler(db *gorm.DB, rdb *cache.Redis) *Handler {
 }
 
 // GET /api/products
+// 只回前台可見的商品（上架、已通過審核、廠商已核准）
 // 支援：
 //   ?category=home|3C|beauty
-//   ?visible=1           -> 僅回上架（預設就會這樣；visible=true 或 is_active=true 任一為真）
 //   ?q=keyword           -> name/description 模糊搜尋
 //   ?sort=price_asc|price_desc|name_asc|new(預設；id DESC)
 //   ?limit=20&offset=0   -> 簡單分頁
 func (h *Handler) List(c *gin.Context) {
-	var rows []Product
+	h.list(c, h.db.Model(&Product{}).Scopes(publicProducts))
+}
 
+// 後台：GET /api/admin/products（含下架 / 審核中 / 停權廠商的商品）
+// 參數同 List，另可用 ?visible=1|0 篩選上下架
+func (h *Handler) AdminList(c *gin.Context) {
 	q := h.db.Model(&Product{})
-
-	// 預設：只回上架（任一欄位為 true）
-	visibleParam := strings.TrimSpace(c.Query("visible"))
-	if visibleParam == "" || visibleParam == "1" || strings.EqualFold(visibleParam, "true") {
+	switch v := strings.TrimSpace(c.Query("visible")); {
+	case v == "1" || strings.EqualFold(v, "true"):
 		q = q.Where("(visible = ? OR is_active = ?)", true, true)
+	case v == "0" || strings.EqualFold(v, "false"):
+		q = q.Where("visible = ? AND is_active = ?", false, false)
 	}
+	h.list(c, q)
+}
+
+// ListByVendor：廠商店家頁商品，篩選 / 排序 / 分頁與 List 相同；
+// 一律只列前台可見的商品（visible 參數無效）
+func (h *Handler) ListByVendor(c *gin.Context, vendorID string) {
+	h.list(c, h.db.Model(&Product{}).Where("vendor_id = ?", vendorID).Scopes(publicProducts))
+}
+
+// CountPublic：某廠商目前上架中的商品數
+func (h *Handler) CountPublic(ctx context.Context, vendorID string) (int64, error) {
+	var n int64
+	err := h.db.WithContext(ctx).Model(&Product{}).
+		Where("vendor_id = ?", vendorID).
+		Scopes(publicProducts).
+		Count(&n).Error
+	return n, err
+}
+
+// publicProducts：前台可見的商品（上架、已通過審核、廠商已核准），不受查詢參數影響
+func publicProducts(q *gorm.DB) *gorm.DB {
+	return q.Where("(visible = ? OR is_active = ?)", true, true).
+		Where("(review_status = '' OR review_status = ?)", ReviewApproved).
+		Scopes(vendorApproved)
+}
+
+// vendorApproved：廠商商品僅顯示已核准廠商（停權 / 審核中的商品保留資料但不對外）
+func vendorApproved(q *gorm.DB) *gorm.DB {
+	return q.Where("(vendor_id = '' OR vendor_id IS NULL OR vendor_id IN (SELECT id FROM vendors WHERE status = ?))", "approved")
+}
+
+func (h *Handler) list(c *gin.Context, q *gorm.DB) {
+	var rows []Product
 
 	// 類別
 	if cat := strings.TrimSpace(c.Query("category")); cat != "" {
@@ -88,13 +127,31 @@ func (h *Handler) List(c *gin.Context) {
 
 // GET /api/products/:id
 func (h *Handler) Get(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var p Product
+	if err := h.db.WithContext(c.Request.Context()).Scopes(publicProducts).First(&p, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"ok": false, "error": "NOT_FOUND"})
+		return
+	}
+	// 瀏覽數只用於統計，失敗不影響回應
+	_ = h.repo.RecordView(c.Request.Context(), p.ID)
+	c.JSON(http.StatusOK, gin.H{"ok": true, "product": p})
+}
+
+// 後台：GET /api/admin/products/:id（不論上架 / 審核狀態）
+func (h *Handler) AdminGet(c *gin.Context) {
 	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
 	p, err := h.repo.Get(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"ok": false, "error": "NOT_FOUND"})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"ok": true, "product": p})
+	images, err := Images(h.db.WithContext(c.Request.Context()), p.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": "DB_ERROR"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "product": p, "images": images})
 }
 
 // Admin：新增商品
@@ -127,6 +184,7 @@ func (h *Handler) Update(c *gin.Context) {
 	}
 
 	// 覆蓋可編輯欄位
+	before := p.Stock
 	p.Name = in.Name
 	p.Description = in.Description
 	p.Price = in.Price
@@ -144,6 +202,7 @@ func (h *Handler) Update(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": err.Error()})
 		return
 	}
+	CheckLowStock(p, before)
 	c.JSON(http.StatusOK, gin.H{"ok": true, "product": p})
 }
 
@@ -157,6 +216,68 @@ func (h *Handler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"ok": true})
 }
 
+// Admin：商品審核佇列 ?status=pending（預設）&vendorId=
+func (h *Handler) AdminListReviews(c *gin.Context) {
+	status := c.DefaultQuery("status", RevisionPending)
+	list, err := h.repo.ListRevisions(c.Request.Context(), strings.TrimSpace(c.Query("vendorId")), status, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": err.Error()})
+		return
+	}
+	// 一併帶出線上版本，方便比對差異
+	ids := make([]uint64, 0, len(list))
+	for _, rev := range list {
+		ids = append(ids, rev.ProductID)
+	}
+	live := map[uint64]Product{}
+	if len(ids) > 0 {
+		var ps []Product
+		if err := h.db.WithContext(c.Request.Context()).Where("id IN ?", ids).Find(&ps).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": err.Error()})
+			return
+		}
+		for _, p := range ps {
+			live[p.ID] = p
+		}
+	}
+	items := make([]gin.H, 0, len(list))
+	for _, rev := range list {
+		items = append(items, gin.H{"revision": rev, "current": live[rev.ProductID]})
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "items": items})
+}
+
+// Admin：核准 PUT /api/admin/product-reviews/:id/approve {"comment":""}
+func (h *Handler) AdminApproveReview(c *gin.Context) { h.review(c, true) }
+
+// Admin：退回 PUT /api/admin/product-reviews/:id/reject {"comment":"原因"}
+func (h *Handler) AdminRejectReview(c *gin.Context) { h.review(c, false) }
+
+func (h *Handler) review(c *gin.Context, approve bool) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var in struct {
+		Comment string `json:"comment"`
+	}
+	_ = c.ShouldBindJSON(&in)
+	if !approve && strings.TrimSpace(in.Comment) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"ok": false, "error": "COMMENT_REQUIRED"})
+		return
+	}
+	rev, err := h.repo.ReviewRevision(c.Request.Context(), id, approve, in.Comment)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "error": "NOT_FOUND"})
+		case errors.Is(err, ErrRevisionClosed):
+			c.JSON(http.StatusConflict, gin.H{"ok": false, "error": "REVISION_CLOSED"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"ok": true, "revision": rev})
+}
+
 // ---- helpers ----
 func toInt(s string, def int) int {
 	if s == "" {
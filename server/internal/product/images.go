@@ -0,0 +1,28 @@
+package product
+
+import "gorm.io/gorm"
+
+// Images：商品圖庫（依排序）
+func Images(tx *gorm.DB, productID uint64) ([]string, error) {
+	urls := []string{}
+	err := tx.Model(&ProductImage{}).
+		Where("product_id = ?", productID).
+		Order("sort ASC, id ASC").
+		Pluck("url", &urls).Error
+	return urls, err
+}
+
+// SetImages：以 urls 取代商品圖庫
+func SetImages(tx *gorm.DB, productID uint64, urls []string) error {
+	if err := tx.Where("product_id = ?", productID).Delete(&ProductImage{}).Error; err != nil {
+		return err
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+	rows := make([]ProductImage, 0, len(urls))
+	for i, u := range urls {
+		rows = append(rows, ProductImage{ProductID: productID, URL: u, Sort: i})
+	}
+	return tx.Create(&rows).Error
+}
@@ -16,6 +16,9 @@ type Product struct {
 	VendorID string `gorm:"size:36;index" json:"vendorId"`
 	Spec     string `gorm:"type:text" json:"spec"`
 
+	// 審核狀態（啟用商品審核時，廠商新商品為 pending，核准前不會上架）
+	ReviewStatus string `gorm:"size:16;index" json:"reviewStatus"`
+
 	CreatedAt time.Time `json:"createdAt"`
 	UpdatedAt time.Time `json:"updatedAt"`
 }
@@ -30,3 +33,12 @@ type ProductImage struct {
 	Sort      int       `gorm:"index;default:0" json:"sort"`
 	CreatedAt time.Time `json:"createdAt"`
 }
+
+// 商品每日瀏覽數（前台 GET /api/products/:id 累加；供廠商分析轉換率）
+type ProductViewDaily struct {
+	ProductID uint64 `gorm:"primaryKey;autoIncrement:false" json:"productId"`
+	Day       string `gorm:"primaryKey;size:10" json:"day"` // 2006-01-02
+	Views     int64  `gorm:"not null;default:0" json:"views"`
+}
+
+func (ProductViewDaily) TableName() string { return "product_view_daily" }
@@ -0,0 +1,165 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 商品審核狀態（Product.ReviewStatus；空字串為審核制度前的舊商品，視同已核准）
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// 異動申請狀態
+const (
+	RevisionPending    = "pending"
+	RevisionApproved   = "approved"
+	RevisionRejected   = "rejected"
+	RevisionSuperseded = "superseded" // 審核前又送出新版本
+)
+
+// 異動類型
+const (
+	RevisionCreate = "create" // 新商品（或尚未核准過的商品重送）
+	RevisionUpdate = "update" // 已上架商品的重要欄位修改
+)
+
+var ErrRevisionClosed = errors.New("revision is not pending")
+
+// ProductRevision：廠商送審的商品內容（名稱、價格、主圖與圖庫、描述）。
+// 核准前線上商品維持上一次核准的版本。
+type ProductRevision struct {
+	ID          uint64     `gorm:"primaryKey" json:"id"`
+	ProductID   uint64     `gorm:"index;not null" json:"productId"`
+	VendorID    string     `gorm:"size:36;index" json:"vendorId"`
+	Kind        string     `gorm:"size:16" json:"kind"`
+	Name        string     `json:"name"`
+	Price       int64      `json:"price"`
+	ImageURL    string     `gorm:"column:image_url" json:"imageUrl"`
+	Images      []string   `gorm:"serializer:json;type:text" json:"images"` // 圖庫；nil = 不變動（舊資料）
+	Description string     `json:"description"`
+	Publish     bool       `json:"publish"` // 新商品核准後是否直接上架
+	Status      string     `gorm:"size:16;index;default:pending" json:"status"`
+	Comment     string     `gorm:"size:500" json:"comment"`
+	ReviewedAt  *time.Time `json:"reviewedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// Approved：商品是否已通過（或不需要）審核
+func (p *Product) Approved() bool {
+	return p.ReviewStatus == "" || p.ReviewStatus == ReviewApproved
+}
+
+// MaterialChanged：名稱、價格、主圖、圖庫（含順序）、描述任一與線上版本不同；images 為線上圖庫
+func (p *Product) MaterialChanged(rev *ProductRevision, images []string) bool {
+	return p.Name != rev.Name || p.Price != rev.Price || p.ImageURL != rev.ImageURL || p.Description != rev.Description ||
+		!slices.Equal(images, rev.Images)
+}
+
+// SubmitRevision：送出新版本並讓同商品先前待審的版本失效
+func SubmitRevision(tx *gorm.DB, rev *ProductRevision) error {
+	if err := tx.Model(&ProductRevision{}).
+		Where("product_id = ? AND status = ?", rev.ProductID, RevisionPending).
+		Update("status", RevisionSuperseded).Error; err != nil {
+		return err
+	}
+	rev.Status = RevisionPending
+	return tx.Create(rev).Error
+}
+
+// PendingRevision：商品目前待審的版本（沒有則回 nil）
+func PendingRevision(tx *gorm.DB, productID uint64) (*ProductRevision, error) {
+	var rev ProductRevision
+	err := tx.Where("product_id = ? AND status = ?", productID, RevisionPending).
+		Order("id DESC").First(&rev).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// ListRevisions：vendorID / status 空字串代表不限
+func (r *Repo) ListRevisions(ctx context.Context, vendorID, status string, productID uint64) ([]ProductRevision, error) {
+	q := r.db.WithContext(ctx).Model(&ProductRevision{})
+	if vendorID != "" {
+		q = q.Where("vendor_id = ?", vendorID)
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if productID != 0 {
+		q = q.Where("product_id = ?", productID)
+	}
+	var list []ProductRevision
+	if err := q.Order("id DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// ReviewRevision：核准時把送審內容套用到線上商品；退回則線上維持原樣
+func (r *Repo) ReviewRevision(ctx context.Context, id uint64, approve bool, comment string) (*ProductRevision, error) {
+	var rev ProductRevision
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&rev, id).Error; err != nil {
+			return err
+		}
+		if rev.Status != RevisionPending {
+			return ErrRevisionClosed
+		}
+		now := time.Now()
+		rev.Comment = strings.TrimSpace(comment)
+		rev.ReviewedAt = &now
+
+		productUpdates := map[string]any{}
+		if approve {
+			rev.Status = RevisionApproved
+			productUpdates["name"] = rev.Name
+			productUpdates["price"] = rev.Price
+			productUpdates["image_url"] = rev.ImageURL
+			productUpdates["description"] = rev.Description
+			if rev.Kind == RevisionCreate {
+				productUpdates["review_status"] = ReviewApproved
+				productUpdates["visible"] = rev.Publish
+				productUpdates["is_active"] = rev.Publish
+			}
+		} else {
+			rev.Status = RevisionRejected
+			if rev.Kind == RevisionCreate {
+				productUpdates["review_status"] = ReviewRejected
+			}
+		}
+		if err := tx.Model(&ProductRevision{ID: rev.ID}).Updates(map[string]any{
+			"status":      rev.Status,
+			"comment":     rev.Comment,
+			"reviewed_at": now,
+		}).Error; err != nil {
+			return err
+		}
+		if approve && rev.Images != nil {
+			if err := SetImages(tx, rev.ProductID, rev.Images); err != nil {
+				return err
+			}
+		}
+		if len(productUpdates) == 0 {
+			return nil
+		}
+		return tx.Model(&Product{ID: rev.ProductID}).Updates(productUpdates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &rev, nil
+}
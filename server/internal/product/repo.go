@@ -2,11 +2,14 @@ package product
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	// 你的快取封裝（若之後沒用到也無妨）
 	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/cache"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/tz"
 )
 
 type Repo struct {
@@ -54,3 +57,10 @@ func (r *Repo) Update(ctx context.Context, p *Product) error {
 func (r *Repo) Delete(ctx context.Context, id uint64) error {
 	return r.db.WithContext(ctx).Delete(&Product{}, id).Error
 }
+
+// RecordView：商品當日（台北時間）瀏覽數 +1，與廠商分析的切日一致
+func (r *Repo) RecordView(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		DoUpdates: clause.Assignments(map[string]any{"views": gorm.Expr("views + 1")}),
+	}).Create(&ProductViewDaily{ProductID: id, Day: time.Now().In(tz.Taipei).Format("2006-01-02"), Views: 1}).Error
+}
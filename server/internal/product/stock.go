@@ -0,0 +1,14 @@
+package product
+
+import "github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/events"
+
+// LowStockThreshold：庫存降到此數量（含）以下視為低庫存（main 依 LOW_STOCK_THRESHOLD 設定）
+var LowStockThreshold = 5
+
+// CheckLowStock：庫存由門檻以上降到門檻以下時發布 product.low_stock；
+// 已在低庫存時再次調整不重複發布
+func CheckLowStock(p *Product, before int) {
+	if before > LowStockThreshold && p.Stock <= LowStockThreshold {
+		events.Publish(events.Event{Type: events.ProductLowStock, ProductID: p.ID})
+	}
+}
@@ -0,0 +1,71 @@
+// Package secretbox 提供欄位層級加密（AES-256-GCM），用於銀行帳號等敏感資料
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+)
+
+const prefix = "v1:"
+
+var ErrMalformed = errors.New("secretbox: malformed ciphertext")
+
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New：以任意長度的字串金鑰建立（內部以 SHA-256 轉為 32 bytes）
+func New(key string) (*Box, error) {
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Seal：加密後輸出 "v1:" + base64(nonce|ciphertext)；空字串維持空字串
+func (b *Box) Seal(plain string) (string, error) {
+	if plain == "" {
+		return "", nil
+	}
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	out := b.aead.Seal(nonce, nonce, []byte(plain), nil)
+	return prefix + base64.StdEncoding.EncodeToString(out), nil
+}
+
+// Open：解密 Seal 的結果
+func (b *Box) Open(sealed string) (string, error) {
+	if sealed == "" {
+		return "", nil
+	}
+	if !strings.HasPrefix(sealed, prefix) {
+		return "", ErrMalformed
+	}
+	raw, err := base64.StdEncoding.DecodeString(sealed[len(prefix):])
+	if err != nil {
+		return "", ErrMalformed
+	}
+	ns := b.aead.NonceSize()
+	if len(raw) < ns {
+		return "", ErrMalformed
+	}
+	plain, err := b.aead.Open(nil, raw[:ns], raw[ns:], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
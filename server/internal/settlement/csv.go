@@ -0,0 +1,42 @@
+package settlement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// WriteCSV：結算單明細（含 BOM，Excel 直接開不會亂碼）
+func WriteCSV(w io.Writer, st *Statement) error {
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"結算期間", st.PeriodStart.Format("2006-01-02"), st.PeriodEnd.AddDate(0, 0, -1).Format("2006-01-02")})
+	_ = cw.Write([]string{"狀態", st.Status})
+	_ = cw.Write([]string{"銷售總額", itoa(st.Gross)})
+	_ = cw.Write([]string{"退款", itoa(st.Refunds)})
+	_ = cw.Write([]string{"平台抽成", itoa(st.Commission)})
+	_ = cw.Write([]string{"應付金額", itoa(st.Net)})
+	_ = cw.Write(nil)
+	_ = cw.Write([]string{"類型", "訂單編號", "商品", "類別", "數量", "金額", "退款", "抽成(%)", "抽成", "淨額", "完成時間"})
+	for _, l := range st.Lines {
+		_ = cw.Write([]string{
+			l.Kind,
+			l.OrderNo,
+			l.ProductName,
+			l.Category,
+			strconv.Itoa(l.Quantity),
+			itoa(l.Amount),
+			itoa(l.Refund),
+			strconv.FormatFloat(float64(l.RateBP)/100, 'f', 2, 64),
+			itoa(l.Commission),
+			itoa(l.Net),
+			l.CompletedAt.Format("2006-01-02 15:04"),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func itoa(n int64) string { return strconv.FormatInt(n, 10) }
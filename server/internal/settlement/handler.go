@@ -0,0 +1,178 @@
+package settlement
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// 後台：結算 / 抽成管理
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// GET /api/admin/settlements?vendorId=&status=
+func (h *Handler) AdminList(c *gin.Context) {
+	list, err := h.svc.List(c.Request.Context(), strings.TrimSpace(c.Query("vendorId")), strings.TrimSpace(c.Query("status")))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list})
+}
+
+// GET /api/admin/settlements/:id
+func (h *Handler) AdminGet(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	st, err := h.svc.Get(c.Request.Context(), id, "")
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// GET /api/admin/settlements/:id/csv
+func (h *Handler) AdminCSV(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	st, err := h.svc.Get(c.Request.Context(), id, "")
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	SendCSV(c, st)
+}
+
+// POST /api/admin/settlements/generate {"from":"2025-09-01","to":"2025-10-01"}
+// 未帶期間時結算上個月
+func (h *Handler) AdminGenerate(c *gin.Context) {
+	var in struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+	_ = c.ShouldBindJSON(&in)
+	start, end := PreviousMonth(time.Now())
+	if in.From != "" || in.To != "" {
+		var err1, err2 error
+		start, err1 = time.ParseInLocation("2006-01-02", in.From, time.Local)
+		end, err2 = time.ParseInLocation("2006-01-02", in.To, time.Local)
+		if err1 != nil || err2 != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from/to must be YYYY-MM-DD"})
+			return
+		}
+	}
+	list, err := h.svc.Generate(c.Request.Context(), start, end)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"items": list})
+}
+
+// PUT /api/admin/settlements/:id/approve
+func (h *Handler) AdminApprove(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	st, err := h.svc.Approve(c.Request.Context(), id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// PUT /api/admin/settlements/:id/paid {"paymentRef":"..."}
+func (h *Handler) AdminMarkPaid(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var in struct {
+		PaymentRef string `json:"paymentRef"`
+	}
+	_ = c.ShouldBindJSON(&in)
+	st, err := h.svc.MarkPaid(c.Request.Context(), id, strings.TrimSpace(in.PaymentRef))
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, st)
+}
+
+// DELETE /api/admin/settlements/:id（僅限草稿）
+func (h *Handler) AdminDiscard(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err := h.svc.Discard(c.Request.Context(), id); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GET /api/admin/commission-rates
+func (h *Handler) AdminRates(c *gin.Context) {
+	list, err := h.svc.Rates(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "defaultBp": h.svc.defaultBP})
+}
+
+// PUT /api/admin/commission-rates {"vendorId":"","category":"3C","rateBp":1200}
+func (h *Handler) AdminSetRate(c *gin.Context) {
+	var in struct {
+		VendorID string `json:"vendorId"`
+		Category string `json:"category"`
+		RateBP   *int   `json:"rateBp"`
+	}
+	if err := c.ShouldBindJSON(&in); err != nil || in.RateBP == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+		return
+	}
+	r, err := h.svc.SetRate(c.Request.Context(), strings.TrimSpace(in.VendorID), strings.TrimSpace(in.Category), *in.RateBP)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, r)
+}
+
+// DELETE /api/admin/commission-rates/:id
+func (h *Handler) AdminDeleteRate(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err := h.svc.DeleteRate(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// SendCSV：下載結算單 CSV（後台與廠商共用）
+func SendCSV(c *gin.Context, st *Statement) {
+	name := fmt.Sprintf("settlement-%s-%d.csv", st.PeriodStart.Format("200601"), st.ID)
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+	if err := WriteCSV(c.Writer, st); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+func writeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, ErrInvalidState):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrInvalidRange):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,77 @@
+package settlement
+
+import "time"
+
+// 結算單狀態
+const (
+	StatusDraft    = "draft"    // 系統產生，待審核
+	StatusApproved = "approved" // 已核准，待撥款
+	StatusPaid     = "paid"     // 已撥款
+)
+
+// 結算明細類型
+const (
+	LineSale       = "sale"       // 本期完成的銷售
+	LineAdjustment = "adjustment" // 已結算項目事後退款的扣回
+)
+
+// 抽成比例（萬分比，1000 = 10%）
+// VendorID / Category 空字串代表「全部」；比對時越精準者優先
+type CommissionRate struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	VendorID  string    `gorm:"size:36;uniqueIndex:idx_rate_scope" json:"vendorId"`
+	Category  string    `gorm:"size:50;uniqueIndex:idx_rate_scope" json:"category"`
+	RateBP    int       `gorm:"not null" json:"rateBp"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// 結算單：某廠商某期間的應付金額
+type Statement struct {
+	ID          uint64          `gorm:"primaryKey" json:"id"`
+	VendorID    string          `gorm:"size:36;index;not null" json:"vendorId"`
+	PeriodStart time.Time       `gorm:"index" json:"periodStart"`
+	PeriodEnd   time.Time       `json:"periodEnd"`
+	Gross       int64           `json:"gross"`      // 銷售總額
+	Refunds     int64           `json:"refunds"`    // 退款
+	Commission  int64           `json:"commission"` // 平台抽成
+	Net         int64           `json:"net"`        // 應付廠商 = Gross - Refunds - Commission
+	Status      string          `gorm:"size:16;index;default:draft" json:"status"`
+	ApprovedAt  *time.Time      `json:"approvedAt"`
+	PaidAt      *time.Time      `json:"paidAt"`
+	PaymentRef  string          `gorm:"size:64" json:"paymentRef"` // 匯款單號 / 備註
+	CreatedAt   time.Time       `json:"createdAt"`
+	UpdatedAt   time.Time       `json:"updatedAt"`
+	Lines       []StatementLine `json:"lines,omitempty"`
+}
+
+// ScheduledRun：排程已自動結算過的期間；之後被刪除的草稿不會再自動產生，需由後台手動重新結算
+type ScheduledRun struct {
+	PeriodStart time.Time `gorm:"primaryKey" json:"periodStart"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// GenerateLock：只有一列；Generate 在交易開頭 FOR UPDATE 鎖住它，
+// 多台實例同時結算時會依序執行，後到的看得到前一次寫入的明細，不會重複入帳
+type GenerateLock struct {
+	ID uint8 `gorm:"primaryKey;autoIncrement:false"`
+}
+
+type StatementLine struct {
+	ID          uint64    `gorm:"primaryKey" json:"id"`
+	StatementID uint64    `gorm:"index;not null" json:"statementId"`
+	Kind        string    `gorm:"size:16" json:"kind"`
+	OrderID     uint64    `json:"orderId"`
+	OrderNo     string    `gorm:"size:32" json:"orderNo"`
+	OrderItemID uint64    `gorm:"index" json:"orderItemId"`
+	ProductID   uint64    `json:"productId"`
+	ProductName string    `json:"productName"`
+	Category    string    `gorm:"size:50" json:"category"`
+	Quantity    int       `json:"quantity"`
+	Amount      int64     `json:"amount"`
+	Refund      int64     `json:"refund"`
+	RateBP      int       `json:"rateBp"`
+	Commission  int64     `json:"commission"`
+	Net         int64     `json:"net"`
+	CompletedAt time.Time `json:"completedAt"`
+}
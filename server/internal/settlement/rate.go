@@ -0,0 +1,44 @@
+package settlement
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// rateTable：一次載入全部抽成設定，依「廠商+類別 > 廠商 > 類別 > 全站」取用
+type rateTable struct {
+	rates map[[2]string]int
+	def   int
+}
+
+func loadRates(ctx context.Context, db *gorm.DB, def int) (*rateTable, error) {
+	var rows []CommissionRate
+	if err := db.WithContext(ctx).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	t := &rateTable{rates: map[[2]string]int{}, def: def}
+	for _, r := range rows {
+		t.rates[[2]string{r.VendorID, r.Category}] = r.RateBP
+	}
+	return t, nil
+}
+
+func (t *rateTable) lookup(vendorID, category string) int {
+	for _, k := range [][2]string{
+		{vendorID, category},
+		{vendorID, ""},
+		{"", category},
+		{"", ""},
+	} {
+		if bp, ok := t.rates[k]; ok {
+			return bp
+		}
+	}
+	return t.def
+}
+
+// 抽成金額（四捨五入到元）
+func commission(amount int64, bp int) int64 {
+	return (amount*int64(bp) + 5000) / 10000
+}
@@ -0,0 +1,358 @@
+package settlement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInvalidState = errors.New("statement is not in the required state")
+	ErrInvalidRange = errors.New("invalid period")
+)
+
+type Service struct {
+	db        *gorm.DB
+	defaultBP int
+}
+
+// defaultBP：沒有任何抽成設定時使用的預設比例（萬分比）
+func NewService(db *gorm.DB, defaultBP int) *Service {
+	return &Service{db: db, defaultBP: defaultBP}
+}
+
+// 本期可結算的銷售項目
+type saleRow struct {
+	OrderItemID  uint64
+	OrderID      uint64
+	OrderNo      string
+	ProductID    uint64
+	ProductName  string
+	Category     string
+	VendorID     string
+	Quantity     int
+	Subtotal     int64
+	RefundAmount int64
+	CompletedAt  time.Time
+}
+
+// 已結算、之後又退款的項目
+type refundRow struct {
+	OrderItemID   uint64
+	OrderID       uint64
+	OrderNo       string
+	ProductID     uint64
+	ProductName   string
+	Category      string
+	VendorID      string
+	RefundAmount  int64
+	SettledRefund int64
+	RateBP        int
+}
+
+// Generate：結算 [start, end) 期間。
+// 截至 end 前已完成、尚未結算且未全額退款的廠商訂單項目都會列入（含前期漏網的），
+// 已結算項目的後續退款以 adjustment 明細扣回。
+// 同一廠商同一期已有結算單時略過，留待下一期。
+// 整個過程在同一個交易內、先鎖住 GenerateLock，多台實例同時執行也不會重複入帳
+func (s *Service) Generate(ctx context.Context, start, end time.Time) ([]Statement, error) {
+	if !start.Before(end) {
+		return nil, ErrInvalidRange
+	}
+
+	var out []Statement
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&GenerateLock{ID: 1}).Error; err != nil {
+			return err
+		}
+		var lock GenerateLock
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lock, 1).Error; err != nil {
+			return err
+		}
+
+		rates, err := loadRates(ctx, tx, s.defaultBP)
+		if err != nil {
+			return err
+		}
+
+		var sales []saleRow
+		if err := tx.Table("order_items AS oi").
+			Select(`oi.id AS order_item_id, oi.order_id, o.order_no, oi.product_id, oi.product_name,
+			        COALESCE(p.category, '') AS category, oi.vendor_id, oi.quantity, oi.subtotal,
+			        oi.refund_amount, COALESCE(so.completed_at, so.updated_at) AS completed_at`).
+			Joins("JOIN sub_orders so ON so.id = oi.sub_order_id").
+			Joins("JOIN orders o ON o.id = oi.order_id").
+			Joins("LEFT JOIN products p ON p.id = oi.product_id").
+			Where("oi.vendor_id <> '' AND so.status = ?", "completed").
+			Where("COALESCE(so.completed_at, so.updated_at) < ?", end).
+			Where("oi.status <> ? AND oi.refund_amount < oi.subtotal", "out_of_stock").
+			Where("NOT EXISTS (SELECT 1 FROM statement_lines sl WHERE sl.order_item_id = oi.id AND sl.kind = ?)", LineSale).
+			Order("oi.id ASC").
+			Scan(&sales).Error; err != nil {
+			return err
+		}
+
+		var refunds []refundRow
+		if err := tx.Table("order_items AS oi").
+			Select(`oi.id AS order_item_id, oi.order_id, o.order_no, oi.product_id, oi.product_name,
+			        COALESCE(p.category, '') AS category, oi.vendor_id, oi.refund_amount,
+			        (SELECT COALESCE(SUM(sl.refund), 0) FROM statement_lines sl WHERE sl.order_item_id = oi.id) AS settled_refund,
+			        (SELECT sl.rate_bp FROM statement_lines sl WHERE sl.order_item_id = oi.id AND sl.kind = ? LIMIT 1) AS rate_bp`, LineSale).
+			Joins("JOIN orders o ON o.id = oi.order_id").
+			Joins("LEFT JOIN products p ON p.id = oi.product_id").
+			Where("oi.vendor_id <> '' AND oi.refund_amount > 0 AND oi.refunded_at < ?", end).
+			Where("EXISTS (SELECT 1 FROM statement_lines sl WHERE sl.order_item_id = oi.id AND sl.kind = ?)", LineSale).
+			Order("oi.id ASC").
+			Scan(&refunds).Error; err != nil {
+			return err
+		}
+
+		vendors, lines := buildLines(sales, refunds, rates, end)
+		for _, vid := range vendors {
+			var n int64
+			if err := tx.Model(&Statement{}).
+				Where("vendor_id = ? AND period_start = ?", vid, start).
+				Count(&n).Error; err != nil {
+				return err
+			}
+			if n > 0 {
+				continue
+			}
+			st := newStatement(vid, start, end, lines[vid])
+			if err := tx.Create(&st).Error; err != nil {
+				return err
+			}
+			out = append(out, st)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// buildLines：把銷售與事後退款換算成明細，依廠商彙整（vendors 保留首次出現的順序）
+func buildLines(sales []saleRow, refunds []refundRow, rates *rateTable, end time.Time) ([]string, map[string][]StatementLine) {
+	var vendors []string
+	lines := map[string][]StatementLine{}
+	add := func(vid string, l StatementLine) {
+		if _, ok := lines[vid]; !ok {
+			vendors = append(vendors, vid)
+		}
+		lines[vid] = append(lines[vid], l)
+	}
+	for _, r := range sales {
+		bp := rates.lookup(r.VendorID, r.Category)
+		base := r.Subtotal - r.RefundAmount
+		comm := commission(base, bp)
+		add(r.VendorID, StatementLine{
+			Kind:        LineSale,
+			OrderID:     r.OrderID,
+			OrderNo:     r.OrderNo,
+			OrderItemID: r.OrderItemID,
+			ProductID:   r.ProductID,
+			ProductName: r.ProductName,
+			Category:    r.Category,
+			Quantity:    r.Quantity,
+			Amount:      r.Subtotal,
+			Refund:      r.RefundAmount,
+			RateBP:      bp,
+			Commission:  comm,
+			Net:         base - comm,
+			CompletedAt: r.CompletedAt,
+		})
+	}
+	for _, r := range refunds {
+		delta := r.RefundAmount - r.SettledRefund
+		if delta <= 0 {
+			continue
+		}
+		comm := commission(delta, r.RateBP)
+		add(r.VendorID, StatementLine{
+			Kind:        LineAdjustment,
+			OrderID:     r.OrderID,
+			OrderNo:     r.OrderNo,
+			OrderItemID: r.OrderItemID,
+			ProductID:   r.ProductID,
+			ProductName: r.ProductName,
+			Category:    r.Category,
+			Refund:      delta,
+			RateBP:      r.RateBP,
+			Commission:  -comm, // 退款部分的抽成退還給廠商
+			Net:         -(delta - comm),
+			CompletedAt: end,
+		})
+	}
+	return vendors, lines
+}
+
+func newStatement(vendorID string, start, end time.Time, lines []StatementLine) Statement {
+	st := Statement{VendorID: vendorID, PeriodStart: start, PeriodEnd: end, Status: StatusDraft, Lines: lines}
+	for _, l := range lines {
+		st.Gross += l.Amount
+		st.Refunds += l.Refund
+		st.Commission += l.Commission
+		st.Net += l.Net
+	}
+	return st
+}
+
+// Approve：草稿 → 已核准
+func (s *Service) Approve(ctx context.Context, id uint64) (*Statement, error) {
+	return s.transition(ctx, id, StatusDraft, func(st *Statement, now time.Time) map[string]any {
+		st.Status, st.ApprovedAt = StatusApproved, &now
+		return map[string]any{"status": StatusApproved, "approved_at": now}
+	})
+}
+
+// MarkPaid：已核准 → 已撥款
+func (s *Service) MarkPaid(ctx context.Context, id uint64, ref string) (*Statement, error) {
+	return s.transition(ctx, id, StatusApproved, func(st *Statement, now time.Time) map[string]any {
+		st.Status, st.PaidAt, st.PaymentRef = StatusPaid, &now, ref
+		return map[string]any{"status": StatusPaid, "paid_at": now, "payment_ref": ref}
+	})
+}
+
+// Discard：刪除草稿，其中項目會在下次結算（後台手動產生或下個月排程）重新列入
+func (s *Service) Discard(ctx context.Context, id uint64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var st Statement
+		if err := tx.First(&st, id).Error; err != nil {
+			return err
+		}
+		if st.Status != StatusDraft {
+			return ErrInvalidState
+		}
+		if err := tx.Where("statement_id = ?", id).Delete(&StatementLine{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&st).Error
+	})
+}
+
+func (s *Service) transition(ctx context.Context, id uint64, from string, apply func(*Statement, time.Time) map[string]any) (*Statement, error) {
+	var st Statement
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&st, id).Error; err != nil {
+			return err
+		}
+		if st.Status != from {
+			return ErrInvalidState
+		}
+		return tx.Model(&Statement{ID: id}).Updates(apply(&st, time.Now())).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// List：vendorID 空字串 = 全部廠商；status 空字串 = 全部狀態
+func (s *Service) List(ctx context.Context, vendorID, status string) ([]Statement, error) {
+	q := s.db.WithContext(ctx).Model(&Statement{})
+	if vendorID != "" {
+		q = q.Where("vendor_id = ?", vendorID)
+	}
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	var list []Statement
+	if err := q.Order("period_start DESC, id DESC").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// Get：含明細；vendorID 非空時限定該廠商
+func (s *Service) Get(ctx context.Context, id uint64, vendorID string) (*Statement, error) {
+	q := s.db.WithContext(ctx).Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") })
+	if vendorID != "" {
+		q = q.Where("vendor_id = ?", vendorID)
+	}
+	var st Statement
+	if err := q.First(&st, id).Error; err != nil {
+		return nil, err
+	}
+	return &st, nil
+}
+
+// ---- 抽成設定 ----
+
+func (s *Service) Rates(ctx context.Context) ([]CommissionRate, error) {
+	var list []CommissionRate
+	if err := s.db.WithContext(ctx).Order("vendor_id, category").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// SetRate：同一組（廠商, 類別）只會有一筆，存在則覆寫
+func (s *Service) SetRate(ctx context.Context, vendorID, category string, bp int) (*CommissionRate, error) {
+	if bp < 0 || bp > 10000 {
+		return nil, fmt.Errorf("rateBp must be between 0 and 10000")
+	}
+	var r CommissionRate
+	err := s.db.WithContext(ctx).
+		Where(CommissionRate{VendorID: vendorID, Category: category}).
+		Assign(CommissionRate{RateBP: bp}).
+		FirstOrCreate(&r).Error
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+func (s *Service) DeleteRate(ctx context.Context, id uint64) error {
+	return s.db.WithContext(ctx).Delete(&CommissionRate{}, id).Error
+}
+
+// ---- 排程 ----
+
+// RunMonthly：背景定期檢查，替「上個月」產生結算單。
+// 每一期只自動產生一次（記在 ScheduledRun），後台刪除的草稿不會在下一輪又冒出來
+func (s *Service) RunMonthly(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		start, end := PreviousMonth(time.Now())
+		if err := s.runScheduled(ctx, start, end); err != nil {
+			log.Printf("settlement: generate %s: %v", start.Format("2006-01"), err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (s *Service) runScheduled(ctx context.Context, start, end time.Time) error {
+	db := s.db.WithContext(ctx)
+	var n int64
+	if err := db.Model(&ScheduledRun{}).Where("period_start = ?", start).Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return nil
+	}
+	list, err := s.Generate(ctx, start, end)
+	if err != nil {
+		return err
+	}
+	if len(list) > 0 {
+		log.Printf("settlement: %d statements generated for %s", len(list), start.Format("2006-01"))
+	}
+	return db.Create(&ScheduledRun{PeriodStart: start}).Error
+}
+
+// PreviousMonth：回傳上個月的 [月初, 本月初)
+func PreviousMonth(now time.Time) (time.Time, time.Time) {
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return end.AddDate(0, -1, 0), end
+}
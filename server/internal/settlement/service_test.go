@@ -0,0 +1,90 @@
+package settlement
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCommission(t *testing.T) {
+	cases := []struct {
+		amount int64
+		bp     int
+		want   int64
+	}{
+		{0, 1000, 0},
+		{1000, 0, 0},
+		{1000, 1000, 100},
+		{14, 1000, 1},     // 1.4 → 1
+		{15, 1000, 2},     // 1.5 → 2（四捨五入）
+		{12345, 250, 309}, // 308.625 → 309
+		{999, 333, 33},    // 33.2667 → 33
+		{1000, 10000, 1000},
+	}
+	for _, tc := range cases {
+		if got := commission(tc.amount, tc.bp); got != tc.want {
+			t.Errorf("commission(%d, %d) = %d, want %d", tc.amount, tc.bp, got, tc.want)
+		}
+	}
+}
+
+func TestRateLookup(t *testing.T) {
+	rates := &rateTable{def: 1000, rates: map[[2]string]int{
+		{"v1", "3C"}: 500,
+		{"v1", ""}:   600,
+		{"", "3C"}:   700,
+	}}
+	cases := []struct {
+		vendor, category string
+		want             int
+	}{
+		{"v1", "3C", 500},
+		{"v1", "home", 600},
+		{"v2", "3C", 700},
+		{"v2", "home", 1000},
+	}
+	for _, tc := range cases {
+		if got := rates.lookup(tc.vendor, tc.category); got != tc.want {
+			t.Errorf("lookup(%q, %q) = %d, want %d", tc.vendor, tc.category, got, tc.want)
+		}
+	}
+}
+
+func TestBuildLines(t *testing.T) {
+	end := time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC)
+	rates := &rateTable{def: 1000, rates: map[[2]string]int{{"v2", ""}: 500}}
+	sales := []saleRow{
+		{OrderItemID: 1, VendorID: "v1", Quantity: 2, Subtotal: 1000},
+		{OrderItemID: 2, VendorID: "v2", Quantity: 1, Subtotal: 2000, RefundAmount: 500},
+		{OrderItemID: 3, VendorID: "v1", Quantity: 1, Subtotal: 15},
+	}
+	refunds := []refundRow{
+		{OrderItemID: 9, VendorID: "v1", RefundAmount: 300, SettledRefund: 100, RateBP: 800},
+		{OrderItemID: 8, VendorID: "v2", RefundAmount: 200, SettledRefund: 200, RateBP: 500}, // 已全數扣回
+	}
+
+	vendors, lines := buildLines(sales, refunds, rates, end)
+	if len(vendors) != 2 || vendors[0] != "v1" || vendors[1] != "v2" {
+		t.Fatalf("vendors = %v", vendors)
+	}
+	if len(lines["v1"]) != 3 || len(lines["v2"]) != 1 {
+		t.Fatalf("lines v1 = %d, v2 = %d", len(lines["v1"]), len(lines["v2"]))
+	}
+
+	// 部分退款：抽成以扣掉退款後的金額計算
+	if l := lines["v2"][0]; l.RateBP != 500 || l.Commission != 75 || l.Net != 1425 {
+		t.Errorf("v2 sale = %+v", l)
+	}
+	// 事後退款：只扣本期新增的 200，並退還其抽成 16
+	adj := lines["v1"][2]
+	if adj.Kind != LineAdjustment || adj.Refund != 200 || adj.Commission != -16 || adj.Net != -184 || !adj.CompletedAt.Equal(end) {
+		t.Errorf("v1 adjustment = %+v", adj)
+	}
+
+	st := newStatement("v1", end.AddDate(0, -1, 0), end, lines["v1"])
+	if st.Gross != 1015 || st.Refunds != 200 || st.Commission != 100+2-16 || st.Net != 900+13-184 {
+		t.Errorf("statement = gross %d refunds %d commission %d net %d", st.Gross, st.Refunds, st.Commission, st.Net)
+	}
+	if st.Gross-st.Refunds-st.Commission != st.Net {
+		t.Errorf("net %d != gross - refunds - commission", st.Net)
+	}
+}
@@ -0,0 +1,144 @@
+package totp
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/secretbox"
+)
+
+const (
+	maxFailures = 5
+	lockFor     = 15 * time.Minute
+)
+
+var (
+	ErrAlreadyEnabled = errors.New("two-factor authentication already enabled")
+	ErrNotEnabled     = errors.New("two-factor authentication not enabled")
+	ErrNotSetup       = errors.New("two-factor setup not started")
+	ErrInvalidCode    = errors.New("invalid verification code")
+	ErrLocked         = errors.New("too many invalid codes, try again later")
+)
+
+// Factor：帳號的 TOTP 設定。嵌入時須使用 gorm:"embedded;embeddedPrefix:totp_"（欄位名稱見 Columns）
+type Factor struct {
+	Secret        string `gorm:"size:128"` // secretbox 加密；EnabledAt 為 nil 時代表綁定中
+	EnabledAt     *time.Time
+	LastStep      int64  // 最後一次使用的時間步（防止同一組碼重用）
+	RecoveryCodes string `gorm:"type:text"` // 備用碼 SHA-256 雜湊（JSON 陣列）
+	Failures      int    // 連續輸入錯誤次數
+	LockedUntil   *time.Time
+}
+
+func (f *Factor) Enabled() bool { return f.EnabledAt != nil }
+
+// MarshalJSON：對外只揭露是否啟用與剩餘備用碼數量
+func (f Factor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{
+		"enabled":           f.Enabled(),
+		"recoveryCodesLeft": RecoveryCodesLeft(f.RecoveryCodes),
+	})
+}
+
+// Columns：寫回資料庫用（embeddedPrefix:totp_）
+func (f *Factor) Columns() map[string]any {
+	return map[string]any{
+		"totp_secret":         f.Secret,
+		"totp_enabled_at":     f.EnabledAt,
+		"totp_last_step":      f.LastStep,
+		"totp_recovery_codes": f.RecoveryCodes,
+		"totp_failures":       f.Failures,
+		"totp_locked_until":   f.LockedUntil,
+	}
+}
+
+// Setup：產生新的金鑰（尚未啟用），回傳明碼金鑰與 otpauth URI
+func (f *Factor) Setup(box *secretbox.Box, issuer, account string) (string, string, error) {
+	if f.Enabled() {
+		return "", "", ErrAlreadyEnabled
+	}
+	secret, err := GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	sealed, err := box.Seal(secret)
+	if err != nil {
+		return "", "", err
+	}
+	f.Secret, f.LastStep, f.Failures, f.LockedUntil = sealed, 0, 0, nil
+	return secret, URI(issuer, account, secret), nil
+}
+
+// Enable：以 App 顯示的驗證碼確認綁定，成功後回傳備用碼明碼（只顯示這一次）
+func (f *Factor) Enable(box *secretbox.Box, code string, now time.Time) ([]string, error) {
+	if f.Enabled() {
+		return nil, ErrAlreadyEnabled
+	}
+	if f.Secret == "" {
+		return nil, ErrNotSetup
+	}
+	secret, err := box.Open(f.Secret)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := Verify(secret, code, now, 0)
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+	codes, hashed, err := NewRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	f.EnabledAt, f.LastStep, f.RecoveryCodes, f.Failures, f.LockedUntil = &now, s, hashed, 0, nil
+	return codes, nil
+}
+
+// Check：登入第二步，接受 6 位數驗證碼或備用碼。
+// 不論成功與否都會改變 Factor（錯誤次數 / 已用時間步），呼叫端須寫回 Columns
+func (f *Factor) Check(box *secretbox.Box, code string, now time.Time) error {
+	if !f.Enabled() {
+		return ErrNotEnabled
+	}
+	if f.LockedUntil != nil && now.Before(*f.LockedUntil) {
+		return ErrLocked
+	}
+	ok := false
+	if secret, err := box.Open(f.Secret); err == nil {
+		if s, match := Verify(secret, code, now, f.LastStep); match {
+			f.LastStep, ok = s, true
+		}
+	}
+	if !ok {
+		if rest, used := UseRecoveryCode(f.RecoveryCodes, code); used {
+			f.RecoveryCodes, ok = rest, true
+		}
+	}
+	if ok {
+		f.Failures, f.LockedUntil = 0, nil
+		return nil
+	}
+	f.Failures++
+	if f.Failures >= maxFailures {
+		until := now.Add(lockFor)
+		f.Failures, f.LockedUntil = 0, &until
+		return ErrLocked
+	}
+	return ErrInvalidCode
+}
+
+// RegenerateRecovery：重新產生備用碼（舊的全部失效）
+func (f *Factor) RegenerateRecovery() ([]string, error) {
+	if !f.Enabled() {
+		return nil, ErrNotEnabled
+	}
+	codes, hashed, err := NewRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	f.RecoveryCodes = hashed
+	return codes, nil
+}
+
+// Disable：關閉並清除所有設定
+func (f *Factor) Disable() { *f = Factor{} }
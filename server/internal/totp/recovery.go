@@ -0,0 +1,75 @@
+package totp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+)
+
+// RecoveryCodeCount：每次產生的備用碼數量（每組只能用一次）
+const RecoveryCodeCount = 10
+
+const recoveryAlphabet = "abcdefghjkmnpqrstuvwxyz23456789" // 去掉易混淆的 i l o 0 1
+
+// NewRecoveryCodes：產生備用碼（xxxxx-xxxxx），回傳明碼（只顯示一次）與要儲存的雜湊清單
+func NewRecoveryCodes() ([]string, string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	hashes := make([]string, RecoveryCodeCount)
+	buf := make([]byte, 10)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, "", err
+		}
+		var sb strings.Builder
+		for j, b := range buf {
+			if j == 5 {
+				sb.WriteByte('-')
+			}
+			sb.WriteByte(recoveryAlphabet[int(b)%len(recoveryAlphabet)])
+		}
+		codes[i] = sb.String()
+		hashes[i] = hashRecovery(codes[i])
+	}
+	raw, err := json.Marshal(hashes)
+	return codes, string(raw), err
+}
+
+func normalizeRecovery(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+}
+
+func hashRecovery(code string) string {
+	sum := sha256.Sum256([]byte(normalizeRecovery(code)))
+	return hex.EncodeToString(sum[:])
+}
+
+func parseHashes(stored string) []string {
+	var hashes []string
+	if stored != "" {
+		_ = json.Unmarshal([]byte(stored), &hashes)
+	}
+	return hashes
+}
+
+// UseRecoveryCode：比對成功則移除該組，回傳剩下的雜湊清單
+func UseRecoveryCode(stored, code string) (string, bool) {
+	if normalizeRecovery(code) == "" {
+		return stored, false
+	}
+	h := hashRecovery(code)
+	hashes := parseHashes(stored)
+	for i, x := range hashes {
+		if subtle.ConstantTimeCompare([]byte(x), []byte(h)) == 1 {
+			rest := append(hashes[:i:i], hashes[i+1:]...)
+			raw, _ := json.Marshal(rest)
+			return string(raw), true
+		}
+	}
+	return stored, false
+}
+
+// RecoveryCodesLeft：剩餘可用的備用碼數量
+func RecoveryCodesLeft(stored string) int { return len(parseHashes(stored)) }
@@ -0,0 +1,12 @@
+// Package tz：全站共用的時區。報表切日 / 切月、日期參數解析一律以台北時間為準，不受主機時區影響
+package tz
+
+import "time"
+
+// Taipei：Asia/Taipei；容器沒有 tzdata 時退回固定 UTC+8（台灣無夏令時間）
+var Taipei = func() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Taipei"); err == nil {
+		return loc
+	}
+	return time.FixedZone("CST", 8*60*60)
+}()
@@ -0,0 +1,206 @@
+// Package auth 廠商登入憑證：簽發 / 驗證 JWT（HS256、固定 issuer / audience、必須有效期），
+// 提供所有廠商 API 共用的 middleware 與目前廠商的存取函式。
+// 登入後發短效 access token（cookie vtoken）與可輪替的 refresh token（cookie vrefresh），
+// 每個登入裝置對應一筆 VendorSession，可個別或全部登出
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/cache"
+)
+
+const (
+	CookieName        = "vtoken"
+	RefreshCookieName = "vrefresh"
+	issuer            = "zeusshop"
+	audience          = "vendor"
+	accessTTL         = 15 * time.Minute
+	refreshTTL        = 30 * 24 * time.Hour
+	refreshPath       = "/api/vendor" // refresh token 只送往廠商 API（/auth/refresh、/logout）
+	ctxKey            = "vendor"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Claims：廠商 JWT 內容（Subject = 廠商 ID、sid = VendorSession.ID）；
+// 員工登入時另帶 stf（VendorStaff.ID）與權限清單
+type Claims struct {
+	Email       string   `json:"email"`
+	SessionID   string   `json:"sid"`
+	StaffID     string   `json:"stf,omitempty"`
+	Permissions []string `json:"perms,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Vendor：目前請求的登入者。ID 一律是廠商 ID（資料以此篩選），員工另有 StaffID，
+// 以 API 金鑰存取時另有 APIKeyID（沒有 session）
+type Vendor struct {
+	ID          string
+	Email       string
+	SessionID   string
+	StaffID     string   // 空 = 廠商本人
+	APIKeyID    string   // 非空 = API 金鑰
+	Permissions []string // 員工的權限 / 金鑰的 scopes；本人不使用
+}
+
+// Owner：是否為廠商本人（員工與 API 金鑰都不是）
+func (v *Vendor) Owner() bool { return v.StaffID == "" && v.APIKeyID == "" }
+
+// Can：本人擁有全部權限；員工與 API 金鑰依指派的權限
+func (v *Vendor) Can(perm string) bool {
+	if v.Owner() {
+		return true
+	}
+	for _, p := range v.Permissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+type Auth struct {
+	secret []byte
+	secure bool // Cookie 是否加 Secure（正式環境 https）
+	db     *gorm.DB
+	rdb    *cache.Redis // 已登出 session 的黑名單（access token 到期前擋下）
+}
+
+func New(secret string, secure bool, db *gorm.DB, rdb *cache.Redis) *Auth {
+	return &Auth{secret: []byte(secret), secure: secure, db: db, rdb: rdb}
+}
+
+// issueAccess：簽發短效 access token 並寫入 HttpOnly cookie
+func (a *Auth) issueAccess(c *gin.Context, v *Vendor) (string, error) {
+	now := time.Now()
+	claims := Claims{
+		Email:       v.Email,
+		SessionID:   v.SessionID,
+		StaffID:     v.StaffID,
+		Permissions: v.Permissions,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{audience},
+			Subject:   v.ID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(accessTTL)),
+		},
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(a.secret)
+	if err != nil {
+		return "", err
+	}
+	a.setCookie(c, CookieName, signed, "/", accessTTL)
+	c.Writer.Header().Add("Vary", "Cookie")
+	return signed, nil
+}
+
+// setCookie：本地非 https → Secure=false；SameSite=Lax；ttl <= 0 代表刪除
+func (a *Auth) setCookie(c *gin.Context, name, value, path string, ttl time.Duration) {
+	maxAge := int(ttl.Seconds())
+	if ttl <= 0 {
+		maxAge = -1
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   a.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// ClearCookie：登出時讓 access / refresh cookie 立即失效
+func (a *Auth) ClearCookie(c *gin.Context) {
+	a.setCookie(c, CookieName, "", "/", 0)
+	a.setCookie(c, RefreshCookieName, "", refreshPath, 0)
+}
+
+// Verify：只接受 HS256，並檢查 issuer / audience / 有效期
+func (a *Auth) Verify(token string) (*Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return a.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.Subject == "" || claims.SessionID == "" {
+		return nil, ErrInvalidToken
+	}
+	return &claims, nil
+}
+
+// tokenFrom：優先讀 cookie，其次 Authorization: Bearer（API 金鑰見 MiddlewareWithAPIKey）
+func tokenFrom(c *gin.Context) string {
+	if t, err := c.Cookie(CookieName); err == nil && t != "" {
+		return t
+	}
+	if h := c.GetHeader("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		return strings.TrimPrefix(h, "Bearer ")
+	}
+	return ""
+}
+
+// Middleware：所有需要廠商登入的路由共用
+func (a *Auth) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := tokenFrom(c)
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "UNAUTHENTICATED"})
+			return
+		}
+		claims, err := a.Verify(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "INVALID_TOKEN"})
+			return
+		}
+		revoked, err := a.revoked(c.Request.Context(), claims.SessionID)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"ok": false, "error": "SESSION_CHECK_UNAVAILABLE"})
+			return
+		}
+		if revoked {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "INVALID_TOKEN"})
+			return
+		}
+		c.Set(ctxKey, &Vendor{
+			ID:          claims.Subject,
+			Email:       claims.Email,
+			SessionID:   claims.SessionID,
+			StaffID:     claims.StaffID,
+			Permissions: claims.Permissions,
+		})
+		c.Next()
+	}
+}
+
+// Current：目前登入的廠商（未經 Middleware 時為 nil）
+func Current(c *gin.Context) *Vendor {
+	if v, ok := c.Get(ctxKey); ok {
+		if vd, ok := v.(*Vendor); ok {
+			return vd
+		}
+	}
+	return nil
+}
+
+// VendorID：目前登入廠商的 ID（未登入為空字串）
+func VendorID(c *gin.Context) string {
+	if v := Current(c); v != nil {
+		return v.ID
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	challengeAudience = "vendor-2fa"
+	challengeTTL      = 5 * time.Minute
+)
+
+// challengeClaims：Subject = 廠商 ID；員工登入另帶 stf
+type challengeClaims struct {
+	StaffID string `json:"stf,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// IssueChallenge：密碼正確但已啟用雙因素驗證時發出的短效憑證，只能拿來換第二步登入
+func (a *Auth) IssueChallenge(vendorID, staffID string) (string, error) {
+	now := time.Now()
+	claims := challengeClaims{
+		StaffID: staffID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Audience:  jwt.ClaimStrings{challengeAudience},
+			Subject:   vendorID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(challengeTTL)),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(a.secret)
+}
+
+// VerifyChallenge：回傳 challenge 對應的廠商 ID 與員工 ID（本人為空）
+func (a *Auth) VerifyChallenge(token string) (string, string, error) {
+	var claims challengeClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return a.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(issuer),
+		jwt.WithAudience(challengeAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.Subject == "" {
+		return "", "", ErrInvalidToken
+	}
+	return claims.Subject, claims.StaffID, nil
+}
@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/cache"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
+
+// 登入防暴力破解
+const (
+	failWindow  = 15 * time.Minute // 失敗次數的計算區間（每次失敗重新起算）
+	delayAfter  = 3                // 同帳號失敗幾次後開始延遲
+	maxDelay    = 30 * time.Second // 延遲上限
+	lockAfter   = 10               // 同帳號失敗幾次後鎖定
+	LockTTL     = 30 * time.Minute // 鎖定時間（解鎖信連結同樣有效期）
+	ipFailLimit = 50               // 同 IP 在 failWindow 內的失敗上限（撞庫多半換帳號不換 IP）
+)
+
+var (
+	ErrLoginLocked    = errors.New("account temporarily locked")
+	ErrLoginThrottled = errors.New("too many login attempts")
+	ErrUnlockInvalid  = errors.New("invalid or expired unlock token")
+)
+
+// LoginGuard：以 Redis 計算同帳號 / 同 IP 的登入失敗次數，
+// 依次數要求等待（漸進延遲）或暫時鎖定帳號，並把失敗寫入 VendorLoginAudit
+type LoginGuard struct {
+	db  *gorm.DB
+	rdb *cache.Redis // nil = 不限制（仍會寫入稽核紀錄）
+}
+
+func NewLoginGuard(db *gorm.DB, rdb *cache.Redis) *LoginGuard {
+	return &LoginGuard{db: db, rdb: rdb}
+}
+
+// Attempt：一次登入嘗試的來源
+type Attempt struct {
+	Email     string
+	IP        string
+	UserAgent string
+	VendorID  string // 帳號存在時填入
+}
+
+func normalizeEmail(e string) string { return strings.ToLower(strings.TrimSpace(e)) }
+
+func failKey(email string) string   { return "vendor:login:fail:email:" + normalizeEmail(email) }
+func ipFailKey(ip string) string    { return "vendor:login:fail:ip:" + ip }
+func waitKey(email string) string   { return "vendor:login:wait:" + normalizeEmail(email) }
+func lockKey(email string) string   { return "vendor:login:lock:" + normalizeEmail(email) }
+func unlockKey(token string) string { return "vendor:login:unlock:" + hashToken(token) }
+
+// Check：比對密碼前呼叫；被鎖定或延遲期間回傳錯誤與需等待的時間（不耗費 bcrypt）
+func (g *LoginGuard) Check(ctx context.Context, a Attempt) (time.Duration, error) {
+	if g.rdb == nil {
+		return 0, nil
+	}
+	pipe := g.rdb.Pipeline()
+	ipN := pipe.Get(ctx, ipFailKey(a.IP))
+	ipTTL := pipe.PTTL(ctx, ipFailKey(a.IP))
+	lockTTL := pipe.PTTL(ctx, lockKey(a.Email))
+	waitTTL := pipe.PTTL(ctx, waitKey(a.Email))
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		// Redis 故障時放行，避免所有廠商都無法登入
+		log.Printf("vendor login guard: %v", err)
+		return 0, nil
+	}
+	var (
+		reason string
+		wait   time.Duration
+		err    error
+	)
+	switch {
+	case lockTTL.Val() > 0:
+		reason, wait, err = models.LoginLocked, lockTTL.Val(), ErrLoginLocked
+	case waitTTL.Val() > 0:
+		reason, wait, err = models.LoginThrottled, waitTTL.Val(), ErrLoginThrottled
+	default:
+		if n, _ := ipN.Int(); n >= ipFailLimit {
+			reason, wait, err = models.LoginThrottled, ipTTL.Val(), ErrLoginThrottled
+		}
+	}
+	if err != nil {
+		g.audit(ctx, a, reason)
+	}
+	return wait, err
+}
+
+// Fail：記錄一次失敗（密碼或驗證碼錯誤）。
+// 達到鎖定門檻且帳號存在時回傳解鎖 token（呼叫端寄送解鎖信）
+func (g *LoginGuard) Fail(ctx context.Context, a Attempt, reason string) (unlockToken string) {
+	if g.rdb == nil {
+		g.audit(ctx, a, reason)
+		return ""
+	}
+	pipe := g.rdb.TxPipeline()
+	n := pipe.Incr(ctx, failKey(a.Email))
+	pipe.Expire(ctx, failKey(a.Email), failWindow)
+	pipe.Incr(ctx, ipFailKey(a.IP))
+	pipe.Expire(ctx, ipFailKey(a.IP), failWindow)
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("vendor login guard: %v", err)
+		g.audit(ctx, a, reason)
+		return ""
+	}
+	g.audit(ctx, a, reason)
+
+	count := int(n.Val())
+	switch {
+	case count >= lockAfter:
+		// 帳號不存在也照樣鎖定，回應才不會洩漏帳號是否存在
+		pipe := g.rdb.TxPipeline()
+		pipe.Set(ctx, lockKey(a.Email), 1, LockTTL)
+		pipe.Del(ctx, failKey(a.Email), waitKey(a.Email))
+		if a.VendorID != "" {
+			unlockToken = newToken()
+			pipe.Set(ctx, unlockKey(unlockToken), normalizeEmail(a.Email), LockTTL)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			log.Printf("vendor login guard: %v", err)
+			return ""
+		}
+		g.audit(ctx, a, models.LoginLockedOut)
+	case count >= delayAfter:
+		// 第 3 次起 1s、2s、4s…，上限 maxDelay
+		d := time.Second << (count - delayAfter)
+		if d > maxDelay {
+			d = maxDelay
+		}
+		_ = g.rdb.Set(ctx, waitKey(a.Email), 1, d).Err()
+	}
+	return unlockToken
+}
+
+// Succeed：登入成功後清除該帳號的失敗次數（IP 計數保留到自然過期）
+func (g *LoginGuard) Succeed(ctx context.Context, email string) {
+	if g.rdb == nil {
+		return
+	}
+	_ = g.rdb.Del(ctx, failKey(email), waitKey(email)).Err()
+}
+
+// Clear：解除鎖定與失敗次數（解鎖信、後台手動解鎖、重設密碼）
+func (g *LoginGuard) Clear(ctx context.Context, email string) error {
+	if g.rdb == nil {
+		return nil
+	}
+	return g.rdb.Del(ctx, failKey(email), waitKey(email), lockKey(email)).Err()
+}
+
+// Unlock：以解鎖信的 token 解除鎖定（token 只能用一次）
+func (g *LoginGuard) Unlock(ctx context.Context, token string) error {
+	if g.rdb == nil || token == "" {
+		return ErrUnlockInvalid
+	}
+	email, err := g.rdb.GetDel(ctx, unlockKey(token)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return ErrUnlockInvalid
+		}
+		return err
+	}
+	return g.Clear(ctx, email)
+}
+
+// Locked：帳號目前是否鎖定中（後台顯示用）
+func (g *LoginGuard) Locked(ctx context.Context, email string) bool {
+	if g.rdb == nil {
+		return false
+	}
+	n, err := g.rdb.Exists(ctx, lockKey(email)).Result()
+	return err == nil && n > 0
+}
+
+// Record：只寫稽核紀錄、不計入失敗次數（例如雙因素驗證碼錯誤，已有自己的鎖定機制）
+func (g *LoginGuard) Record(ctx context.Context, a Attempt, reason string) { g.audit(ctx, a, reason) }
+
+func (g *LoginGuard) audit(ctx context.Context, a Attempt, reason string) {
+	entry := models.VendorLoginAudit{
+		VendorID:  a.VendorID,
+		Email:     truncate(normalizeEmail(a.Email), 191),
+		IP:        truncate(a.IP, 64),
+		UserAgent: truncate(a.UserAgent, 255),
+		Reason:    reason,
+	}
+	if err := g.db.WithContext(ctx).Create(&entry).Error; err != nil {
+		log.Printf("vendor login audit: %v", err)
+	}
+}
+
+// AuditQuery：後台查詢條件（空值 = 不篩選）
+type AuditQuery struct {
+	Email    string
+	IP       string
+	VendorID string
+	Limit    int
+	Offset   int
+}
+
+// Audits：登入失敗紀錄（新到舊）
+func (g *LoginGuard) Audits(ctx context.Context, q AuditQuery) ([]models.VendorLoginAudit, int64, error) {
+	db := g.db.WithContext(ctx).Model(&models.VendorLoginAudit{})
+	if q.Email != "" {
+		db = db.Where("email = ?", normalizeEmail(q.Email))
+	}
+	if q.IP != "" {
+		db = db.Where("ip = ?", q.IP)
+	}
+	if q.VendorID != "" {
+		db = db.Where("vendor_id = ?", q.VendorID)
+	}
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []models.VendorLoginAudit
+	err := db.Order("id DESC").Limit(q.Limit).Offset(q.Offset).Find(&list).Error
+	return list, total, err
+}
+
+func newToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+var (
+	dummyOnce sync.Once
+	dummyHash []byte
+)
+
+// ComparePassword：比對密碼；帳號不存在（hash 為空）時仍比對一組假雜湊，
+// 讓回應時間一致，無法藉此判斷帳號是否存在
+func ComparePassword(hash, password string) bool {
+	if hash == "" {
+		dummyOnce.Do(func() {
+			dummyHash, _ = bcrypt.GenerateFromPassword([]byte("zeusshop-dummy-password"), 12)
+		})
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
@@ -0,0 +1,15 @@
+package auth
+
+import "time"
+
+// InviteTTL：員工邀請連結有效時間
+const InviteTTL = 7 * 24 * time.Hour
+
+// NewInviteToken：員工邀請連結用的 token 與要儲存的雜湊
+func NewInviteToken() (token, hash string) {
+	token = newToken()
+	return token, hashToken(token)
+}
+
+// InviteHash：以連結上的 token 查詢邀請
+func InviteHash(token string) string { return hashToken(token) }
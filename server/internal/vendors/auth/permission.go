@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Require：須接在 Middleware 之後；員工需具備其中任一權限（廠商本人一律通過）
+func Require(perms ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v := Current(c)
+		if v == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "UNAUTHENTICATED"})
+			return
+		}
+		for _, p := range perms {
+			if v.Can(p) {
+				c.Next()
+				return
+			}
+		}
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"ok": false, "error": "FORBIDDEN", "permissions": perms})
+	}
+}
+
+// OwnerOnly：只有廠商本人能用（員工管理、商業資料 / 撥款帳戶）
+func OwnerOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		v := Current(c)
+		if v == nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "UNAUTHENTICATED"})
+			return
+		}
+		if !v.Owner() {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"ok": false, "error": "OWNER_ONLY"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
+
+// ResetTTL：重設密碼連結有效時間
+const ResetTTL = 30 * time.Minute
+
+var (
+	ErrResetInvalid = errors.New("invalid reset token")
+	ErrResetExpired = errors.New("reset token expired")
+)
+
+// IssueResetToken：產生重設密碼 token（明碼只出現在信件連結裡），
+// 同一廠商先前的 token 一併作廢
+func (a *Auth) IssueResetToken(ctx context.Context, vendorID string) (string, error) {
+	token := newToken()
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("vendor_id = ?", vendorID).Delete(&models.VendorPasswordReset{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.VendorPasswordReset{
+			ID:        uuid.NewString(),
+			VendorID:  vendorID,
+			TokenHash: hashToken(token),
+			ExpiresAt: time.Now().Add(ResetTTL),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// ResetPassword：以 token 設定新密碼，回傳廠商。token 只能用一次；
+// 成功後該廠商所有重設 token 作廢、所有裝置登出
+func (a *Auth) ResetPassword(ctx context.Context, token, password string) (*models.Vendor, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), 12)
+	if err != nil {
+		return nil, err
+	}
+	var v models.Vendor
+	err = a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var pr models.VendorPasswordReset
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&pr, "token_hash = ?", hashToken(token)).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrResetInvalid
+			}
+			return err
+		}
+		if time.Now().After(pr.ExpiresAt) {
+			return ErrResetExpired // 由 RunResetCleanup 清除
+		}
+		if err := tx.First(&v, "id = ?", pr.VendorID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrResetInvalid
+			}
+			return err
+		}
+		if err := tx.Model(&v).Update("password_hash", string(hash)).Error; err != nil {
+			return err
+		}
+		return tx.Where("vendor_id = ?", v.ID).Delete(&models.VendorPasswordReset{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := a.RevokeAll(ctx, v.ID, "", ""); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// ClearResetTokens：密碼已變更，尚未使用的重設連結全部作廢
+func (a *Auth) ClearResetTokens(ctx context.Context, vendorID string) error {
+	return a.db.WithContext(ctx).Where("vendor_id = ?", vendorID).Delete(&models.VendorPasswordReset{}).Error
+}
+
+// RunResetCleanup：背景定期刪除過期的重設 token（阻塞到 ctx 結束）
+func (a *Auth) RunResetCleanup(ctx context.Context, every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		res := a.db.WithContext(ctx).Where("expires_at < ?", time.Now()).Delete(&models.VendorPasswordReset{})
+		if res.Error != nil {
+			log.Printf("vendor reset cleanup: %v", res.Error)
+		} else if res.RowsAffected > 0 {
+			log.Printf("vendor reset cleanup: %d expired tokens removed", res.RowsAffected)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
+
+var (
+	ErrRefreshInvalid  = errors.New("invalid refresh token")
+	ErrRefreshReused   = errors.New("refresh token reused")
+	ErrRefreshRace     = errors.New("refresh token already rotated")
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+// reuseGrace：同一把舊 refresh token 在輪替後短時間內再出現（多分頁同時刷新），
+// 回 ErrRefreshRace 但不作廢，cookie 已由先完成的分頁換成新的
+const reuseGrace = 30 * time.Second
+
+func revokedKey(sid string) string { return "vendor:session:revoked:" + sid }
+
+func hashToken(t string) string {
+	sum := sha256.Sum256([]byte(t))
+	return hex.EncodeToString(sum[:])
+}
+
+func newRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Login：建立新的登入 session，寫入 access / refresh cookie，回傳 access token。
+// v.ID 為廠商 ID；員工登入時帶 StaffID 與 Permissions
+func (a *Auth) Login(c *gin.Context, v *Vendor) (string, error) {
+	refresh, err := newRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	s := &models.VendorSession{
+		ID:          uuid.NewString(),
+		VendorID:    v.ID,
+		StaffID:     v.StaffID,
+		RefreshHash: hashToken(refresh),
+		UserAgent:   truncate(c.Request.UserAgent(), 255),
+		IP:          c.ClientIP(),
+		CreatedAt:   now,
+		LastUsedAt:  now,
+		ExpiresAt:   now.Add(refreshTTL),
+	}
+	if err := a.db.WithContext(c.Request.Context()).Create(s).Error; err != nil {
+		return "", err
+	}
+	a.setCookie(c, RefreshCookieName, refresh, refreshPath, refreshTTL)
+	login := *v
+	login.SessionID = s.ID
+	return a.issueAccess(c, &login)
+}
+
+// Refresh：以 refresh cookie 換發新的 access token，並輪替 refresh token。
+// 已輪替掉的舊 token 再被使用（超過 reuseGrace）視為外洩，整個 session 作廢
+func (a *Auth) Refresh(c *gin.Context) (*Vendor, error) {
+	token, _ := c.Cookie(RefreshCookieName)
+	if token == "" {
+		return nil, ErrRefreshInvalid
+	}
+	h := hashToken(token)
+	ctx := c.Request.Context()
+	now := time.Now()
+
+	var (
+		vendor  *Vendor
+		refresh string
+		reused  string
+	)
+	err := a.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var s models.VendorSession
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("refresh_hash = ?", h).First(&s).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("prev_refresh_hash = ?", h).First(&s).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return ErrRefreshInvalid
+				}
+				return err
+			}
+			if s.RevokedAt != nil {
+				return ErrRefreshInvalid
+			}
+			if now.Sub(s.LastUsedAt) < reuseGrace {
+				return ErrRefreshRace
+			}
+			if err := tx.Model(&s).Update("revoked_at", now).Error; err != nil {
+				return err
+			}
+			reused = s.ID
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if s.RevokedAt != nil || now.After(s.ExpiresAt) {
+			return ErrRefreshInvalid
+		}
+
+		// 停權 / 停用的廠商不再換發
+		var v models.Vendor
+		if err := tx.Select("id,email,is_active,status").First(&v, "id = ?", s.VendorID).Error; err != nil {
+			return ErrRefreshInvalid
+		}
+		if !v.IsActive || v.Status == models.StatusSuspended {
+			return ErrRefreshInvalid
+		}
+		vendor = &Vendor{ID: v.ID, Email: v.Email, SessionID: s.ID}
+		// 員工：重新讀取權限（停用或已移除則不再換發）
+		if s.StaffID != "" {
+			var st models.VendorStaff
+			if err := tx.Select("id,vendor_id,email,permissions,is_active,accepted_at,password_hash").
+				First(&st, "id = ? AND vendor_id = ?", s.StaffID, v.ID).Error; err != nil {
+				return ErrRefreshInvalid
+			}
+			if !st.IsActive || !st.Accepted() {
+				return ErrRefreshInvalid
+			}
+			vendor.Email, vendor.StaffID, vendor.Permissions = st.Email, st.ID, st.PermissionList()
+		}
+
+		if refresh, err = newRefreshToken(); err != nil {
+			return err
+		}
+		if err := tx.Model(&s).Updates(map[string]any{
+			"prev_refresh_hash": h,
+			"refresh_hash":      hashToken(refresh),
+			"last_used_at":      now,
+			"expires_at":        now.Add(refreshTTL),
+			"ip":                c.ClientIP(),
+			"user_agent":        truncate(c.Request.UserAgent(), 255),
+		}).Error; err != nil {
+			return err
+		}
+		return nil
+	})
+	if reused != "" {
+		a.markRevoked(ctx, reused)
+		return nil, ErrRefreshReused
+	}
+	if err != nil {
+		return nil, err
+	}
+	a.setCookie(c, RefreshCookieName, refresh, refreshPath, refreshTTL)
+	if _, err := a.issueAccess(c, vendor); err != nil {
+		return nil, err
+	}
+	return vendor, nil
+}
+
+// Logout：作廢目前這個裝置的 session（由 access token 的 sid 或 refresh cookie 找出），並清 cookie
+func (a *Auth) Logout(c *gin.Context) error {
+	defer a.ClearCookie(c)
+	ctx := c.Request.Context()
+	if t := tokenFrom(c); t != "" {
+		if claims, err := a.Verify(t); err == nil {
+			return a.revoke(ctx, a.db.Where("id = ?", claims.SessionID))
+		}
+	}
+	if t, _ := c.Cookie(RefreshCookieName); t != "" {
+		return a.revoke(ctx, a.db.Where("refresh_hash = ?", hashToken(t)))
+	}
+	return nil
+}
+
+// Revoke：登出自己的某個裝置（staffID 為空 = 廠商本人的帳號）
+func (a *Auth) Revoke(ctx context.Context, vendorID, staffID, sid string) error {
+	var n int64
+	a.db.WithContext(ctx).Model(&models.VendorSession{}).
+		Where("id = ? AND vendor_id = ? AND staff_id = ? AND revoked_at IS NULL", sid, vendorID, staffID).Count(&n)
+	if n == 0 {
+		return ErrSessionNotFound
+	}
+	return a.revoke(ctx, a.db.Where("id = ? AND vendor_id = ? AND staff_id = ?", sid, vendorID, staffID))
+}
+
+// RevokeAll：登出某個帳號的所有裝置（staffID 為空 = 廠商本人；
+// exceptSID 非空時保留目前裝置，例如改密碼後）
+func (a *Auth) RevokeAll(ctx context.Context, vendorID, staffID, exceptSID string) error {
+	q := a.db.Where("vendor_id = ? AND staff_id = ?", vendorID, staffID)
+	if exceptSID != "" {
+		q = q.Where("id <> ?", exceptSID)
+	}
+	return a.revoke(ctx, q)
+}
+
+// revoke：把符合條件且尚未作廢的 session 標記 revoked_at，並把 sid 放進黑名單
+func (a *Auth) revoke(ctx context.Context, scope *gorm.DB) error {
+	var ids []string
+	if err := scope.Session(&gorm.Session{}).WithContext(ctx).Model(&models.VendorSession{}).
+		Where("revoked_at IS NULL").Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := a.db.WithContext(ctx).Model(&models.VendorSession{}).
+		Where("id IN ?", ids).Update("revoked_at", time.Now()).Error; err != nil {
+		return err
+	}
+	a.markRevoked(ctx, ids...)
+	return nil
+}
+
+// markRevoked：黑名單只需保留到 access token 最長有效期
+func (a *Auth) markRevoked(ctx context.Context, ids ...string) {
+	if a.rdb == nil {
+		return
+	}
+	pipe := a.rdb.Pipeline()
+	for _, id := range ids {
+		pipe.Set(ctx, revokedKey(id), 1, accessTTL)
+	}
+	_, _ = pipe.Exec(ctx)
+}
+
+// revoked：Redis 黑名單優先；Redis 不可用時退回查資料庫。
+// 兩者都查不到時回傳 error，由呼叫端拒絕請求（不可當成未撤銷放行）
+func (a *Auth) revoked(ctx context.Context, sid string) (bool, error) {
+	if a.rdb != nil {
+		n, err := a.rdb.Exists(ctx, revokedKey(sid)).Result()
+		if err == nil {
+			return n > 0, nil
+		}
+	}
+	var n int64
+	if err := a.db.WithContext(ctx).Model(&models.VendorSession{}).
+		Where("id = ? AND revoked_at IS NULL", sid).Count(&n).Error; err != nil {
+		return false, err
+	}
+	return n == 0, nil
+}
+
+// Sessions：某個帳號目前有效的登入裝置（最近使用在前）
+func (a *Auth) Sessions(ctx context.Context, vendorID, staffID string) ([]models.VendorSession, error) {
+	var list []models.VendorSession
+	err := a.db.WithContext(ctx).
+		Where("vendor_id = ? AND staff_id = ? AND revoked_at IS NULL AND expires_at > ?", vendorID, staffID, time.Now()).
+		Order("last_used_at DESC").Find(&list).Error
+	return list, err
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n]
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKeyScopes：API 金鑰可用的權限（只開放商品與訂單同步）
+var APIKeyScopes = []string{PermProducts, PermOrdersView, PermOrdersFulfil}
+
+func ValidAPIKeyScope(s string) bool {
+	for _, x := range APIKeyScopes {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// VendorAPIKey：廠商給自家 ERP 等程式使用的 API 金鑰。
+// 明碼只在建立時顯示一次，資料庫只存 SHA-256；Prefix 供列表辨識
+type VendorAPIKey struct {
+	ID         string     `gorm:"primaryKey;size:36" json:"id"`
+	VendorID   string     `gorm:"size:36;index;not null" json:"-"`
+	Name       string     `gorm:"size:100;not null" json:"name"`
+	Prefix     string     `gorm:"size:16;not null" json:"prefix"`
+	KeyHash    string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Scopes     string     `gorm:"size:255" json:"-"` // 逗號分隔，見 APIKeyScopes
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	LastUsedIP string     `gorm:"size:64" json:"lastUsedIp"`
+	RevokedAt  *time.Time `gorm:"index" json:"revokedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+func (VendorAPIKey) TableName() string { return "vendor_api_keys" }
+
+// ScopeList：權限清單（忽略已不存在的權限）
+func (k *VendorAPIKey) ScopeList() []string {
+	out := []string{}
+	for _, s := range strings.Split(k.Scopes, ",") {
+		if s = strings.TrimSpace(s); ValidAPIKeyScope(s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// VendorSession：一個登入裝置。access token 帶 session ID（sid），refresh token 只存雜湊
+type VendorSession struct {
+	ID          string `gorm:"primaryKey;size:36"`
+	VendorID    string `gorm:"size:36;index;not null"`
+	StaffID     string `gorm:"size:36;index;not null;default:''"` // 空 = 廠商本人
+	RefreshHash string `gorm:"size:64;uniqueIndex;not null"`
+	// 上一把 refresh token：被重複使用代表外洩，整個 session 作廢
+	PrevRefreshHash string `gorm:"size:64;index"`
+	UserAgent       string `gorm:"size:255"`
+	IP              string `gorm:"size:64"`
+	CreatedAt       time.Time
+	LastUsedAt      time.Time
+	ExpiresAt       time.Time  `gorm:"index;not null"`
+	RevokedAt       *time.Time `gorm:"index"`
+}
+
+// 登入失敗原因
+const (
+	LoginInvalidCredentials = "INVALID_CREDENTIALS"
+	LoginInvalidCode        = "INVALID_CODE"      // 雙因素驗證碼錯誤
+	LoginThrottled          = "TOO_MANY_ATTEMPTS" // 漸進延遲期間或同 IP 失敗過多
+	LoginLocked             = "ACCOUNT_LOCKED"    // 鎖定期間嘗試登入
+	LoginLockedOut          = "LOCKED_OUT"        // 此次失敗觸發鎖定
+)
+
+// VendorLoginAudit：廠商登入失敗紀錄（後台查看撞庫 / 暴力破解）
+type VendorLoginAudit struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement" json:"id"`
+	VendorID  string    `gorm:"size:36;index" json:"vendorId"` // 帳號不存在時為空
+	Email     string    `gorm:"size:191;index" json:"email"`
+	IP        string    `gorm:"size:64;index" json:"ip"`
+	UserAgent string    `gorm:"size:255" json:"userAgent"`
+	Reason    string    `gorm:"size:32" json:"reason"`
+	CreatedAt time.Time `gorm:"index" json:"createdAt"`
+}
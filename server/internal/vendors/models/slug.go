@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	reSlugStrip = regexp.MustCompile(`[^a-z0-9]+`)
+	ReSlug      = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{2,63}$`)
+)
+
+// SlugValue：尚未設定時回空字串
+func (v *Vendor) SlugValue() string {
+	if v.Slug == nil {
+		return ""
+	}
+	return *v.Slug
+}
+
+// NewSlug：由店名產生不重複的網址代稱；中文店名等無法轉換時以 ID 前 8 碼代替
+func NewSlug(db *gorm.DB, name, id string) (string, error) {
+	base := strings.Trim(reSlugStrip.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(base) > 48 {
+		base = strings.Trim(base[:48], "-")
+	}
+	if len(base) < 3 {
+		base = "shop-" + strings.ReplaceAll(id, "-", "")[:8]
+	}
+	slug := base
+	for i := 2; ; i++ {
+		var n int64
+		if err := db.Model(&Vendor{}).Where("slug = ?", slug).Count(&n).Error; err != nil {
+			return "", err
+		}
+		if n == 0 {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, i)
+	}
+}
+
+// BackfillSlugs：替舊廠商補上 slug
+func BackfillSlugs(db *gorm.DB) error {
+	var list []Vendor
+	if err := db.Select("id", "name").Where("slug IS NULL OR slug = ''").Find(&list).Error; err != nil {
+		return err
+	}
+	for _, v := range list {
+		slug, err := NewSlug(db, v.Name, v.ID)
+		if err != nil {
+			return err
+		}
+		if err := db.Model(&Vendor{}).Where("id = ?", v.ID).Update("slug", slug).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/totp"
+)
+
+// 員工權限（廠商本人永遠擁有全部權限，另外只有本人能管理員工、商業資料 / 撥款帳戶）
+const (
+	PermProducts     = "products"      // 商品上架 / 編輯 / 刪除、圖片上傳
+	PermOrdersView   = "orders.view"   // 查看訂單
+	PermOrdersFulfil = "orders.fulfil" // 確認、出貨、缺貨
+	PermSettlements  = "settlements"   // 結算單、銷售分析
+)
+
+// StaffPermissions：可指派給員工的權限（前端依此顯示勾選項目）
+var StaffPermissions = []string{PermProducts, PermOrdersView, PermOrdersFulfil, PermSettlements}
+
+func ValidStaffPermission(p string) bool {
+	for _, x := range StaffPermissions {
+		if x == p {
+			return true
+		}
+	}
+	return false
+}
+
+// VendorStaff：廠商的員工子帳號。以 Email 邀請，接受邀請時設定密碼；
+// 登入後所有資料仍以所屬廠商（VendorID）篩選
+type VendorStaff struct {
+	ID           string `gorm:"primaryKey;size:36"`
+	VendorID     string `gorm:"size:36;index;not null"`
+	Email        string `gorm:"uniqueIndex;size:190;not null"`
+	Name         string `gorm:"size:190"`
+	PasswordHash string `gorm:"size:191"` // 接受邀請前為空
+	Permissions  string `gorm:"size:255"` // 逗號分隔，見 StaffPermissions
+	IsActive     bool   `gorm:"not null;default:true"`
+
+	// 邀請連結：只存 token 的 SHA-256；接受後清空
+	InviteHash      string `gorm:"size:64;index"`
+	InviteExpiresAt *time.Time
+	AcceptedAt      *time.Time
+	LastLoginAt     *time.Time
+
+	// 雙因素驗證（選用）
+	TOTP totp.Factor `gorm:"embedded;embeddedPrefix:totp_"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (VendorStaff) TableName() string { return "vendor_staff" }
+
+// PermissionList：權限清單（忽略已不存在的權限）
+func (s *VendorStaff) PermissionList() []string {
+	out := []string{}
+	for _, p := range strings.Split(s.Permissions, ",") {
+		if p = strings.TrimSpace(p); ValidStaffPermission(p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// Accepted：已接受邀請、可以登入
+func (s *VendorStaff) Accepted() bool { return s.AcceptedAt != nil && s.PasswordHash != "" }
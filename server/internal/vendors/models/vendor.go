@@ -1,22 +1,91 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/totp"
+)
 
 type Vendor struct {
-	ID           string    `gorm:"primaryKey;size:36"`
-	Email        string    `gorm:"uniqueIndex;size:190;not null"`
-	Name         string    `gorm:"size:190"`
-	PasswordHash string    `gorm:"size:191;not null"`
-	IsActive     bool      `gorm:"not null;default:true"`
-	CreatedAt    time.Time `gorm:"autoCreateTime"`
-	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+	ID           string `gorm:"primaryKey;size:36"`
+	Email        string `gorm:"uniqueIndex;size:190;not null"`
+	Name         string `gorm:"size:190"`
+	PasswordHash string `gorm:"size:191;not null"`
+	IsActive     bool   `gorm:"not null;default:true"`
+
+	// 上架審核：新註冊為 pending，後台核准後才能上架商品（既有資料預設 approved）
+	Status       string `gorm:"size:16;not null;default:approved;index"`
+	StatusReason string `gorm:"size:255"` // 退回 / 停權原因
+	ReviewedAt   *time.Time
+
+	// 店家頁網址代稱（/api/vendors/:slug）；舊資料啟動時補上
+	Slug *string `gorm:"size:64;uniqueIndex"`
+
+	// 商業資料（店家頁面 / 發票 / 撥款用）
+	CompanyName   string `gorm:"size:190"`
+	TaxID         string `gorm:"size:8"` // 統一編號
+	ContactPhone  string `gorm:"size:32"`
+	ReturnAddress string `gorm:"size:255"`
+	LogoURL       string `gorm:"size:500"`
+	Description   string `gorm:"type:text"`
+
+	// 店家評價（評價功能寫入；目前僅對外顯示）
+	RatingAvg   float64 `gorm:"not null;default:0"`
+	RatingCount int     `gorm:"not null;default:0"`
+
+	// 撥款帳戶：帳號加密儲存，變更後需後台審核
+	BankCode         string `gorm:"size:3"`
+	BankBranch       string `gorm:"size:64"`
+	BankAccountName  string `gorm:"size:190"`
+	BankAccountEnc   string `gorm:"size:255"`
+	BankAccountLast4 string `gorm:"size:4"`
+	BankReview       string `gorm:"size:16;index"` // "" / pending / approved / rejected
+	BankReviewNote   string `gorm:"size:255"`
+	BankUpdatedAt    *time.Time
+	BankReviewedAt   *time.Time
+
+	// 雙因素驗證（選用；TOTP 金鑰以 DATA_KEY 加密）
+	TOTP totp.Factor `gorm:"embedded;embeddedPrefix:totp_"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
 }
 
+// 廠商狀態
+const (
+	StatusPending   = "pending"
+	StatusApproved  = "approved"
+	StatusRejected  = "rejected"
+	StatusSuspended = "suspended"
+)
+
+// 銀行帳戶審核狀態
+const (
+	BankReviewPending  = "pending"
+	BankReviewApproved = "approved"
+	BankReviewRejected = "rejected"
+)
+
+// VendorPasswordReset：重設密碼連結。只存 token 的 SHA-256，每個廠商同時只有一筆有效
 type VendorPasswordReset struct {
 	ID        string    `gorm:"primaryKey;size:36"`
 	VendorID  string    `gorm:"size:36;index;not null"`
-	Token     string    `gorm:"size:191;uniqueIndex;not null"`
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null"`
 	ExpiresAt time.Time `gorm:"index;not null"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 }
 
+// DropPlainResetTokens：舊版明碼 token 欄位（token）無法轉成雜湊，
+// 在 AutoMigrate 前清空舊連結並移除欄位（舊連結本來就只有 30 分鐘效期）
+func DropPlainResetTokens(db *gorm.DB) error {
+	m := db.Migrator()
+	if !m.HasTable(&VendorPasswordReset{}) || !m.HasColumn(&VendorPasswordReset{}, "token") {
+		return nil
+	}
+	if err := db.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&VendorPasswordReset{}).Error; err != nil {
+		return err
+	}
+	return m.DropColumn(&VendorPasswordReset{}, "token")
+}
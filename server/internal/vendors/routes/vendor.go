@@ -2,17 +2,21 @@
 package routes
 
 import (
+	"errors"
+	"log"
 	"net/http"
-	"os"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/mail"
 	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/auth"
 )
 
 // 統一成功/錯誤回應（確保前端永遠拿到 JSON）
@@ -28,7 +32,18 @@ func fail(c *gin.Context, code int, msg string) {
 	c.JSON(code, gin.H{"ok": false, "error": msg})
 }
 
-func RegisterVendorRoutes(r *gin.Engine, gdb *gorm.DB) {
+// tooManyAttempts：登入被鎖定或需等待，附上 Retry-After（秒）
+func tooManyAttempts(c *gin.Context, err error, wait time.Duration) {
+	secs := int((wait + time.Second - 1) / time.Second)
+	msg := "TOO_MANY_ATTEMPTS"
+	if errors.Is(err, auth.ErrLoginLocked) {
+		msg = "ACCOUNT_LOCKED"
+	}
+	c.Header("Retry-After", strconv.Itoa(secs))
+	c.JSON(http.StatusTooManyRequests, gin.H{"ok": false, "error": msg, "retryAfter": secs})
+}
+
+func RegisterVendorRoutes(r *gin.Engine, gdb *gorm.DB, va *auth.Auth, lg *auth.LoginGuard, ms *mail.Service, baseURL string) {
 	grp := r.Group("/api/vendor")
 
 	// 註冊
@@ -43,25 +58,38 @@ func RegisterVendorRoutes(r *gin.Engine, gdb *gorm.DB) {
 		}
 		var count int64
 		gdb.Model(&models.Vendor{}).Where("email = ?", req.Email).Count(&count)
+		if count == 0 {
+			// 員工帳號同樣以 Email 登入，不可重複
+			gdb.Model(&models.VendorStaff{}).Where("email = ?", req.Email).Count(&count)
+		}
 		if count > 0 {
 			fail(c, http.StatusConflict, "EMAIL_EXISTS"); return
 		}
 		hash, _ := bcrypt.GenerateFromPassword([]byte(req.Password), 12)
+		id := uuid.NewString()
+		slug, err := models.NewSlug(gdb, req.Name, id)
+		if err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR"); return
+		}
 		v := &models.Vendor{
-			ID:           uuid.NewString(),
+			ID:           id,
+			Slug:         &slug,
 			Email:        req.Email,
 			Name:         req.Name,
 			PasswordHash: string(hash),
 			IsActive:     true,
+			Status:       models.StatusPending, // 待後台審核
 		}
 		if err := gdb.Create(v).Error; err != nil {
 			fail(c, http.StatusInternalServerError, "DB_ERROR"); return
 		}
-		issueVendorToken(c, v)
-		ok(c, gin.H{"vendor": gin.H{"id": v.ID, "email": v.Email, "name": v.Name}})
+		if _, err := va.Login(c, &auth.Vendor{ID: v.ID, Email: v.Email}); err != nil {
+			fail(c, http.StatusInternalServerError, "TOKEN_ERROR"); return
+		}
+		ok(c, gin.H{"vendor": gin.H{"id": v.ID, "email": v.Email, "name": v.Name, "status": v.Status}})
 	})
 
-	// 登入
+	// 登入（廠商本人或已接受邀請的員工）
 	grp.POST("/login", func(c *gin.Context) {
 		var req struct {
 			Email    string `json:"email" binding:"required,email"`
@@ -70,64 +98,163 @@ func RegisterVendorRoutes(r *gin.Engine, gdb *gorm.DB) {
 		if err := c.ShouldBindJSON(&req); err != nil {
 			fail(c, http.StatusBadRequest, "INVALID_INPUT"); return
 		}
-		var v models.Vendor
-		if err := gdb.Where("email = ? AND is_active = 1", req.Email).First(&v).Error; err != nil {
-			fail(c, http.StatusUnauthorized, "INVALID_CREDENTIALS"); return
+		ctx := c.Request.Context()
+		att := auth.Attempt{Email: req.Email, IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+		// 鎖定 / 延遲期間直接拒絕，不比對密碼
+		if wait, err := lg.Check(ctx, att); err != nil {
+			tooManyAttempts(c, err, wait); return
 		}
-		if bcrypt.CompareHashAndPassword([]byte(v.PasswordHash), []byte(req.Password)) != nil {
+		acct, err := findAccount(gdb, req.Email)
+		if err != nil && !errors.Is(err, errAccountNotFound) {
+			fail(c, http.StatusInternalServerError, "DB_ERROR"); return
+		}
+		hash := ""
+		if acct != nil {
+			att.VendorID, hash = acct.vendor.ID, acct.passwordHash()
+		}
+		// 帳號不存在也比對假雜湊，回應時間一致
+		if !auth.ComparePassword(hash, req.Password) {
+			if token := lg.Fail(ctx, att, models.LoginInvalidCredentials); token != "" {
+				link := baseURL + "/vendor/unlock?token=" + url.QueryEscape(token)
+				if err := ms.AccountLocked(acct.email(), acct.name(), link, auth.LockTTL); err != nil {
+					log.Printf("vendor account locked mail: %v", err)
+				}
+			}
 			fail(c, http.StatusUnauthorized, "INVALID_CREDENTIALS"); return
 		}
-		issueVendorToken(c, &v)
-		ok(c, gin.H{"vendor": gin.H{"id": v.ID, "email": v.Email, "name": v.Name}})
+		lg.Succeed(ctx, acct.email())
+		// 審核中 / 被退回仍可登入查看狀態；停權則不可登入（員工亦同）
+		if acct.vendor.Status == models.StatusSuspended {
+			fail(c, http.StatusForbidden, "VENDOR_SUSPENDED"); return
+		}
+		// 已啟用雙因素驗證：先發 challenge，輸入驗證碼後才建立 session（POST /login/2fa）
+		if acct.factor().Enabled() {
+			challenge, err := va.IssueChallenge(acct.vendor.ID, acct.staffID())
+			if err != nil {
+				fail(c, http.StatusInternalServerError, "TOKEN_ERROR"); return
+			}
+			ok(c, gin.H{"twoFactorRequired": true, "challenge": challenge}); return
+		}
+		if _, err := va.Login(c, acct.principal()); err != nil {
+			fail(c, http.StatusInternalServerError, "TOKEN_ERROR"); return
+		}
+		ok(c, gin.H{"vendor": acct.loggedIn(gdb)})
 	})
 
-	// 登出
-	grp.POST("/logout", func(c *gin.Context) {
-		// 立即失效 cookie
-		c.SetCookie("vtoken", "", -1, "/", "", isSecure(), true)
+	// 解鎖信連結 {token}：解除登入失敗造成的暫時鎖定
+	grp.POST("/login/unlock", func(c *gin.Context) {
+		var req struct{ Token string `json:"token" binding:"required"` }
+		if err := c.ShouldBindJSON(&req); err != nil {
+			fail(c, http.StatusBadRequest, "INVALID_INPUT"); return
+		}
+		if err := lg.Unlock(c.Request.Context(), req.Token); err != nil {
+			if errors.Is(err, auth.ErrUnlockInvalid) {
+				fail(c, http.StatusBadRequest, "INVALID_TOKEN"); return
+			}
+			fail(c, http.StatusInternalServerError, "SERVER_ERROR"); return
+		}
 		ok(c, nil)
 	})
 
-	// 簡易驗證中介（僅此檔使用，不影響你的全局 middleware）
-	requireVendor := func(c *gin.Context) {
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {                      // ★ 新增這兩行
-			secret = "dev-secret"
+	// 以 refresh cookie 換發 access token（refresh token 同時輪替）
+	grp.POST("/auth/refresh", func(c *gin.Context) {
+		v, err := va.Refresh(c)
+		if errors.Is(err, auth.ErrRefreshRace) {
+			// 其他分頁剛完成輪替：保留 cookie，前端直接重試原請求即可
+			fail(c, http.StatusConflict, "REFRESH_IN_PROGRESS"); return
 		}
-		tokenStr, _ := c.Cookie("vtoken")
-		if tokenStr == "" {
-			if auth := c.GetHeader("Authorization"); len(auth) > 7 && auth[:7] == "Bearer " {
-			tokenStr = auth[7:]
+		if err != nil {
+			va.ClearCookie(c)
+			msg := "INVALID_REFRESH_TOKEN"
+			if errors.Is(err, auth.ErrRefreshReused) {
+				msg = "REFRESH_TOKEN_REUSED"
+			} else if !errors.Is(err, auth.ErrRefreshInvalid) {
+				fail(c, http.StatusInternalServerError, "DB_ERROR"); return
 			}
+			fail(c, http.StatusUnauthorized, msg); return
+		}
+		ok(c, gin.H{"vendor": gin.H{"id": v.ID, "email": v.Email}})
+	})
+
+	// 登出：作廢目前裝置的 session 並清 cookie
+	grp.POST("/logout", func(c *gin.Context) {
+		if err := va.Logout(c); err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR"); return
+		}
+		ok(c, nil)
+	})
+
+	requireVendor := va.Middleware()
+
+	// 登出所有裝置（含目前這台）
+	grp.POST("/logout-all", requireVendor, func(c *gin.Context) {
+		cur := auth.Current(c)
+		if err := va.RevokeAll(c.Request.Context(), cur.ID, cur.StaffID, ""); err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR"); return
 		}
-		if tokenStr == "" {
-			fail(c, http.StatusUnauthorized, "UNAUTHENTICATED"); c.Abort(); return
+		va.ClearCookie(c)
+		ok(c, nil)
+	})
+
+	// 目前有效的登入裝置
+	grp.GET("/sessions", requireVendor, func(c *gin.Context) {
+		cur := auth.Current(c)
+		list, err := va.Sessions(c.Request.Context(), cur.ID, cur.StaffID)
+		if err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR"); return
 		}
-		claims := jwt.MapClaims{}
-		parsed, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-		if err != nil || !parsed.Valid || claims["role"] != "vendor" {
-			fail(c, http.StatusUnauthorized, "INVALID_TOKEN"); c.Abort(); return
+		items := make([]gin.H, 0, len(list))
+		for _, s := range list {
+			items = append(items, gin.H{
+				"id": s.ID, "userAgent": s.UserAgent, "ip": s.IP,
+				"createdAt": s.CreatedAt, "lastUsedAt": s.LastUsedAt, "expiresAt": s.ExpiresAt,
+				"current": s.ID == cur.SessionID,
+			})
 		}
-		c.Set("vendor_id", claims["id"])
-		c.Next()
+		ok(c, gin.H{"items": items})
+	})
+
+	// 登出指定裝置
+	grp.DELETE("/sessions/:id", requireVendor, func(c *gin.Context) {
+		cur := auth.Current(c)
+		if err := va.Revoke(c.Request.Context(), cur.ID, cur.StaffID, c.Param("id")); err != nil {
+			if errors.Is(err, auth.ErrSessionNotFound) {
+				fail(c, http.StatusNotFound, "NOT_FOUND"); return
+			}
+			fail(c, http.StatusInternalServerError, "DB_ERROR"); return
 		}
+		if c.Param("id") == cur.SessionID {
+			va.ClearCookie(c)
+		}
+		ok(c, nil)
+	})
 
-	// 取得自己
+	// 取得自己（員工另回傳 staff；permissions 為目前可用的權限，本人為全部）
 	grp.GET("/me", requireVendor, func(c *gin.Context) {
-		id, _ := c.Get("vendor_id")
+		cur := auth.Current(c)
 		var v models.Vendor
-		if err := gdb.Select("id,email,name").First(&v, "id = ?", id).Error; err != nil {
+		if err := gdb.Select("id,email,name,status,status_reason").First(&v, "id = ?", cur.ID).Error; err != nil {
 			// 還是回 200，但 vendor = null
 			ok(c, gin.H{"vendor": nil}); return
 		}
-		ok(c, gin.H{"vendor": v})
+		out := gin.H{
+			"id": v.ID, "email": v.Email, "name": v.Name,
+			"status": v.Status, "statusReason": v.StatusReason,
+			"owner": cur.Owner(), "permissions": models.StaffPermissions,
+		}
+		if !cur.Owner() {
+			var s models.VendorStaff
+			if err := gdb.Select("id,email,name").First(&s, "id = ? AND vendor_id = ?", cur.StaffID, cur.ID).Error; err != nil {
+				ok(c, gin.H{"vendor": nil}); return
+			}
+			out["email"], out["permissions"] = s.Email, cur.Permissions
+			out["staff"] = gin.H{"id": s.ID, "name": s.Name}
+		}
+		ok(c, gin.H{"vendor": out})
 	})
 
-	// 更改密碼
+	// 更改密碼（員工改自己的密碼）
 	grp.POST("/change-password", requireVendor, func(c *gin.Context) {
-		id, _ := c.Get("vendor_id")
 		var req struct {
 			OldPassword string `json:"oldPassword" binding:"required"`
 			NewPassword string `json:"newPassword" binding:"required,min=8"`
@@ -135,21 +262,32 @@ func RegisterVendorRoutes(r *gin.Engine, gdb *gorm.DB) {
 		if err := c.ShouldBindJSON(&req); err != nil {
 			fail(c, http.StatusBadRequest, "INVALID_INPUT"); return
 		}
-		var v models.Vendor
-		if err := gdb.First(&v, "id = ?", id).Error; err != nil {
+		acct, err := currentAccount(c, gdb)
+		if err != nil {
 			fail(c, http.StatusUnauthorized, "UNAUTHENTICATED"); return
 		}
-		if bcrypt.CompareHashAndPassword([]byte(v.PasswordHash), []byte(req.OldPassword)) != nil {
+		if bcrypt.CompareHashAndPassword([]byte(acct.passwordHash()), []byte(req.OldPassword)) != nil {
 			fail(c, http.StatusBadRequest, "WRONG_OLD_PASSWORD"); return
 		}
 		hash, _ := bcrypt.GenerateFromPassword([]byte(req.NewPassword), 12)
-		if err := gdb.Model(&v).Update("password_hash", string(hash)).Error; err != nil {
+		if err := acct.update(gdb, map[string]any{"password_hash": string(hash)}); err != nil {
 			fail(c, http.StatusInternalServerError, "DB_ERROR"); return
 		}
+		// 其他裝置一律登出，保留目前這台；尚未使用的重設連結作廢
+		ctx := c.Request.Context()
+		if err := va.RevokeAll(ctx, acct.vendor.ID, acct.staffID(), auth.Current(c).SessionID); err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR"); return
+		}
+		if acct.staff == nil {
+			if err := va.ClearResetTokens(ctx, acct.vendor.ID); err != nil {
+				fail(c, http.StatusInternalServerError, "DB_ERROR"); return
+			}
+		}
 		ok(c, nil)
 	})
 
-	// 忘記密碼（開發期直接回 token，正式環境應寄信）
+	// 忘記密碼：寄出重設連結（信件由背景佇列寄送）。
+	// 只適用廠商本人；員工忘記密碼由廠商重寄邀請信重新設定
 	grp.POST("/password/forgot", func(c *gin.Context) {
 		var req struct{ Email string `json:"email" binding:"required,email"` }
 		if err := c.ShouldBindJSON(&req); err != nil {
@@ -160,18 +298,15 @@ func RegisterVendorRoutes(r *gin.Engine, gdb *gorm.DB) {
 			// 隱匿存在性：即使沒有該帳號也回 ok=true
 			ok(c, nil); return
 		}
-		token := uuid.NewString()
-		reset := &models.VendorPasswordReset{
-			ID:        uuid.NewString(),
-			VendorID:  v.ID,
-			Token:     token,
-			ExpiresAt: time.Now().Add(30 * time.Minute),
-		}
-		if err := gdb.Create(reset).Error; err != nil {
+		token, err := va.IssueResetToken(c.Request.Context(), v.ID)
+		if err != nil {
 			fail(c, http.StatusInternalServerError, "DB_ERROR"); return
 		}
-		// 開發模式：一併回 token，方便前端測試；正式環境應改為寄信
-		ok(c, gin.H{"token": token})
+		link := baseURL + "/vendor/reset?token=" + url.QueryEscape(token)
+		if err := ms.PasswordReset(v.Email, v.Name, link, auth.ResetTTL); err != nil {
+			log.Printf("vendor password reset mail: %v", err)
+		}
+		ok(c, nil)
 	})
 
 	// 重設密碼
@@ -183,54 +318,23 @@ func RegisterVendorRoutes(r *gin.Engine, gdb *gorm.DB) {
 		if err := c.ShouldBindJSON(&req); err != nil {
 			fail(c, http.StatusBadRequest, "INVALID_INPUT"); return
 		}
-		var pr models.VendorPasswordReset
-		if err := gdb.First(&pr, "token = ?", req.Token).Error; err != nil {
-			fail(c, http.StatusBadRequest, "INVALID_TOKEN"); return
-		}
-		if time.Now().After(pr.ExpiresAt) {
-			fail(c, http.StatusBadRequest, "TOKEN_EXPIRED"); return
-		}
-		var v models.Vendor
-		if err := gdb.First(&v, "id = ?", pr.VendorID).Error; err != nil {
-			fail(c, http.StatusBadRequest, "INVALID_VENDOR"); return
+		// 成功後所有重設連結作廢、所有裝置都要重新登入
+		v, err := va.ResetPassword(c.Request.Context(), req.Token, req.NewPassword)
+		if err != nil {
+			switch {
+			case errors.Is(err, auth.ErrResetInvalid):
+				fail(c, http.StatusBadRequest, "INVALID_TOKEN")
+			case errors.Is(err, auth.ErrResetExpired):
+				fail(c, http.StatusBadRequest, "TOKEN_EXPIRED")
+			default:
+				fail(c, http.StatusInternalServerError, "DB_ERROR")
+			}
+			return
 		}
-		hash, _ := bcrypt.GenerateFromPassword([]byte(req.NewPassword), 12)
-		if err := gdb.Model(&v).Update("password_hash", string(hash)).Error; err != nil {
-			fail(c, http.StatusInternalServerError, "DB_ERROR"); return
+		// 已證明擁有信箱：一併解除登入鎖定
+		if err := lg.Clear(c.Request.Context(), v.Email); err != nil {
+			log.Printf("vendor login unlock: %v", err)
 		}
-		gdb.Delete(&pr)
 		ok(c, nil)
 	})
 }
-
-func issueVendorToken(c *gin.Context, v *models.Vendor) {
-  secret := os.Getenv("JWT_SECRET")
-  if secret == "" { secret = "dev-secret" }
-
-  ttl := 7 * 24 * time.Hour
-  claims := jwt.MapClaims{
-    "id": v.ID, "email": v.Email, "role": "vendor",
-    "exp": time.Now().Add(ttl).Unix(),
-  }
-  token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-  signed, _ := token.SignedString([]byte(secret))
-
-  // 明確設置 Cookie 屬性（本地非 https → Secure=false；SameSite=Lax）
-  http.SetCookie(c.Writer, &http.Cookie{
-    Name:     "vtoken",
-    Value:    signed,
-    Path:     "/",
-    MaxAge:   int(ttl.Seconds()),
-    HttpOnly: true,
-    Secure:   os.Getenv("APP_ENV") == "production",
-    SameSite: http.SameSiteLaxMode,
-  })
-  // 讓 gin 知道我們已寫 header
-  c.Writer.Header().Add("Vary", "Cookie")
-}
-
-// isSecure：本地開發用 http，正式用 https
-func isSecure() bool {
-	// 你可以換成讀 ENV：APP_ENV=production
-	return os.Getenv("APP_ENV") == "production"
-}
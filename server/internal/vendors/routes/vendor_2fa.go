@@ -0,0 +1,229 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/secretbox"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/totp"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/auth"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
+
+var errWrongPassword = errors.New("wrong password")
+
+// totpFail：雙因素驗證錯誤 → 回應代碼
+func totpFail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, totp.ErrInvalidCode):
+		fail(c, http.StatusBadRequest, "INVALID_CODE")
+	case errors.Is(err, totp.ErrLocked):
+		fail(c, http.StatusTooManyRequests, "TOO_MANY_ATTEMPTS")
+	case errors.Is(err, totp.ErrAlreadyEnabled):
+		fail(c, http.StatusConflict, "2FA_ALREADY_ENABLED")
+	case errors.Is(err, totp.ErrNotEnabled):
+		fail(c, http.StatusBadRequest, "2FA_NOT_ENABLED")
+	case errors.Is(err, totp.ErrNotSetup):
+		fail(c, http.StatusBadRequest, "2FA_SETUP_REQUIRED")
+	case errors.Is(err, errWrongPassword):
+		fail(c, http.StatusBadRequest, "WRONG_PASSWORD")
+	default:
+		fail(c, http.StatusInternalServerError, "SERVER_ERROR")
+	}
+}
+
+// 廠商：雙因素驗證（TOTP）綁定 / 停用 / 備用碼，以及登入第二步（廠商本人與員工各自綁定）
+func RegisterVendorTwoFactorRoutes(r *gin.Engine, gdb *gorm.DB, box *secretbox.Box, va *auth.Auth, lg *auth.LoginGuard, issuer string) {
+	grp := r.Group("/api/vendor")
+
+	saveFactor := func(a *account) error {
+		return a.update(gdb, a.factor().Columns())
+	}
+
+	// withFactor：鎖定目前登入者的帳號後執行 fn（見 lockFactor），失敗時直接回應；fn 成功才回傳 true
+	withFactor := func(c *gin.Context, fn func(a *account) error) bool {
+		cur := auth.Current(c)
+		acct, err := lockFactor(gdb, cur.ID, cur.StaffID, fn)
+		switch {
+		case acct == nil && errors.Is(err, errAccountNotFound):
+			fail(c, http.StatusUnauthorized, "UNAUTHENTICATED")
+		case acct == nil:
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+		case err != nil:
+			totpFail(c, err)
+		default:
+			return true
+		}
+		return false
+	}
+
+	// 登入第二步：{challenge, code}（code 可為 6 位數驗證碼或備用碼）
+	grp.POST("/login/2fa", func(c *gin.Context) {
+		var req struct {
+			Challenge string `json:"challenge" binding:"required"`
+			Code      string `json:"code" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			fail(c, http.StatusBadRequest, "INVALID_INPUT")
+			return
+		}
+		vendorID, staffID, err := va.VerifyChallenge(req.Challenge)
+		if err != nil {
+			fail(c, http.StatusUnauthorized, "CHALLENGE_EXPIRED")
+			return
+		}
+		acct, err := loadAccount(gdb, vendorID, staffID)
+		if err != nil {
+			fail(c, http.StatusUnauthorized, "INVALID_CREDENTIALS")
+			return
+		}
+		if acct.vendor.Status == models.StatusSuspended {
+			fail(c, http.StatusForbidden, "VENDOR_SUSPENDED")
+			return
+		}
+		acct, checkErr := lockFactor(gdb, vendorID, staffID, func(a *account) error {
+			return a.factor().Check(box, req.Code, time.Now())
+		})
+		if acct == nil {
+			if errors.Is(checkErr, errAccountNotFound) {
+				fail(c, http.StatusUnauthorized, "INVALID_CREDENTIALS")
+				return
+			}
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		if checkErr != nil {
+			if errors.Is(checkErr, totp.ErrInvalidCode) || errors.Is(checkErr, totp.ErrLocked) {
+				lg.Record(c.Request.Context(), auth.Attempt{
+					Email: acct.email(), IP: c.ClientIP(), UserAgent: c.Request.UserAgent(), VendorID: acct.vendor.ID,
+				}, models.LoginInvalidCode)
+			}
+			totpFail(c, checkErr)
+			return
+		}
+		if _, err := va.Login(c, acct.principal()); err != nil {
+			fail(c, http.StatusInternalServerError, "TOKEN_ERROR")
+			return
+		}
+		ok(c, gin.H{
+			"vendor":            acct.loggedIn(gdb),
+			"recoveryCodesLeft": totp.RecoveryCodesLeft(acct.factor().RecoveryCodes),
+		})
+	})
+
+	tf := grp.Group("/2fa", va.Middleware())
+
+	load := func(c *gin.Context) (*account, bool) {
+		acct, err := currentAccount(c, gdb)
+		if err != nil {
+			fail(c, http.StatusUnauthorized, "UNAUTHENTICATED")
+			return nil, false
+		}
+		return acct, true
+	}
+
+	// 目前狀態
+	tf.GET("", func(c *gin.Context) {
+		v, found := load(c)
+		if !found {
+			return
+		}
+		ok(c, gin.H{"twoFactor": v.factor()})
+	})
+
+	// 開始綁定：產生金鑰與 otpauth URI（尚未啟用，需 /enable 確認）
+	tf.POST("/setup", func(c *gin.Context) {
+		v, found := load(c)
+		if !found {
+			return
+		}
+		secret, uri, err := v.factor().Setup(box, issuer, v.email())
+		if err != nil {
+			totpFail(c, err)
+			return
+		}
+		if err := saveFactor(v); err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		ok(c, gin.H{"secret": secret, "uri": uri})
+	})
+
+	// 確認綁定 {code} → 回傳備用碼（只顯示一次）；其他裝置一律登出
+	tf.POST("/enable", func(c *gin.Context) {
+		var req struct {
+			Code string `json:"code" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			fail(c, http.StatusBadRequest, "INVALID_INPUT")
+			return
+		}
+		var codes []string
+		if !withFactor(c, func(a *account) error {
+			var err error
+			codes, err = a.factor().Enable(box, req.Code, time.Now())
+			return err
+		}) {
+			return
+		}
+		cur := auth.Current(c)
+		if err := va.RevokeAll(c.Request.Context(), cur.ID, cur.StaffID, cur.SessionID); err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		ok(c, gin.H{"recoveryCodes": codes})
+	})
+
+	// 停用 {password, code}
+	tf.POST("/disable", func(c *gin.Context) {
+		var req struct {
+			Password string `json:"password" binding:"required"`
+			Code     string `json:"code" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			fail(c, http.StatusBadRequest, "INVALID_INPUT")
+			return
+		}
+		if !withFactor(c, func(a *account) error {
+			if bcrypt.CompareHashAndPassword([]byte(a.passwordHash()), []byte(req.Password)) != nil {
+				return errWrongPassword
+			}
+			if err := a.factor().Check(box, req.Code, time.Now()); err != nil {
+				return err
+			}
+			a.factor().Disable()
+			return nil
+		}) {
+			return
+		}
+		ok(c, nil)
+	})
+
+	// 重新產生備用碼 {code}
+	tf.POST("/recovery-codes", func(c *gin.Context) {
+		var req struct {
+			Code string `json:"code" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			fail(c, http.StatusBadRequest, "INVALID_INPUT")
+			return
+		}
+		var codes []string
+		if !withFactor(c, func(a *account) error {
+			if err := a.factor().Check(box, req.Code, time.Now()); err != nil {
+				return err
+			}
+			var err error
+			codes, err = a.factor().RegenerateRecovery()
+			return err
+		}) {
+			return
+		}
+		ok(c, gin.H{"recoveryCodes": codes})
+	})
+}
@@ -0,0 +1,158 @@
+package routes
+
+import (
+	"errors"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/totp"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/auth"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
+
+var errAccountNotFound = errors.New("account not found")
+
+// account：可登入的帳號（廠商本人或員工），登入、改密碼、雙因素驗證共用
+type account struct {
+	vendor *models.Vendor      // 所屬廠商
+	staff  *models.VendorStaff // nil = 廠商本人
+}
+
+// findAccount：以 Email 找可登入的帳號（先找廠商本人，再找已接受邀請的員工）
+func findAccount(gdb *gorm.DB, email string) (*account, error) {
+	var v models.Vendor
+	err := gdb.Where("email = ? AND is_active = 1", email).First(&v).Error
+	if err == nil {
+		return &account{vendor: &v}, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	var s models.VendorStaff
+	if err := gdb.Where("email = ? AND is_active = 1 AND accepted_at IS NOT NULL", email).First(&s).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errAccountNotFound
+		}
+		return nil, err
+	}
+	return loadAccount(gdb, s.VendorID, s.ID)
+}
+
+// loadAccount：依廠商 ID / 員工 ID（本人為空）載入帳號，停用的帳號視為不存在
+func loadAccount(gdb *gorm.DB, vendorID, staffID string) (*account, error) {
+	var v models.Vendor
+	if err := gdb.Where("id = ? AND is_active = 1", vendorID).First(&v).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errAccountNotFound
+		}
+		return nil, err
+	}
+	if staffID == "" {
+		return &account{vendor: &v}, nil
+	}
+	var s models.VendorStaff
+	if err := gdb.Where("id = ? AND vendor_id = ? AND is_active = 1", staffID, vendorID).First(&s).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errAccountNotFound
+		}
+		return nil, err
+	}
+	if !s.Accepted() {
+		return nil, errAccountNotFound
+	}
+	return &account{vendor: &v, staff: &s}, nil
+}
+
+// lockFactor：在交易中重新載入帳號並鎖定所在的資料列（SELECT ... FOR UPDATE），執行 fn 後寫回 2FA 欄位。
+// 同一組驗證碼的併發請求因此依序比對，後到的會看到已用過的時間步而被拒絕。
+// 回傳的帳號為 nil 表示讀取 / 寫入失敗；否則 error 為 fn 的結果（驗證碼錯誤時錯誤次數照樣寫回）
+func lockFactor(gdb *gorm.DB, vendorID, staffID string, fn func(a *account) error) (*account, error) {
+	var (
+		acct  *account
+		fnErr error
+	)
+	err := gdb.Transaction(func(tx *gorm.DB) error {
+		var err error
+		locked := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Session(&gorm.Session{})
+		if acct, err = loadAccount(locked, vendorID, staffID); err != nil {
+			return err
+		}
+		fnErr = fn(acct)
+		return acct.update(tx, acct.factor().Columns())
+	})
+	if err != nil {
+		return nil, err
+	}
+	return acct, fnErr
+}
+
+// currentAccount：目前登入者的帳號
+func currentAccount(c *gin.Context, gdb *gorm.DB) (*account, error) {
+	cur := auth.Current(c)
+	return loadAccount(gdb, cur.ID, cur.StaffID)
+}
+
+func (a *account) staffID() string {
+	if a.staff == nil {
+		return ""
+	}
+	return a.staff.ID
+}
+
+func (a *account) email() string {
+	if a.staff != nil {
+		return a.staff.Email
+	}
+	return a.vendor.Email
+}
+
+func (a *account) name() string {
+	if a.staff != nil {
+		return a.staff.Name
+	}
+	return a.vendor.Name
+}
+
+func (a *account) passwordHash() string {
+	if a.staff != nil {
+		return a.staff.PasswordHash
+	}
+	return a.vendor.PasswordHash
+}
+
+func (a *account) factor() *totp.Factor {
+	if a.staff != nil {
+		return &a.staff.TOTP
+	}
+	return &a.vendor.TOTP
+}
+
+// principal：建立 session 用的登入者
+func (a *account) principal() *auth.Vendor {
+	p := &auth.Vendor{ID: a.vendor.ID, Email: a.email()}
+	if a.staff != nil {
+		p.StaffID, p.Permissions = a.staff.ID, a.staff.PermissionList()
+	}
+	return p
+}
+
+// update：寫回帳號本身所在的資料表
+func (a *account) update(gdb *gorm.DB, cols map[string]any) error {
+	if a.staff != nil {
+		return gdb.Model(&models.VendorStaff{}).Where("id = ?", a.staff.ID).Updates(cols).Error
+	}
+	return gdb.Model(&models.Vendor{}).Where("id = ?", a.vendor.ID).Updates(cols).Error
+}
+
+// loggedIn：登入成功後的收尾（員工記錄最後登入時間），回傳給前端的資料
+func (a *account) loggedIn(gdb *gorm.DB) gin.H {
+	out := gin.H{"id": a.vendor.ID, "email": a.email(), "name": a.vendor.Name, "status": a.vendor.Status}
+	if a.staff != nil {
+		_ = a.update(gdb, map[string]any{"last_login_at": time.Now()})
+		out["staff"] = gin.H{"id": a.staff.ID, "name": a.staff.Name, "permissions": a.staff.PermissionList()}
+	}
+	return out
+}
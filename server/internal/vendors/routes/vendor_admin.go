@@ -0,0 +1,185 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/secretbox"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/auth"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
+
+// 後台：廠商審核 / 停權、銀行帳戶審核
+func RegisterAdminVendorRoutes(admin *gin.RouterGroup, gdb *gorm.DB, box *secretbox.Box, lg *auth.LoginGuard) {
+	// 廠商列表 ?status=pending|approved|rejected|suspended
+	admin.GET("/vendors", func(c *gin.Context) {
+		q := gdb.Model(&models.Vendor{})
+		if st := strings.TrimSpace(c.Query("status")); st != "" {
+			q = q.Where("status = ?", st)
+		}
+		var list []models.Vendor
+		if err := q.Order("created_at DESC").Find(&list).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		items := make([]gin.H, 0, len(list))
+		for i := range list {
+			item := profileJSON(&list[i])
+			item["status"] = list[i].Status
+			item["statusReason"] = list[i].StatusReason
+			item["reviewedAt"] = list[i].ReviewedAt
+			item["createdAt"] = list[i].CreatedAt
+			items = append(items, item)
+		}
+		c.JSON(http.StatusOK, gin.H{"items": items})
+	})
+
+	// 狀態轉換：from 為允許的原狀態
+	transition := func(to string, from []string, needReason bool) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			var in struct {
+				Reason string `json:"reason"`
+			}
+			_ = c.ShouldBindJSON(&in)
+			in.Reason = strings.TrimSpace(in.Reason)
+			if needReason && in.Reason == "" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "reason required"})
+				return
+			}
+			res := gdb.Model(&models.Vendor{}).
+				Where("id = ? AND status IN ?", c.Param("id"), from).
+				Updates(map[string]any{
+					"status":        to,
+					"status_reason": in.Reason,
+					"reviewed_at":   time.Now(),
+				})
+			if res.Error != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": res.Error.Error()})
+				return
+			}
+			if res.RowsAffected == 0 {
+				var n int64
+				gdb.Model(&models.Vendor{}).Where("id = ?", c.Param("id")).Count(&n)
+				if n == 0 {
+					c.JSON(http.StatusNotFound, gin.H{"error": "vendor not found"})
+				} else {
+					c.JSON(http.StatusConflict, gin.H{"error": "invalid status transition"})
+				}
+				return
+			}
+			c.Status(http.StatusNoContent)
+		}
+	}
+	admin.PUT("/vendors/:id/approve", transition(models.StatusApproved,
+		[]string{models.StatusPending, models.StatusRejected, models.StatusSuspended}, false))
+	admin.PUT("/vendors/:id/reject", transition(models.StatusRejected,
+		[]string{models.StatusPending}, true))
+	admin.PUT("/vendors/:id/suspend", transition(models.StatusSuspended,
+		[]string{models.StatusApproved}, true))
+
+	// 待審核清單（含完整帳號供核對）
+	admin.GET("/vendors/bank-reviews", func(c *gin.Context) {
+		status := c.DefaultQuery("status", models.BankReviewPending)
+		var list []models.Vendor
+		if err := gdb.Where("bank_review = ?", status).Order("bank_updated_at ASC").Find(&list).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		items := make([]gin.H, 0, len(list))
+		for i := range list {
+			item := profileJSON(&list[i])
+			acct, err := box.Open(list[i].BankAccountEnc)
+			if err != nil {
+				acct = ""
+			}
+			item["bankAccount"] = acct
+			items = append(items, item)
+		}
+		c.JSON(http.StatusOK, gin.H{"items": items})
+	})
+
+	// 核准 / 退回 {"approve":true,"note":""}
+	admin.PUT("/vendors/:id/bank-review", func(c *gin.Context) {
+		var in struct {
+			Approve bool   `json:"approve"`
+			Note    string `json:"note"`
+		}
+		if err := c.ShouldBindJSON(&in); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid input"})
+			return
+		}
+		var v models.Vendor
+		if err := gdb.First(&v, "id = ?", c.Param("id")).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "vendor not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if v.BankReview != models.BankReviewPending {
+			c.JSON(http.StatusConflict, gin.H{"error": "no pending bank review"})
+			return
+		}
+		status := models.BankReviewRejected
+		if in.Approve {
+			status = models.BankReviewApproved
+		}
+		if err := gdb.Model(&v).Updates(map[string]any{
+			"bank_review":      status,
+			"bank_review_note": strings.TrimSpace(in.Note),
+			"bank_reviewed_at": time.Now(),
+		}).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	// 廠商登入失敗紀錄 ?email=&ip=&vendorId=&limit=50&offset=0
+	admin.GET("/vendors/login-audits", func(c *gin.Context) {
+		q := auth.AuditQuery{
+			Email:    strings.TrimSpace(c.Query("email")),
+			IP:       strings.TrimSpace(c.Query("ip")),
+			VendorID: strings.TrimSpace(c.Query("vendorId")),
+		}
+		q.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+		if q.Limit < 1 || q.Limit > 200 {
+			q.Limit = 50
+		}
+		q.Offset, _ = strconv.Atoi(c.Query("offset"))
+		if q.Offset < 0 {
+			q.Offset = 0
+		}
+		list, total, err := lg.Audits(c.Request.Context(), q)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": q.Limit, "offset": q.Offset})
+	})
+
+	// 手動解除登入鎖定
+	admin.POST("/vendors/:id/unlock", func(c *gin.Context) {
+		var v models.Vendor
+		if err := gdb.Select("id", "email").First(&v, "id = ?", c.Param("id")).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "vendor not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if err := lg.Clear(c.Request.Context(), v.Email); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,102 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/analytics"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/tz"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/auth"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
+
+// 廠商：銷售分析（只計已確認收款的訂單，日期以台北時間切分）
+// 共用參數：?from=2025-09-01&to=2025-09-30（含當日，預設近 30 天）
+func RegisterVendorAnalyticsRoutes(r *gin.Engine, svc *analytics.Service, va *auth.Auth) {
+	grp := r.Group("/api/vendor/analytics")
+	grp.Use(va.Middleware(), auth.Require(models.PermSettlements))
+
+	// GET /api/vendor/analytics/summary
+	grp.GET("/summary", func(c *gin.Context) {
+		rg, ok := parseRange(c)
+		if !ok {
+			return
+		}
+		sum, err := svc.Summary(c.Request.Context(), auth.VendorID(c), rg)
+		if err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true, "summary": sum})
+	})
+
+	// GET /api/vendor/analytics/sales?granularity=day|week|month
+	grp.GET("/sales", func(c *gin.Context) {
+		rg, ok := parseRange(c)
+		if !ok {
+			return
+		}
+		by := c.DefaultQuery("granularity", analytics.ByDay)
+		switch by {
+		case analytics.ByDay, analytics.ByWeek, analytics.ByMonth:
+		default:
+			fail(c, http.StatusBadRequest, "INVALID_GRANULARITY")
+			return
+		}
+		points, err := svc.Series(c.Request.Context(), auth.VendorID(c), rg, by)
+		if err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true, "granularity": by, "points": points})
+	})
+
+	// GET /api/vendor/analytics/top-products?limit=10
+	grp.GET("/top-products", func(c *gin.Context) {
+		rg, ok := parseRange(c)
+		if !ok {
+			return
+		}
+		limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+		if limit < 1 || limit > 50 {
+			limit = 10
+		}
+		list, err := svc.TopProducts(c.Request.Context(), auth.VendorID(c), rg, limit)
+		if err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true, "products": list})
+	})
+}
+
+// parseRange：from/to 皆為含當日的日期（台北時間）；最長一年
+func parseRange(c *gin.Context) (analytics.Range, bool) {
+	today := time.Now().In(tz.Taipei)
+	today = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, tz.Taipei)
+	rg := analytics.Range{From: today.AddDate(0, 0, -29), To: today.AddDate(0, 0, 1)}
+	if v := c.Query("from"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, tz.Taipei)
+		if err != nil {
+			fail(c, http.StatusBadRequest, "INVALID_FROM")
+			return rg, false
+		}
+		rg.From = t
+	}
+	if v := c.Query("to"); v != "" {
+		t, err := time.ParseInLocation("2006-01-02", v, tz.Taipei)
+		if err != nil {
+			fail(c, http.StatusBadRequest, "INVALID_TO")
+			return rg, false
+		}
+		rg.To = t.AddDate(0, 0, 1)
+	}
+	if !rg.From.Before(rg.To) || rg.To.Sub(rg.From) > 366*24*time.Hour {
+		fail(c, http.StatusBadRequest, "INVALID_RANGE")
+		return rg, false
+	}
+	return rg, true
+}
@@ -0,0 +1,124 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/auth"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
+
+// maxAPIKeys：每家廠商同時有效的金鑰上限
+const maxAPIKeys = 20
+
+func apiKeyJSON(k *models.VendorAPIKey) gin.H {
+	return gin.H{
+		"id":         k.ID,
+		"name":       k.Name,
+		"prefix":     k.Prefix,
+		"scopes":     k.ScopeList(),
+		"lastUsedAt": k.LastUsedAt,
+		"lastUsedIp": k.LastUsedIP,
+		"revokedAt":  k.RevokedAt,
+		"createdAt":  k.CreatedAt,
+	}
+}
+
+// 廠商：API 金鑰（只有廠商本人能建立 / 撤銷）。
+// 金鑰以 Authorization: Bearer zsk_… 存取 /api/vendor/products、/api/vendor/orders
+func RegisterVendorAPIKeyRoutes(r *gin.Engine, gdb *gorm.DB, va *auth.Auth) {
+	grp := r.Group("/api/vendor/api-keys", va.Middleware(), auth.OwnerOnly())
+
+	// 金鑰列表（含已撤銷），並附上可用的 scopes
+	grp.GET("", func(c *gin.Context) {
+		var list []models.VendorAPIKey
+		if err := gdb.Where("vendor_id = ?", auth.VendorID(c)).Order("created_at DESC").Find(&list).Error; err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		items := make([]gin.H, 0, len(list))
+		for i := range list {
+			items = append(items, apiKeyJSON(&list[i]))
+		}
+		ok(c, gin.H{"items": items, "scopes": models.APIKeyScopes})
+	})
+
+	// 建立 {name, scopes} → 回傳金鑰明碼（只顯示這一次）
+	grp.POST("", func(c *gin.Context) {
+		var req struct {
+			Name   string   `json:"name" binding:"required,max=100"`
+			Scopes []string `json:"scopes" binding:"required,min=1"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			fail(c, http.StatusBadRequest, "INVALID_INPUT")
+			return
+		}
+		name := strings.TrimSpace(req.Name)
+		if name == "" {
+			fail(c, http.StatusBadRequest, "INVALID_INPUT")
+			return
+		}
+		seen := map[string]bool{}
+		scopes := make([]string, 0, len(req.Scopes))
+		for _, s := range req.Scopes {
+			if !models.ValidAPIKeyScope(s) {
+				fail(c, http.StatusBadRequest, "INVALID_SCOPE")
+				return
+			}
+			if !seen[s] {
+				seen[s] = true
+				scopes = append(scopes, s)
+			}
+		}
+		vid := auth.VendorID(c)
+		var n int64
+		if err := gdb.Model(&models.VendorAPIKey{}).Where("vendor_id = ? AND revoked_at IS NULL", vid).Count(&n).Error; err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		if n >= maxAPIKeys {
+			fail(c, http.StatusConflict, "TOO_MANY_KEYS")
+			return
+		}
+		key, prefix, hash := auth.NewAPIKey()
+		k := &models.VendorAPIKey{
+			ID:       uuid.NewString(),
+			VendorID: vid,
+			Name:     name,
+			Prefix:   prefix,
+			KeyHash:  hash,
+			Scopes:   strings.Join(scopes, ","),
+		}
+		if err := gdb.Create(k).Error; err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		ok(c, gin.H{"apiKey": apiKeyJSON(k), "key": key})
+	})
+
+	// 撤銷：立即失效，紀錄保留
+	grp.DELETE("/:id", func(c *gin.Context) {
+		var k models.VendorAPIKey
+		if err := gdb.Where("id = ? AND vendor_id = ?", c.Param("id"), auth.VendorID(c)).First(&k).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				fail(c, http.StatusNotFound, "NOT_FOUND")
+				return
+			}
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		if k.RevokedAt == nil {
+			if err := gdb.Model(&k).Update("revoked_at", time.Now()).Error; err != nil {
+				fail(c, http.StatusInternalServerError, "DB_ERROR")
+				return
+			}
+		}
+		ok(c, gin.H{"apiKey": apiKeyJSON(&k)})
+	})
+}
@@ -1,141 +1,171 @@
 package routes
 
 import (
-	"os"
+	"errors"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"gorm.io/gorm"
-)
 
-func requireVendorMiddlewareForOrders() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			secret = "dev-secret"
-		}
-		tokenStr, _ := c.Cookie("vtoken")
-		if tokenStr == "" {
-			if auth := c.GetHeader("Authorization"); len(auth) > 7 && auth[:7] == "Bearer " {
-				tokenStr = auth[7:]
-			}
-		}
-		if tokenStr == "" {
-			c.AbortWithStatusJSON(401, gin.H{"ok": false, "error": "UNAUTHENTICATED"})
-			return
-		}
-		claims := jwt.MapClaims{}
-		parsed, err := jwt.ParseWithClaims(tokenStr, claims, func(t *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-		if err != nil || !parsed.Valid || claims["role"] != "vendor" {
-			c.AbortWithStatusJSON(401, gin.H{"ok": false, "error": "INVALID_TOKEN"})
-			return
-		}
-		c.Set("vendor_id", claims["id"])
-		c.Next()
-	}
-}
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/order"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/auth"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
 
 type VendorOrderItem struct {
-	ItemID    uint   `json:"itemId"`
-	OrderID   uint   `json:"orderId"`
-	ProductID uint   `json:"productId"`
-	Title     string `json:"title"`
-	Quantity  int    `json:"quantity"`
-	UnitPrice int64  `json:"unitPrice"`
-	Subtotal  int64  `json:"subtotal"`
-	Status    string `json:"status"`
+	ItemID     uint   `json:"itemId"`
+	OrderID    uint   `json:"orderId"`
+	ProductID  uint   `json:"productId"`
+	Title      string `json:"title"`
+	Quantity   int    `json:"quantity"`
+	UnitPrice  int64  `json:"unitPrice"`
+	Subtotal   int64  `json:"subtotal"`
+	Status     string `json:"status"`
+	TrackingNo string `json:"trackingNo"`
 }
 
+// 以廠商子訂單為單位回傳（主訂單資訊一併帶出，方便出貨）
 type VendorOrder struct {
-	OrderID   uint              `json:"orderId"`
-	Number    string            `json:"number"`
-	BuyerName string            `json:"buyerName"`
-	Phone     string            `json:"phone"`
-	Address   string            `json:"address"`
-	Status    string            `json:"status"`
-	Items     []VendorOrderItem `json:"items"`
+	OrderID     uint              `json:"orderId"`
+	Number      string            `json:"number"`
+	SubOrderID  uint              `json:"subOrderId"`
+	SubOrderNo  string            `json:"subOrderNo"`
+	BuyerName   string            `json:"buyerName"`
+	Phone       string            `json:"phone"`
+	Address     string            `json:"address"`
+	StoreCode   string            `json:"storeCode"`
+	Shipping    string            `json:"shippingMethod"`
+	Status      string            `json:"status"`
+	TrackingNo  string            `json:"trackingNo"`
+	ItemsTotal  int64             `json:"itemsTotal"`
+	ShippingFee int64             `json:"shippingFee"` // 平台目前不向買家收運費，子訂單不分攤，恆為 0
+	Total       int64             `json:"total"`
+	Items       []VendorOrderItem `json:"items"`
 }
 
-func RegisterVendorOrderRoutes(r *gin.Engine, gdb *gorm.DB) {
+func RegisterVendorOrderRoutes(r *gin.Engine, gdb *gorm.DB, va *auth.Auth) {
 	grp := r.Group("/api/vendor")
-	grp.Use(requireVendorMiddlewareForOrders())
+	grp.Use(va.MiddlewareWithAPIKey()) // 廠商 ERP 可用 API 金鑰拉訂單
 
-	grp.GET("/orders", func(c *gin.Context) {
-		vid := c.GetString("vendor_id")
+	// 員工 / API 金鑰需有查看或處理訂單的權限
+	grp.GET("/orders", auth.Require(models.PermOrdersView, models.PermOrdersFulfil), func(c *gin.Context) {
+		vid := auth.VendorID(c)
 
-		type row struct {
-			ItemID    uint
-			OrderID   uint
-			ProductID uint
-			Title     string
-			Quantity  int
-			UnitPrice int64
+		var subs []struct {
+			order.SubOrder
 			Number    string
 			BuyerName string
 			Phone     string
 			Address   string
-			Status    string
+			StoreCode string
 		}
-
-		var rows []row
-		err := gdb.Table("order_items AS oi").
-			Select(`oi.id AS item_id,
-			        oi.order_id,
-			        oi.product_id,
-			        p.name AS title,
-			        oi.quantity,
-			        oi.unit_price AS unit_price,
+		err := gdb.Table("sub_orders AS so").
+			Select(`so.*,
 			        o.order_no AS number,
 			        o.buyer_name,
 			        o.buyer_phone AS phone,
 			        o.address,
-			        o.status`).
-			Joins("JOIN products p ON p.id = oi.product_id").
-			Joins("JOIN orders o ON o.id = oi.order_id").
-			Where("p.vendor_id = ?", vid).
-			Order("o.id DESC, oi.id ASC").
-			Scan(&rows).Error
+			        o.store_code`).
+			Joins("JOIN orders o ON o.id = so.order_id").
+			Where("so.vendor_id = ?", vid).
+			Order("so.order_id DESC, so.id ASC").
+			Scan(&subs).Error
 		if err != nil {
 			c.JSON(500, gin.H{"ok": false, "error": "DB_ERROR"})
 			return
 		}
 
-		byOrder := map[uint]*VendorOrder{}
-		for _, r1 := range rows {
-			vo := byOrder[r1.OrderID]
-			if vo == nil {
-				vo = &VendorOrder{
-					OrderID:   r1.OrderID,
-					Number:    r1.Number,
-					BuyerName: r1.BuyerName,
-					Phone:     r1.Phone,
-					Address:   r1.Address,
-					Status:    r1.Status,
-				}
-				byOrder[r1.OrderID] = vo
+		ids := make([]uint64, 0, len(subs))
+		for _, so := range subs {
+			ids = append(ids, so.ID)
+		}
+		var items []order.OrderItem
+		if len(ids) > 0 {
+			if err := gdb.Where("sub_order_id IN ?", ids).Order("id ASC").Find(&items).Error; err != nil {
+				c.JSON(500, gin.H{"ok": false, "error": "DB_ERROR"})
+				return
 			}
-			sub := r1.UnitPrice * int64(r1.Quantity)
-			vo.Items = append(vo.Items, VendorOrderItem{
-				ItemID:    r1.ItemID,
-				OrderID:   r1.OrderID,
-				ProductID: r1.ProductID,
-				Title:     r1.Title,
-				Quantity:  r1.Quantity,
-				UnitPrice: r1.UnitPrice,
-				Subtotal:  sub,
-				Status:    r1.Status,
-			})
+		}
+		bySub := map[uint64][]order.OrderItem{}
+		for _, it := range items {
+			bySub[it.SubOrderID] = append(bySub[it.SubOrderID], it)
 		}
 
-		list := make([]*VendorOrder, 0, len(byOrder))
-		for _, v := range byOrder {
-			list = append(list, v)
+		list := make([]*VendorOrder, 0, len(subs))
+		for _, so := range subs {
+			vo := &VendorOrder{
+				OrderID:     uint(so.OrderID),
+				Number:      so.Number,
+				SubOrderID:  uint(so.ID),
+				SubOrderNo:  so.SubOrderNo,
+				BuyerName:   so.BuyerName,
+				Phone:       so.Phone,
+				Address:     so.Address,
+				StoreCode:   so.StoreCode,
+				Shipping:    string(so.ShippingMethod),
+				Status:      so.Status,
+				TrackingNo:  so.TrackingNo,
+				ItemsTotal:  so.ItemsTotal,
+				ShippingFee: so.ShippingFee,
+				Total:       so.Total,
+			}
+			for _, it := range bySub[so.ID] {
+				vo.Items = append(vo.Items, VendorOrderItem{
+					ItemID:     uint(it.ID),
+					OrderID:    uint(it.OrderID),
+					ProductID:  uint(it.ProductID),
+					Title:      it.ProductName,
+					Quantity:   it.Quantity,
+					UnitPrice:  it.UnitPrice,
+					Subtotal:   it.Subtotal,
+					Status:     it.Status,
+					TrackingNo: it.TrackingNo,
+				})
+			}
+			list = append(list, vo)
 		}
 		c.JSON(200, gin.H{"ok": true, "orders": list})
 	})
+
+	// 出貨作業：僅能操作自己商品的訂單項目
+	repo := order.NewRepo(gdb)
+	fulfil := func(action string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			vid := auth.VendorID(c)
+			itemID, _ := strconv.ParseUint(c.Param("itemId"), 10, 64)
+			var req struct {
+				TrackingNo string `json:"trackingNo"`
+				Note       string `json:"note"`
+			}
+			// body 可省略（確認 / 缺貨不一定需要）
+			if c.Request.ContentLength > 0 {
+				if err := c.ShouldBindJSON(&req); err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"ok": false, "error": "BAD_JSON"})
+					return
+				}
+			}
+			it, err := repo.VendorUpdateItem(vid, itemID, action, req.TrackingNo, req.Note)
+			if err != nil {
+				switch {
+				case errors.Is(err, order.ErrItemNotFound):
+					c.JSON(http.StatusNotFound, gin.H{"ok": false, "error": "NOT_FOUND"})
+				case errors.Is(err, order.ErrInvalidTransition):
+					c.JSON(http.StatusConflict, gin.H{"ok": false, "error": "INVALID_TRANSITION"})
+				case errors.Is(err, order.ErrTrackingRequired):
+					c.JSON(http.StatusBadRequest, gin.H{"ok": false, "error": "TRACKING_NO_REQUIRED"})
+				default:
+					c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": "DB_ERROR"})
+				}
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"ok": true, "item": it})
+		}
+	}
+	canFulfil := auth.Require(models.PermOrdersFulfil)
+	grp.POST("/orders/items/:itemId/acknowledge", canFulfil, fulfil(order.ActionAcknowledge))
+	grp.POST("/orders/items/:itemId/ship", canFulfil, fulfil(order.ActionShip))
+	grp.POST("/orders/items/:itemId/out-of-stock", canFulfil, fulfil(order.ActionOutOfStock))
 }
 
 // （小工具）uint 轉字串：若你之後想用 o.id 當字串顯示，可用 strconv
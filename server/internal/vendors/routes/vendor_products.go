@@ -5,25 +5,30 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
 	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/product"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/auth"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
 )
 
-const ctxKeyVendorID = "vendorID"
+// 供 main.go 呼叫：廠商商品管理（需登入或 API 金鑰）
+// moderated = true 時，新商品與重要欄位修改需經後台審核才會上線
+func RegisterVendorProductRoutes(r *gin.Engine, db *gorm.DB, moderated bool, va *auth.Auth) {
+	// 員工 / API 金鑰需有「商品管理」權限
+	grp := r.Group("/api/vendor", va.MiddlewareWithAPIKey(), auth.Require(models.PermProducts))
 
-// 供 main.go 呼叫：廠商商品管理（需登入）
-func RegisterVendorProductRoutes(r *gin.Engine, db *gorm.DB) {
-	grp := r.Group("/api/vendor")
+	// 異動商品前確認廠商已通過審核
+	approved := requireApprovedVendor(db)
 
 	// 上傳圖片（需登入）: POST /api/vendor/upload
-	grp.POST("/upload", requireVendor, func(c *gin.Context) {
+	grp.POST("/upload", approved, func(c *gin.Context) {
 		file, err := c.FormFile("file")
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "error": "NO_FILE"})
@@ -52,18 +57,19 @@ func RegisterVendorProductRoutes(r *gin.Engine, db *gorm.DB) {
 	})
 
 	// 新增商品（自動上架）
-	grp.POST("/products", requireVendor, func(c *gin.Context) {
-		vendorID := c.GetString(ctxKeyVendorID)
+	grp.POST("/products", approved, func(c *gin.Context) {
+		vendorID := auth.VendorID(c)
 
 		var req struct {
-			Name        string `json:"name"`
-			Category    string `json:"category"`
-			Price       int64  `json:"price"` // 與 model 對齊
-			Stock       int    `json:"stock"`
-			Description string `json:"description"`
-			ImageURL    string `json:"imageUrl"`
-			Spec        string `json:"spec"`
-			IsActive    *bool  `json:"isActive"` // 允許覆寫
+			Name        string   `json:"name"`
+			Category    string   `json:"category"`
+			Price       int64    `json:"price"` // 與 model 對齊
+			Stock       int      `json:"stock"`
+			Description string   `json:"description"`
+			ImageURL    string   `json:"imageUrl"`
+			Images      []string `json:"images"` // 圖庫（依順序）
+			Spec        string   `json:"spec"`
+			IsActive    *bool    `json:"isActive"` // 允許覆寫
 		}
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "error": "BAD_JSON"})
@@ -107,30 +113,55 @@ func RegisterVendorProductRoutes(r *gin.Engine, db *gorm.DB) {
 			IsActive: active,
 		}
 
-		if err := db.WithContext(c.Request.Context()).Create(p).Error; err != nil {
+		// 審核模式：先建立下架中的商品，送審通過後才依 isActive 上架
+		var rev *product.ProductRevision
+		if moderated {
+			p.Visible, p.IsActive = false, false
+			p.ReviewStatus = product.ReviewPending
+		}
+		if err := db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(p).Error; err != nil {
+				return err
+			}
+			if !moderated {
+				return product.SetImages(tx, p.ID, req.Images)
+			}
+			rev = &product.ProductRevision{
+				ProductID:   p.ID,
+				VendorID:    vendorID,
+				Kind:        product.RevisionCreate,
+				Name:        p.Name,
+				Price:       p.Price,
+				ImageURL:    p.ImageURL,
+				Images:      append([]string{}, req.Images...),
+				Description: p.Description,
+				Publish:     active,
+			}
+			return product.SubmitRevision(tx, rev)
+		}); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": "DB_CREATE_FAIL"})
 			return
 		}
-		c.JSON(http.StatusCreated, gin.H{"ok": true, "product": p})
+		c.JSON(http.StatusCreated, gin.H{"ok": true, "product": p, "revision": rev})
 	})
 
 	// 取得我的商品列表（登入廠商）
-	grp.GET("/products", requireVendor, func(c *gin.Context) {
-		vendorID := c.GetString(ctxKeyVendorID)
+	grp.GET("/products", func(c *gin.Context) {
+		vendorID := auth.VendorID(c)
 		var rows []product.Product
 		if err := db.WithContext(c.Request.Context()).
 			Where("vendor_id = ?", vendorID).
 			Order("id DESC").
 			Find(&rows).Error; err != nil {
-		 c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": "DB_ERROR"})
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": "DB_ERROR"})
 			return
 		}
 		c.JSON(http.StatusOK, gin.H{"ok": true, "products": rows})
 	})
 
 	// 單筆讀取（登入廠商，僅能看自己的）
-	grp.GET("/products/:id", requireVendor, func(c *gin.Context) {
-		vendorID := c.GetString(ctxKeyVendorID)
+	grp.GET("/products/:id", func(c *gin.Context) {
+		vendorID := auth.VendorID(c)
 		var p product.Product
 		if err := db.WithContext(c.Request.Context()).
 			Where("vendor_id = ?", vendorID).
@@ -142,12 +173,17 @@ func RegisterVendorProductRoutes(r *gin.Engine, db *gorm.DB) {
 			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": "DB_ERROR"})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"ok": true, "product": p})
+		images, err := product.Images(db.WithContext(c.Request.Context()), p.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": "DB_ERROR"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true, "product": p, "images": images})
 	})
 
 	// 更新（可切換上/下架）
-	grp.PUT("/products/:id", requireVendor, func(c *gin.Context) {
-		vendorID := c.GetString(ctxKeyVendorID)
+	grp.PUT("/products/:id", approved, func(c *gin.Context) {
+		vendorID := auth.VendorID(c)
 
 		var p product.Product
 		if err := db.WithContext(c.Request.Context()).
@@ -162,21 +198,24 @@ func RegisterVendorProductRoutes(r *gin.Engine, db *gorm.DB) {
 		}
 
 		var req struct {
-			Name        *string `json:"name"`
-			Category    *string `json:"category"`
-			Price       *int64  `json:"price"`
-			Stock       *int    `json:"stock"`
-			Description *string `json:"description"`
-			ImageURL    *string `json:"imageUrl"`
-			Spec        *string `json:"spec"`
-			Visible     *bool   `json:"visible"`
-			IsActive    *bool   `json:"isActive"`
+			Name        *string   `json:"name"`
+			Category    *string   `json:"category"`
+			Price       *int64    `json:"price"`
+			Stock       *int      `json:"stock"`
+			Description *string   `json:"description"`
+			ImageURL    *string   `json:"imageUrl"`
+			Images      *[]string `json:"images"` // 圖庫（依順序）；未帶 = 不變
+			Spec        *string   `json:"spec"`
+			Visible     *bool     `json:"visible"`
+			IsActive    *bool     `json:"isActive"`
 		}
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"ok": false, "error": "BAD_JSON"})
 			return
 		}
 
+		// 審核模式：名稱、價格、主圖 / 圖庫、描述寫入送審版本，線上維持原值
+		live := p
 		if req.Name != nil {
 			p.Name = *req.Name
 		}
@@ -214,16 +253,77 @@ func RegisterVendorProductRoutes(r *gin.Engine, db *gorm.DB) {
 			p.IsActive = *req.IsActive
 		}
 
-		if err := db.WithContext(c.Request.Context()).Save(&p).Error; err != nil {
+		var rev *product.ProductRevision
+		if err := db.WithContext(c.Request.Context()).Transaction(func(tx *gorm.DB) error {
+			if !moderated {
+				if err := tx.Save(&p).Error; err != nil {
+					return err
+				}
+				if req.Images == nil {
+					return nil
+				}
+				return product.SetImages(tx, p.ID, *req.Images)
+			}
+			liveImages, err := product.Images(tx, p.ID)
+			if err != nil {
+				return err
+			}
+			images := liveImages
+			if req.Images != nil {
+				images = append([]string{}, *req.Images...)
+			}
+			proposed := &product.ProductRevision{
+				ProductID:   p.ID,
+				VendorID:    vendorID,
+				Kind:        product.RevisionUpdate,
+				Name:        p.Name,
+				Price:       p.Price,
+				ImageURL:    p.ImageURL,
+				Images:      images,
+				Description: p.Description,
+				Publish:     p.Visible || p.IsActive,
+			}
+			p.Name, p.Price, p.ImageURL, p.Description = live.Name, live.Price, live.ImageURL, live.Description
+
+			if !live.Approved() {
+				// 尚未核准過（審核中 / 被退回）：整份重新送審，核准前維持下架
+				proposed.Kind = product.RevisionCreate
+				p.Visible, p.IsActive = false, false
+				p.ReviewStatus = product.ReviewPending
+				rev = proposed
+			} else if live.MaterialChanged(proposed, liveImages) {
+				rev = proposed
+			}
+			if err := tx.Save(&p).Error; err != nil {
+				return err
+			}
+			if rev == nil {
+				return nil
+			}
+			return product.SubmitRevision(tx, rev)
+		}); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": "DB_UPDATE_FAIL"})
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"ok": true, "product": p})
+		product.CheckLowStock(&p, live.Stock)
+		c.JSON(http.StatusOK, gin.H{"ok": true, "product": p, "revision": rev})
+	})
+
+	// 我的送審紀錄 ?productId=&status=
+	grp.GET("/product-reviews", func(c *gin.Context) {
+		pid, _ := strconv.ParseUint(c.Query("productId"), 10, 64)
+		list, err := product.NewRepo(db, nil).ListRevisions(c.Request.Context(),
+			auth.VendorID(c), strings.TrimSpace(c.Query("status")), pid)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": "DB_ERROR"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true, "revisions": list})
 	})
 
 	// 刪除（僅限自己）
-	grp.DELETE("/products/:id", requireVendor, func(c *gin.Context) {
-		vendorID := c.GetString(ctxKeyVendorID)
+	grp.DELETE("/products/:id", func(c *gin.Context) {
+		vendorID := auth.VendorID(c)
 		if err := db.WithContext(c.Request.Context()).
 			Where("vendor_id = ?", vendorID).
 			Delete(&product.Product{}, c.Param("id")).Error; err != nil {
@@ -236,34 +336,22 @@ func RegisterVendorProductRoutes(r *gin.Engine, db *gorm.DB) {
 
 // ==== helpers ====
 
-// 讀取 vtoken cookie 驗證，寫入 vendorID 到 context
-func requireVendor(c *gin.Context) {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "INVALID_TOKEN"})
-		return
-	}
-	tokenStr, err := c.Cookie("vtoken")
-	if err != nil || tokenStr == "" {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "INVALID_TOKEN"})
-		return
-	}
-	tkn, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
-	if err != nil || !tkn.Valid {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "INVALID_TOKEN"})
-		return
-	}
-	claims, _ := tkn.Claims.(jwt.MapClaims)
-	idRaw := claims["id"]
-	idStr, _ := idRaw.(string)
-	if idStr == "" {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "INVALID_TOKEN"})
-		return
+// 尚未核准（審核中 / 退回 / 停權）的廠商不可新增或修改商品
+func requireApprovedVendor(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var v models.Vendor
+		if err := db.WithContext(c.Request.Context()).
+			Select("id", "status").
+			First(&v, "id = ?", auth.VendorID(c)).Error; err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"ok": false, "error": "INVALID_TOKEN"})
+			return
+		}
+		if v.Status != models.StatusApproved {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"ok": false, "error": "VENDOR_NOT_APPROVED", "status": v.Status})
+			return
+		}
+		c.Next()
 	}
-	c.Set(ctxKeyVendorID, idStr)
-	c.Next()
 }
 
 func normalizeCategory(cat string) string {
@@ -0,0 +1,205 @@
+package routes
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/invoice"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/secretbox"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/auth"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
+
+var (
+	rePhone       = regexp.MustCompile(`^[0-9+\-() #]{8,20}$`)
+	reBankCode    = regexp.MustCompile(`^[0-9]{3}$`)
+	reBankAccount = regexp.MustCompile(`^[0-9]{6,16}$`)
+)
+
+// 對外輸出的廠商資料（銀行帳號只露末四碼）
+func profileJSON(v *models.Vendor) gin.H {
+	return gin.H{
+		"id":               v.ID,
+		"email":            v.Email,
+		"name":             v.Name,
+		"slug":             v.SlugValue(),
+		"companyName":      v.CompanyName,
+		"taxId":            v.TaxID,
+		"contactPhone":     v.ContactPhone,
+		"returnAddress":    v.ReturnAddress,
+		"logoUrl":          v.LogoURL,
+		"description":      v.Description,
+		"bankCode":         v.BankCode,
+		"bankBranch":       v.BankBranch,
+		"bankAccountName":  v.BankAccountName,
+		"bankAccountLast4": v.BankAccountLast4,
+		"bankReview":       v.BankReview,
+		"bankReviewNote":   v.BankReviewNote,
+		"bankUpdatedAt":    v.BankUpdatedAt,
+	}
+}
+
+// 廠商：商業資料 / 撥款帳戶（只有廠商本人）
+func RegisterVendorProfileRoutes(r *gin.Engine, gdb *gorm.DB, box *secretbox.Box, va *auth.Auth) {
+	grp := r.Group("/api/vendor/profile")
+	grp.Use(va.Middleware(), auth.OwnerOnly())
+
+	grp.GET("", func(c *gin.Context) {
+		var v models.Vendor
+		if err := gdb.First(&v, "id = ?", auth.VendorID(c)).Error; err != nil {
+			fail(c, http.StatusNotFound, "NOT_FOUND")
+			return
+		}
+		ok(c, gin.H{"profile": profileJSON(&v)})
+	})
+
+	grp.PUT("", func(c *gin.Context) {
+		var req struct {
+			Name            *string `json:"name"`
+			Slug            *string `json:"slug"`
+			CompanyName     *string `json:"companyName"`
+			TaxID           *string `json:"taxId"`
+			ContactPhone    *string `json:"contactPhone"`
+			ReturnAddress   *string `json:"returnAddress"`
+			LogoURL         *string `json:"logoUrl"`
+			Description     *string `json:"description"`
+			BankCode        *string `json:"bankCode"`
+			BankBranch      *string `json:"bankBranch"`
+			BankAccountName *string `json:"bankAccountName"`
+			BankAccount     *string `json:"bankAccount"` // 完整帳號；只寫不讀
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			fail(c, http.StatusBadRequest, "BAD_JSON")
+			return
+		}
+
+		var v models.Vendor
+		if err := gdb.First(&v, "id = ?", auth.VendorID(c)).Error; err != nil {
+			fail(c, http.StatusNotFound, "NOT_FOUND")
+			return
+		}
+
+		// max：欄位的 size（字元數），0 = 不限；超過時回 400 <欄位>_TOO_LONG
+		updates := map[string]any{}
+		tooLong := ""
+		set := func(col string, max int, p *string, dst *string) {
+			if p == nil {
+				return
+			}
+			*dst = strings.TrimSpace(*p)
+			updates[col] = *dst
+			if max > 0 && utf8.RuneCountInString(*dst) > max && tooLong == "" {
+				tooLong = strings.ToUpper(col) + "_TOO_LONG"
+			}
+		}
+		set("name", 190, req.Name, &v.Name)
+		set("company_name", 190, req.CompanyName, &v.CompanyName)
+		set("contact_phone", 0, req.ContactPhone, &v.ContactPhone) // 格式檢查已限制長度
+		set("return_address", 255, req.ReturnAddress, &v.ReturnAddress)
+		set("logo_url", 500, req.LogoURL, &v.LogoURL)
+		set("description", 0, req.Description, &v.Description)
+		set("tax_id", 0, req.TaxID, &v.TaxID) // 格式檢查已限制長度
+		if tooLong != "" {
+			fail(c, http.StatusBadRequest, tooLong)
+			return
+		}
+
+		if req.Slug != nil {
+			slug := strings.ToLower(strings.TrimSpace(*req.Slug))
+			if !models.ReSlug.MatchString(slug) {
+				fail(c, http.StatusBadRequest, "INVALID_SLUG")
+				return
+			}
+			if slug != v.SlugValue() {
+				var n int64
+				if err := gdb.Model(&models.Vendor{}).Where("slug = ? AND id <> ?", slug, v.ID).Count(&n).Error; err != nil {
+					fail(c, http.StatusInternalServerError, "DB_ERROR")
+					return
+				}
+				if n > 0 {
+					fail(c, http.StatusConflict, "SLUG_TAKEN")
+					return
+				}
+				v.Slug = &slug
+				updates["slug"] = slug
+			}
+		}
+		if v.TaxID != "" && !invoice.ValidTaxID(v.TaxID) {
+			fail(c, http.StatusBadRequest, "INVALID_TAX_ID")
+			return
+		}
+		if v.ContactPhone != "" && !rePhone.MatchString(v.ContactPhone) {
+			fail(c, http.StatusBadRequest, "INVALID_PHONE")
+			return
+		}
+
+		// 撥款帳戶：任何一欄變動都要重新審核
+		bankChanged := false
+		bankSet := func(col string, p *string, dst *string) {
+			if p == nil {
+				return
+			}
+			val := strings.TrimSpace(*p)
+			if val != *dst {
+				bankChanged = true
+			}
+			*dst = val
+			updates[col] = val
+		}
+		bankSet("bank_code", req.BankCode, &v.BankCode)
+		bankSet("bank_branch", req.BankBranch, &v.BankBranch)
+		bankSet("bank_account_name", req.BankAccountName, &v.BankAccountName)
+		if v.BankCode != "" && !reBankCode.MatchString(v.BankCode) {
+			fail(c, http.StatusBadRequest, "INVALID_BANK_CODE")
+			return
+		}
+		if utf8.RuneCountInString(v.BankBranch) > 64 {
+			fail(c, http.StatusBadRequest, "BANK_BRANCH_TOO_LONG")
+			return
+		}
+		if utf8.RuneCountInString(v.BankAccountName) > 190 {
+			fail(c, http.StatusBadRequest, "BANK_ACCOUNT_NAME_TOO_LONG")
+			return
+		}
+		if req.BankAccount != nil {
+			acct := strings.NewReplacer("-", "", " ", "").Replace(*req.BankAccount)
+			if !reBankAccount.MatchString(acct) {
+				fail(c, http.StatusBadRequest, "INVALID_BANK_ACCOUNT")
+				return
+			}
+			current, _ := box.Open(v.BankAccountEnc)
+			if acct != current {
+				enc, err := box.Seal(acct)
+				if err != nil {
+					fail(c, http.StatusInternalServerError, "ENCRYPT_FAIL")
+					return
+				}
+				v.BankAccountEnc, v.BankAccountLast4 = enc, acct[len(acct)-4:]
+				updates["bank_account_enc"] = enc
+				updates["bank_account_last4"] = v.BankAccountLast4
+				bankChanged = true
+			}
+		}
+		if bankChanged {
+			now := time.Now()
+			v.BankReview, v.BankReviewNote, v.BankUpdatedAt = models.BankReviewPending, "", &now
+			updates["bank_review"] = v.BankReview
+			updates["bank_review_note"] = ""
+			updates["bank_updated_at"] = now
+		}
+
+		if len(updates) > 0 {
+			if err := gdb.Model(&models.Vendor{}).Where("id = ?", v.ID).Updates(updates).Error; err != nil {
+				fail(c, http.StatusInternalServerError, "DB_ERROR")
+				return
+			}
+		}
+		ok(c, gin.H{"profile": profileJSON(&v)})
+	})
+}
@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/product"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
+
+// 前台：廠商店家頁（僅限已核准且啟用的廠商）
+func RegisterVendorPublicRoutes(r *gin.Engine, gdb *gorm.DB, ph *product.Handler) {
+	grp := r.Group("/api/vendors")
+
+	load := func(c *gin.Context) *models.Vendor {
+		var v models.Vendor
+		if err := gdb.WithContext(c.Request.Context()).
+			Where("slug = ? AND status = ? AND is_active = ?", c.Param("slug"), models.StatusApproved, true).
+			First(&v).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"ok": false, "error": "NOT_FOUND"})
+			return nil
+		}
+		return &v
+	}
+
+	// GET /api/vendors/:slug
+	grp.GET("/:slug", func(c *gin.Context) {
+		v := load(c)
+		if v == nil {
+			return
+		}
+		count, err := ph.CountPublic(c.Request.Context(), v.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": "DB_ERROR"})
+			return
+		}
+		name := v.CompanyName
+		if v.Name != "" {
+			name = v.Name
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true, "vendor": gin.H{
+			"slug":         v.SlugValue(),
+			"name":         name,
+			"logoUrl":      v.LogoURL,
+			"description":  v.Description,
+			"rating":       v.RatingAvg,
+			"ratingCount":  v.RatingCount,
+			"productCount": count,
+			"since":        v.CreatedAt,
+		}})
+	})
+
+	// GET /api/vendors/:slug/products（篩選 / 排序 / 分頁同 /api/products；只列上架中的商品）
+	grp.GET("/:slug/products", func(c *gin.Context) {
+		if v := load(c); v != nil {
+			ph.ListByVendor(c, v.ID)
+		}
+	})
+}
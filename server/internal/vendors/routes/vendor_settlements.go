@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/settlement"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/auth"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
+
+// 廠商：查看自己的結算單 / 下載 CSV
+func RegisterVendorSettlementRoutes(r *gin.Engine, svc *settlement.Service, va *auth.Auth) {
+	grp := r.Group("/api/vendor/settlements")
+	grp.Use(va.Middleware(), auth.Require(models.PermSettlements))
+
+	// 草稿仍可能被後台退回重算，廠商只看得到核准後的結算單
+	visible := func(st *settlement.Statement) bool {
+		return st.Status != settlement.StatusDraft
+	}
+
+	grp.GET("", func(c *gin.Context) {
+		list, err := svc.List(c.Request.Context(), auth.VendorID(c), "")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": "DB_ERROR"})
+			return
+		}
+		out := make([]settlement.Statement, 0, len(list))
+		for i := range list {
+			if visible(&list[i]) {
+				out = append(out, list[i])
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"ok": true, "statements": out})
+	})
+
+	load := func(c *gin.Context) *settlement.Statement {
+		id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+		st, err := svc.Get(c.Request.Context(), id, auth.VendorID(c))
+		if err == nil && !visible(st) {
+			err = gorm.ErrRecordNotFound
+		}
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"ok": false, "error": "NOT_FOUND"})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{"ok": false, "error": "DB_ERROR"})
+			}
+			return nil
+		}
+		return st
+	}
+
+	grp.GET("/:id", func(c *gin.Context) {
+		if st := load(c); st != nil {
+			c.JSON(http.StatusOK, gin.H{"ok": true, "statement": st})
+		}
+	})
+
+	grp.GET("/:id/csv", func(c *gin.Context) {
+		if st := load(c); st != nil {
+			settlement.SendCSV(c, st)
+		}
+	})
+}
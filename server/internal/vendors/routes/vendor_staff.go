@@ -0,0 +1,345 @@
+package routes
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/mail"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/auth"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
+
+var (
+	errInviteInvalid = errors.New("invalid invite token")
+	errInviteExpired = errors.New("invite token expired")
+)
+
+func staffJSON(s *models.VendorStaff) gin.H {
+	return gin.H{
+		"id":              s.ID,
+		"email":           s.Email,
+		"name":            s.Name,
+		"permissions":     s.PermissionList(),
+		"active":          s.IsActive,
+		"accepted":        s.Accepted(),
+		"inviteExpiresAt": s.InviteExpiresAt,
+		"lastLoginAt":     s.LastLoginAt,
+		"twoFactor":       s.TOTP.Enabled(),
+		"createdAt":       s.CreatedAt,
+	}
+}
+
+// staffPermissions：檢查並去除重複，回傳逗號分隔的字串
+func staffPermissions(in []string) (string, bool) {
+	seen := map[string]bool{}
+	out := make([]string, 0, len(in))
+	for _, p := range in {
+		p = strings.TrimSpace(p)
+		if !models.ValidStaffPermission(p) {
+			return "", false
+		}
+		if !seen[p] {
+			seen[p] = true
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, ","), true
+}
+
+// 廠商：員工子帳號（邀請 / 權限 / 停用），以及員工接受邀請
+func RegisterVendorStaffRoutes(r *gin.Engine, gdb *gorm.DB, va *auth.Auth, ms *mail.Service, baseURL string) {
+	// 寄出邀請信：產生新的邀請連結（先前的連結作廢）
+	invite := func(c *gin.Context, s *models.VendorStaff) error {
+		token, hash := auth.NewInviteToken()
+		exp := time.Now().Add(auth.InviteTTL)
+		if err := gdb.Model(s).Updates(map[string]any{"invite_hash": hash, "invite_expires_at": exp}).Error; err != nil {
+			return err
+		}
+		s.InviteHash, s.InviteExpiresAt = hash, &exp
+		var v models.Vendor
+		if err := gdb.Select("id,name").First(&v, "id = ?", s.VendorID).Error; err != nil {
+			return err
+		}
+		link := baseURL + "/vendor/invite?token=" + url.QueryEscape(token)
+		if err := ms.StaffInvite(s.Email, s.Name, v.Name, link, auth.InviteTTL); err != nil {
+			log.Printf("vendor staff invite mail: %v", err)
+		}
+		return nil
+	}
+
+	grp := r.Group("/api/vendor/staff", va.Middleware(), auth.OwnerOnly())
+
+	// 員工列表，並附上可指派的權限
+	grp.GET("", func(c *gin.Context) {
+		var list []models.VendorStaff
+		if err := gdb.Where("vendor_id = ?", auth.VendorID(c)).Order("created_at").Find(&list).Error; err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		items := make([]gin.H, 0, len(list))
+		for i := range list {
+			items = append(items, staffJSON(&list[i]))
+		}
+		ok(c, gin.H{"items": items, "permissions": models.StaffPermissions})
+	})
+
+	// 邀請員工 {email, name, permissions}
+	grp.POST("", func(c *gin.Context) {
+		var req struct {
+			Email       string   `json:"email" binding:"required,email"`
+			Name        string   `json:"name"`
+			Permissions []string `json:"permissions"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			fail(c, http.StatusBadRequest, "INVALID_INPUT")
+			return
+		}
+		perms, valid := staffPermissions(req.Permissions)
+		if !valid {
+			fail(c, http.StatusBadRequest, "INVALID_PERMISSION")
+			return
+		}
+		// 廠商本人與員工共用登入 Email，兩邊都不可重複
+		var count int64
+		gdb.Model(&models.Vendor{}).Where("email = ?", req.Email).Count(&count)
+		if count == 0 {
+			gdb.Model(&models.VendorStaff{}).Where("email = ?", req.Email).Count(&count)
+		}
+		if count > 0 {
+			fail(c, http.StatusConflict, "EMAIL_EXISTS")
+			return
+		}
+		s := &models.VendorStaff{
+			ID:          uuid.NewString(),
+			VendorID:    auth.VendorID(c),
+			Email:       req.Email,
+			Name:        strings.TrimSpace(req.Name),
+			Permissions: perms,
+			IsActive:    true,
+		}
+		if err := gdb.Create(s).Error; err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		if err := invite(c, s); err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		ok(c, gin.H{"staff": staffJSON(s)})
+	})
+
+	load := func(c *gin.Context) (*models.VendorStaff, bool) {
+		var s models.VendorStaff
+		if err := gdb.Where("id = ? AND vendor_id = ?", c.Param("id"), auth.VendorID(c)).First(&s).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				fail(c, http.StatusNotFound, "NOT_FOUND")
+			} else {
+				fail(c, http.StatusInternalServerError, "DB_ERROR")
+			}
+			return nil, false
+		}
+		return &s, true
+	}
+
+	// 修改 {name?, permissions?, active?}；權限或啟用狀態變更時該員工所有裝置登出
+	grp.PUT("/:id", func(c *gin.Context) {
+		var req struct {
+			Name        *string   `json:"name"`
+			Permissions *[]string `json:"permissions"`
+			Active      *bool     `json:"active"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			fail(c, http.StatusBadRequest, "INVALID_INPUT")
+			return
+		}
+		s, found := load(c)
+		if !found {
+			return
+		}
+		cols := map[string]any{}
+		revoke := false
+		if req.Name != nil {
+			s.Name = strings.TrimSpace(*req.Name)
+			cols["name"] = s.Name
+		}
+		if req.Permissions != nil {
+			perms, valid := staffPermissions(*req.Permissions)
+			if !valid {
+				fail(c, http.StatusBadRequest, "INVALID_PERMISSION")
+				return
+			}
+			cols["permissions"] = perms
+			revoke = revoke || perms != s.Permissions
+			s.Permissions = perms
+		}
+		if req.Active != nil {
+			cols["is_active"] = *req.Active
+			revoke = revoke || *req.Active != s.IsActive
+			s.IsActive = *req.Active
+		}
+		if len(cols) > 0 {
+			if err := gdb.Model(&models.VendorStaff{}).Where("id = ?", s.ID).Updates(cols).Error; err != nil {
+				fail(c, http.StatusInternalServerError, "DB_ERROR")
+				return
+			}
+		}
+		if revoke {
+			if err := va.RevokeAll(c.Request.Context(), s.VendorID, s.ID, ""); err != nil {
+				fail(c, http.StatusInternalServerError, "DB_ERROR")
+				return
+			}
+		}
+		ok(c, gin.H{"staff": staffJSON(s)})
+	})
+
+	// 重寄邀請信（已接受的員工可藉此重新設定密碼）
+	grp.POST("/:id/invite", func(c *gin.Context) {
+		s, found := load(c)
+		if !found {
+			return
+		}
+		if !s.IsActive {
+			fail(c, http.StatusConflict, "STAFF_DISABLED")
+			return
+		}
+		if err := invite(c, s); err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		ok(c, gin.H{"staff": staffJSON(s)})
+	})
+
+	// 刪除員工：所有裝置登出
+	grp.DELETE("/:id", func(c *gin.Context) {
+		s, found := load(c)
+		if !found {
+			return
+		}
+		if err := va.RevokeAll(c.Request.Context(), s.VendorID, s.ID, ""); err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		if err := gdb.Delete(s).Error; err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		ok(c, nil)
+	})
+
+	// 以邀請連結的 token 找員工（含過期檢查）
+	findInvite := func(tx *gorm.DB, token string) (*models.VendorStaff, error) {
+		var s models.VendorStaff
+		if token == "" {
+			return nil, errInviteInvalid
+		}
+		if err := tx.Where("invite_hash = ? AND is_active = 1", auth.InviteHash(token)).First(&s).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, errInviteInvalid
+			}
+			return nil, err
+		}
+		if s.InviteExpiresAt == nil || time.Now().After(*s.InviteExpiresAt) {
+			return nil, errInviteExpired
+		}
+		return &s, nil
+	}
+	inviteFail := func(c *gin.Context, err error) {
+		switch {
+		case errors.Is(err, errInviteInvalid):
+			fail(c, http.StatusBadRequest, "INVALID_TOKEN")
+		case errors.Is(err, errInviteExpired):
+			fail(c, http.StatusBadRequest, "TOKEN_EXPIRED")
+		default:
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+		}
+	}
+
+	pub := r.Group("/api/vendor/invite")
+
+	// 邀請內容 ?token=
+	pub.GET("", func(c *gin.Context) {
+		s, err := findInvite(gdb, c.Query("token"))
+		if err != nil {
+			inviteFail(c, err)
+			return
+		}
+		var v models.Vendor
+		if err := gdb.Select("id,name").Where("id = ? AND is_active = 1", s.VendorID).First(&v).Error; err != nil {
+			inviteFail(c, errInviteInvalid)
+			return
+		}
+		ok(c, gin.H{"invite": gin.H{"email": s.Email, "name": s.Name, "vendorName": v.Name}})
+	})
+
+	// 接受邀請 {token, password, name}：設定密碼後直接登入（連結只能用一次）
+	pub.POST("/accept", func(c *gin.Context) {
+		var req struct {
+			Token    string `json:"token" binding:"required"`
+			Password string `json:"password" binding:"required,min=8"`
+			Name     string `json:"name"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			fail(c, http.StatusBadRequest, "INVALID_INPUT")
+			return
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), 12)
+		if err != nil {
+			fail(c, http.StatusInternalServerError, "SERVER_ERROR")
+			return
+		}
+		var s *models.VendorStaff
+		err = gdb.Transaction(func(tx *gorm.DB) error {
+			var err error
+			if s, err = findInvite(tx.Clauses(clause.Locking{Strength: "UPDATE"}), req.Token); err != nil {
+				return err
+			}
+			cols := map[string]any{
+				"password_hash":     string(hash),
+				"invite_hash":       "",
+				"invite_expires_at": nil,
+			}
+			if s.AcceptedAt == nil {
+				cols["accepted_at"] = time.Now()
+			}
+			if name := strings.TrimSpace(req.Name); name != "" {
+				cols["name"] = name
+			}
+			return tx.Model(s).Updates(cols).Error
+		})
+		if err != nil {
+			inviteFail(c, err)
+			return
+		}
+		// 重新設定密碼：其他裝置一律登出
+		ctx := c.Request.Context()
+		if err := va.RevokeAll(ctx, s.VendorID, s.ID, ""); err != nil {
+			fail(c, http.StatusInternalServerError, "DB_ERROR")
+			return
+		}
+		acct, err := loadAccount(gdb, s.VendorID, s.ID)
+		if err != nil {
+			inviteFail(c, errInviteInvalid)
+			return
+		}
+		// 已停權或已啟用雙因素驗證：改走一般登入
+		if acct.vendor.Status == models.StatusSuspended || acct.factor().Enabled() {
+			ok(c, gin.H{"loginRequired": true})
+			return
+		}
+		if _, err := va.Login(c, acct.principal()); err != nil {
+			fail(c, http.StatusInternalServerError, "TOKEN_ERROR")
+			return
+		}
+		ok(c, gin.H{"vendor": acct.loggedIn(gdb)})
+	})
+}
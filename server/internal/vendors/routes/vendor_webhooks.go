@@ -0,0 +1,137 @@
+package routes
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/events"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/auth"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/webhook"
+)
+
+// webhookFail：webhook 錯誤 → 回應代碼
+func webhookFail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, webhook.ErrNotFound):
+		fail(c, http.StatusNotFound, "NOT_FOUND")
+	case errors.Is(err, webhook.ErrInvalidURL):
+		fail(c, http.StatusBadRequest, "INVALID_URL")
+	case errors.Is(err, webhook.ErrHTTPSRequired):
+		fail(c, http.StatusBadRequest, "HTTPS_REQUIRED")
+	case errors.Is(err, webhook.ErrInvalidEvent):
+		fail(c, http.StatusBadRequest, "INVALID_EVENT")
+	case errors.Is(err, webhook.ErrTooMany):
+		fail(c, http.StatusConflict, "TOO_MANY_WEBHOOKS")
+	default:
+		fail(c, http.StatusInternalServerError, "SERVER_ERROR")
+	}
+}
+
+// 廠商：webhook 訂閱與投遞紀錄（只有廠商本人）；只會收到自己的子訂單與商品事件
+func RegisterVendorWebhookRoutes(r *gin.Engine, svc *webhook.Service, va *auth.Auth) {
+	grp := r.Group("/api/vendor", va.Middleware(), auth.OwnerOnly())
+
+	id := func(c *gin.Context) uint64 {
+		n, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+		return n
+	}
+
+	// 訂閱列表，並附上可訂閱的事件
+	grp.GET("/webhooks", func(c *gin.Context) {
+		list, err := svc.Subscriptions(c.Request.Context(), auth.VendorID(c))
+		if err != nil {
+			webhookFail(c, err)
+			return
+		}
+		items := make([]gin.H, 0, len(list))
+		for i := range list {
+			items = append(items, webhook.SubscriptionJSON(&list[i]))
+		}
+		ok(c, gin.H{"items": items, "events": events.Types})
+	})
+
+	// 新增 {url, description, events} → 回傳簽章金鑰（只顯示一次）
+	grp.POST("/webhooks", func(c *gin.Context) {
+		var in webhook.SubscriptionInput
+		if err := c.ShouldBindJSON(&in); err != nil {
+			fail(c, http.StatusBadRequest, "INVALID_INPUT")
+			return
+		}
+		sub, secret, err := svc.CreateSubscription(c.Request.Context(), auth.VendorID(c), in)
+		if err != nil {
+			webhookFail(c, err)
+			return
+		}
+		ok(c, gin.H{"subscription": webhook.SubscriptionJSON(sub), "secret": secret})
+	})
+
+	// 修改 {url, description, events, active}
+	grp.PUT("/webhooks/:id", func(c *gin.Context) {
+		var in webhook.SubscriptionInput
+		if err := c.ShouldBindJSON(&in); err != nil {
+			fail(c, http.StatusBadRequest, "INVALID_INPUT")
+			return
+		}
+		sub, err := svc.UpdateSubscription(c.Request.Context(), auth.VendorID(c), id(c), in)
+		if err != nil {
+			webhookFail(c, err)
+			return
+		}
+		ok(c, gin.H{"subscription": webhook.SubscriptionJSON(sub)})
+	})
+
+	grp.DELETE("/webhooks/:id", func(c *gin.Context) {
+		if err := svc.DeleteSubscription(c.Request.Context(), auth.VendorID(c), id(c)); err != nil {
+			webhookFail(c, err)
+			return
+		}
+		ok(c, nil)
+	})
+
+	// 更換簽章金鑰
+	grp.POST("/webhooks/:id/rotate-secret", func(c *gin.Context) {
+		secret, err := svc.RotateSecret(c.Request.Context(), auth.VendorID(c), id(c))
+		if err != nil {
+			webhookFail(c, err)
+			return
+		}
+		ok(c, gin.H{"secret": secret})
+	})
+
+	// 送出測試事件（ping）
+	grp.POST("/webhooks/:id/ping", func(c *gin.Context) {
+		d, err := svc.Ping(c.Request.Context(), auth.VendorID(c), id(c))
+		if err != nil {
+			webhookFail(c, err)
+			return
+		}
+		ok(c, gin.H{"delivery": d})
+	})
+
+	// 投遞紀錄 ?subscriptionId=&status=&event=&limit=&offset=
+	grp.GET("/webhook-deliveries", func(c *gin.Context) {
+		q := webhook.ParseDeliveryQuery(c)
+		vid := auth.VendorID(c)
+		q.VendorID = &vid
+		list, total, err := svc.Deliveries(c.Request.Context(), q)
+		if err != nil {
+			webhookFail(c, err)
+			return
+		}
+		ok(c, gin.H{"items": list, "total": total, "limit": q.Limit, "offset": q.Offset})
+	})
+
+	// 手動重送
+	grp.POST("/webhook-deliveries/:id/redeliver", func(c *gin.Context) {
+		vid := auth.VendorID(c)
+		d, err := svc.Redeliver(c.Request.Context(), &vid, id(c))
+		if err != nil {
+			webhookFail(c, err)
+			return
+		}
+		ok(c, gin.H{"delivery": d})
+	})
+}
@@ -0,0 +1,168 @@
+package webhook
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/events"
+)
+
+// 後台管理的是平台訂閱（VendorID 空）；投遞紀錄可查看所有廠商
+type Handler struct {
+	svc *Service
+}
+
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+func writeError(c *gin.Context, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, ErrInvalidURL), errors.Is(err, ErrHTTPSRequired), errors.Is(err, ErrInvalidEvent):
+		code = http.StatusBadRequest
+	case errors.Is(err, ErrTooMany):
+		code = http.StatusConflict
+	}
+	c.JSON(code, gin.H{"error": err.Error()})
+}
+
+// SubscriptionJSON：訂閱的回應格式（不含金鑰）
+func SubscriptionJSON(s *Subscription) gin.H {
+	return gin.H{
+		"id":          s.ID,
+		"url":         s.URL,
+		"description": s.Description,
+		"events":      s.EventList(),
+		"isActive":    s.IsActive,
+		"createdAt":   s.CreatedAt,
+		"updatedAt":   s.UpdatedAt,
+	}
+}
+
+// ParseDeliveryQuery：共用的投遞紀錄查詢參數 ?subscriptionId=&status=&event=&limit=&offset=
+func ParseDeliveryQuery(c *gin.Context) DeliveryQuery {
+	q := DeliveryQuery{
+		Status: strings.TrimSpace(c.Query("status")),
+		Event:  strings.TrimSpace(c.Query("event")),
+	}
+	q.SubscriptionID, _ = strconv.ParseUint(c.Query("subscriptionId"), 10, 64)
+	q.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if q.Limit < 1 || q.Limit > 200 {
+		q.Limit = 50
+	}
+	q.Offset, _ = strconv.Atoi(c.Query("offset"))
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	return q
+}
+
+// 後台：平台訂閱列表 GET /api/admin/webhooks
+func (h *Handler) AdminList(c *gin.Context) {
+	list, err := h.svc.Subscriptions(c.Request.Context(), "")
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	items := make([]gin.H, 0, len(list))
+	for i := range list {
+		items = append(items, SubscriptionJSON(&list[i]))
+	}
+	c.JSON(http.StatusOK, gin.H{"items": items, "events": events.Types})
+}
+
+// 後台：新增 POST /api/admin/webhooks {url, description, events} → 回傳 secret（只顯示一次）
+func (h *Handler) AdminCreate(c *gin.Context) {
+	var in SubscriptionInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sub, secret, err := h.svc.CreateSubscription(c.Request.Context(), "", in)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"subscription": SubscriptionJSON(sub), "secret": secret})
+}
+
+// 後台：修改 PUT /api/admin/webhooks/:id {url, description, events, active}
+func (h *Handler) AdminUpdate(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	var in SubscriptionInput
+	if err := c.ShouldBindJSON(&in); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sub, err := h.svc.UpdateSubscription(c.Request.Context(), "", id, in)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, SubscriptionJSON(sub))
+}
+
+// 後台：刪除 DELETE /api/admin/webhooks/:id
+func (h *Handler) AdminDelete(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err := h.svc.DeleteSubscription(c.Request.Context(), "", id); err != nil {
+		writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// 後台：更換簽章金鑰 POST /api/admin/webhooks/:id/rotate-secret
+func (h *Handler) AdminRotateSecret(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	secret, err := h.svc.RotateSecret(c.Request.Context(), "", id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"secret": secret})
+}
+
+// 後台：送出測試事件 POST /api/admin/webhooks/:id/ping
+func (h *Handler) AdminPing(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	d, err := h.svc.Ping(c.Request.Context(), "", id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+// 後台：投遞紀錄 GET /api/admin/webhook-deliveries?vendorId=&subscriptionId=&status=&event=&limit=&offset=
+// 未帶 vendorId = 全部（含各廠商）
+func (h *Handler) AdminDeliveries(c *gin.Context) {
+	q := ParseDeliveryQuery(c)
+	if v, ok := c.GetQuery("vendorId"); ok {
+		q.VendorID = &v
+	}
+	list, total, err := h.svc.Deliveries(c.Request.Context(), q)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": list, "total": total, "limit": q.Limit, "offset": q.Offset})
+}
+
+// 後台：手動重送 POST /api/admin/webhook-deliveries/:id/redeliver
+func (h *Handler) AdminRedeliver(c *gin.Context) {
+	id, _ := strconv.ParseUint(c.Param("id"), 10, 64)
+	d, err := h.svc.Redeliver(c.Request.Context(), nil, id)
+	if err != nil {
+		writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
@@ -0,0 +1,78 @@
+package webhook
+
+import (
+	"strings"
+	"time"
+)
+
+// 投遞狀態
+const (
+	StatusPending = "pending" // 等待投遞 / 等待重試
+	StatusSuccess = "success"
+	StatusFailed  = "failed" // 重試次數用完或訂閱已停用
+)
+
+// Subscription：webhook 訂閱。VendorID 空 = 平台（後台工具），收到所有訂單 / 商品事件；
+// 廠商只收到含有自己子訂單的訂單事件與自己商品的事件
+type Subscription struct {
+	ID          uint64    `gorm:"primaryKey" json:"id"`
+	VendorID    string    `gorm:"size:36;index;not null;default:''" json:"vendorId"`
+	URL         string    `gorm:"size:500;not null" json:"url"`
+	Description string    `gorm:"size:190" json:"description"`
+	Events      string    `gorm:"size:255" json:"-"`          // 逗號分隔；空 = 所有事件
+	SecretEnc   string    `gorm:"size:255;not null" json:"-"` // 簽章金鑰（secretbox 加密）
+	IsActive    bool      `gorm:"not null;default:true" json:"isActive"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (Subscription) TableName() string { return "webhook_subscriptions" }
+
+// EventList：訂閱的事件（空 = 所有事件）
+func (s *Subscription) EventList() []string {
+	out := []string{}
+	for _, e := range strings.Split(s.Events, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Wants：是否訂閱此事件
+func (s *Subscription) Wants(typ string) bool {
+	list := s.EventList()
+	if len(list) == 0 {
+		return true
+	}
+	for _, e := range list {
+		if e == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery：一個事件送往一個訂閱的投遞紀錄（自動重試累計在同一筆；手動重送另開一筆）
+type Delivery struct {
+	ID             uint64     `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint64     `gorm:"index;not null" json:"subscriptionId"`
+	VendorID       string     `gorm:"size:36;index;not null;default:''" json:"vendorId"`
+	EventID        string     `gorm:"size:36;index" json:"eventId"` // 重送時不變，接收端可據此去重
+	Event          string     `gorm:"size:32;index" json:"event"`
+	URL            string     `gorm:"size:500" json:"url"`
+	Payload        string     `gorm:"type:text" json:"payload"`
+	Status         string     `gorm:"size:16;index" json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `gorm:"index" json:"nextAttemptAt"`
+	ResponseStatus int        `json:"responseStatus"`
+	ResponseBody   string     `gorm:"size:1000" json:"responseBody"`
+	Error          string     `gorm:"size:255" json:"error"`
+	DurationMs     int64      `json:"durationMs"`
+	DeliveredAt    *time.Time `json:"deliveredAt"`
+	RedeliveryOf   uint64     `json:"redeliveryOf,omitempty"` // 手動重送的原投遞
+	CreatedAt      time.Time  `gorm:"index" json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+func (Delivery) TableName() string { return "webhook_deliveries" }
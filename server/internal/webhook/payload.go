@@ -0,0 +1,88 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/events"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/order"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/product"
+)
+
+// EventPing：測試用事件，只由「送出測試」產生，不在 events.Types 內
+const EventPing = "ping"
+
+// Payload：送出的 JSON 內容
+type Payload struct {
+	ID        string    `json:"id"` // 事件 ID（重試 / 重送不變）
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"`
+}
+
+// VendorOrder：廠商收到的訂單（只含自己的子訂單）
+type VendorOrder struct {
+	ID             uint64               `json:"id"`
+	OrderNo        string               `json:"orderNo"`
+	Status         string               `json:"status"`
+	PaymentStatus  string               `json:"paymentStatus"`
+	ShippingMethod order.ShippingMethod `json:"shippingMethod"`
+	BuyerName      string               `json:"buyerName"`
+	BuyerPhone     string               `json:"buyerPhone"`
+	Address        string               `json:"address"`
+	StoreCode      string               `json:"storeCode"`
+	StoreName      string               `json:"storeName"`
+	CreatedAt      time.Time            `json:"createdAt"`
+	SubOrder       order.SubOrder       `json:"subOrder"`
+}
+
+// subject：事件相關的資料，依訂閱者（平台 / 廠商）裁切內容
+type subject struct {
+	order   *order.Order
+	product *product.Product
+}
+
+func (s *Service) load(ctx context.Context, e events.Event) (*subject, error) {
+	switch e.Type {
+	case events.OrderCreated, events.OrderPaid, events.OrderShipped:
+		o, err := s.repo.AdminGet(e.OrderID)
+		if err != nil {
+			return nil, err
+		}
+		return &subject{order: o}, nil
+	case events.ProductLowStock:
+		var p product.Product
+		if err := s.db.WithContext(ctx).First(&p, e.ProductID).Error; err != nil {
+			return nil, err
+		}
+		return &subject{product: &p}, nil
+	}
+	return nil, nil
+}
+
+// dataFor：訂閱者看得到的內容；與該廠商無關時回傳 nil（不投遞）
+func (sj *subject) dataFor(vendorID string) any {
+	switch {
+	case sj.order != nil:
+		if vendorID == "" {
+			return map[string]any{"order": sj.order}
+		}
+		o := sj.order
+		for _, so := range o.SubOrders {
+			if so.VendorID != vendorID {
+				continue
+			}
+			return map[string]any{"order": VendorOrder{
+				ID: o.ID, OrderNo: o.OrderNo, Status: o.Status, PaymentStatus: o.PaymentStatus,
+				ShippingMethod: o.ShippingMethod, BuyerName: o.BuyerName, BuyerPhone: o.BuyerPhone,
+				Address: o.Address, StoreCode: o.StoreCode, StoreName: o.StoreName,
+				CreatedAt: o.CreatedAt, SubOrder: so,
+			}}
+		}
+	case sj.product != nil:
+		if vendorID == "" || sj.product.VendorID == vendorID {
+			return map[string]any{"product": sj.product, "threshold": product.LowStockThreshold}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,572 @@
+// Package webhook 對外 webhook：訂閱訂單 / 商品事件，以 HMAC 簽章 POST 到訂閱網址，
+// 投遞紀錄存在資料庫，失敗以指數退避重試，並可手動重送
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/events"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/order"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/secretbox"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
+
+const (
+	maxAttempts    = 8
+	baseBackoff    = 30 * time.Second // 30s, 1m, 2m … 最多 2 小時
+	maxBackoff     = 2 * time.Hour
+	requestTimeout = 10 * time.Second
+	pollEvery      = 10 * time.Second
+	claimFor       = time.Minute // 投遞中先把下次時間往後推，多個實例不會重複投遞
+	batchSize      = 100
+	maxSubs        = 10 // 每個廠商（或平台）的訂閱上限
+)
+
+var (
+	ErrNotFound       = errors.New("webhook: not found")
+	ErrInvalidURL     = errors.New("webhook: invalid url")
+	ErrHTTPSRequired  = errors.New("webhook: https url required")
+	ErrInvalidEvent   = errors.New("webhook: unknown event type")
+	ErrTooMany        = errors.New("webhook: too many subscriptions")
+	errPrivateAddress = errors.New("webhook: private or loopback address not allowed")
+)
+
+type Service struct {
+	db           *gorm.DB
+	box          *secretbox.Box
+	repo         *order.Repo
+	client       *http.Client
+	allowPrivate bool
+	wake         chan struct{}
+}
+
+// NewService：allowPrivate = 允許 http 與 localhost / 內網網址（本機測試用）
+func NewService(db *gorm.DB, box *secretbox.Box, allowPrivate bool) *Service {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivate {
+		// 以實際連線的 IP 判斷，DNS 指向內網也擋得住
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || privateIP(ip) {
+				return errPrivateAddress
+			}
+			return nil
+		}
+	}
+	return &Service{
+		db:   db,
+		box:  box,
+		repo: order.NewRepo(db),
+		client: &http.Client{
+			Timeout:   requestTimeout,
+			Transport: &http.Transport{DialContext: dialer.DialContext},
+			// 不跟隨轉址：3xx 視為失敗，避免被導向內網
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		allowPrivate: allowPrivate,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+func privateIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast()
+}
+
+// Subscribe：訂閱所有事件（依各訂閱的事件類型篩選）
+func (s *Service) Subscribe() {
+	events.Subscribe("", s.handle)
+}
+
+func (s *Service) handle(ctx context.Context, e events.Event) {
+	if !validEvent(e.Type) {
+		return
+	}
+	// 停權廠商的訂閱不再產生投遞（平台訂閱 vendor_id 為空）
+	var subs []Subscription
+	if err := s.db.WithContext(ctx).Where("is_active = 1").
+		Where("(vendor_id = '' OR vendor_id NOT IN (SELECT id FROM vendors WHERE status = ?))", models.StatusSuspended).
+		Find(&subs).Error; err != nil {
+		log.Printf("webhook: %s: load subscriptions: %v", e.Type, err)
+		return
+	}
+	var sj *subject
+	eventID := uuid.NewString()
+	now := time.Now()
+	var list []Delivery
+	for i := range subs {
+		sub := &subs[i]
+		if !sub.Wants(e.Type) {
+			continue
+		}
+		if sj == nil {
+			var err error
+			if sj, err = s.load(ctx, e); err != nil || sj == nil {
+				log.Printf("webhook: %s: load subject: %v", e.Type, err)
+				return
+			}
+		}
+		data := sj.dataFor(sub.VendorID)
+		if data == nil {
+			continue
+		}
+		body, err := json.Marshal(Payload{ID: eventID, Type: e.Type, CreatedAt: e.At, Data: data})
+		if err != nil {
+			log.Printf("webhook: %s: marshal: %v", e.Type, err)
+			return
+		}
+		list = append(list, Delivery{
+			SubscriptionID: sub.ID,
+			VendorID:       sub.VendorID,
+			EventID:        eventID,
+			Event:          e.Type,
+			URL:            sub.URL,
+			Payload:        string(body),
+			Status:         StatusPending,
+			NextAttemptAt:  &now,
+		})
+	}
+	if len(list) == 0 {
+		return
+	}
+	if err := s.db.WithContext(ctx).Create(&list).Error; err != nil {
+		log.Printf("webhook: %s: save deliveries: %v", e.Type, err)
+		return
+	}
+	s.notify()
+}
+
+// notify：有新投遞時叫醒 Run，不必等下一次輪詢
+func (s *Service) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run：背景投遞，workers 個同時送出（阻塞到 ctx 結束）
+func (s *Service) Run(ctx context.Context, workers int) {
+	if workers <= 0 {
+		workers = 1
+	}
+	t := time.NewTicker(pollEvery)
+	defer t.Stop()
+	for {
+		s.dispatch(ctx, workers)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// dispatch：送出所有到期的投遞
+func (s *Service) dispatch(ctx context.Context, workers int) {
+	for ctx.Err() == nil {
+		now := time.Now()
+		var due []Delivery
+		if err := s.db.WithContext(ctx).
+			Where("status = ? AND next_attempt_at <= ?", StatusPending, now).
+			Order("next_attempt_at").Limit(batchSize).Find(&due).Error; err != nil {
+			log.Printf("webhook: load due deliveries: %v", err)
+			return
+		}
+		sem := make(chan struct{}, workers)
+		var wg sync.WaitGroup
+		for i := range due {
+			d := &due[i]
+			if !s.claim(ctx, d, now) {
+				continue
+			}
+			sem <- struct{}{}
+			wg.Add(1)
+			go func() {
+				defer func() { <-sem; wg.Done() }()
+				s.attempt(ctx, d, true)
+			}()
+		}
+		wg.Wait()
+		if len(due) < batchSize {
+			return
+		}
+	}
+}
+
+// claim：把下次投遞時間往後推；其他實例已取走時回傳 false
+func (s *Service) claim(ctx context.Context, d *Delivery, now time.Time) bool {
+	res := s.db.WithContext(ctx).Model(&Delivery{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", d.ID, StatusPending, now).
+		Update("next_attempt_at", now.Add(claimFor))
+	return res.Error == nil && res.RowsAffected == 1
+}
+
+// attempt：送出一次並更新紀錄；retry = 失敗時排入下次重試（手動重送 / 測試不重試）
+func (s *Service) attempt(ctx context.Context, d *Delivery, retry bool) {
+	var sub Subscription
+	err := s.db.WithContext(ctx).First(&sub, d.SubscriptionID).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		err, retry = errors.New("subscription deleted"), false
+	case err == nil && !sub.IsActive:
+		err, retry = errors.New("subscription disabled"), false
+	}
+	var secret string
+	if err == nil {
+		d.URL = sub.URL // 重試時網址可能已修改
+		secret, err = s.box.Open(sub.SecretEnc)
+	}
+	var (
+		status  int
+		excerpt string
+		elapsed time.Duration
+	)
+	if err == nil {
+		status, excerpt, elapsed, err = s.post(ctx, sub.URL, secret, d)
+		if err == nil && (status < 200 || status > 299) {
+			err = fmt.Errorf("HTTP %d", status)
+		}
+	}
+
+	now := time.Now()
+	d.Attempts++
+	d.ResponseStatus, d.ResponseBody, d.DurationMs = status, excerpt, elapsed.Milliseconds()
+	switch {
+	case err == nil:
+		d.Status, d.Error, d.NextAttemptAt, d.DeliveredAt = StatusSuccess, "", nil, &now
+	case retry && d.Attempts < maxAttempts:
+		delay := baseBackoff << (d.Attempts - 1)
+		if delay > maxBackoff {
+			delay = maxBackoff
+		}
+		next := now.Add(delay)
+		d.Status, d.Error, d.NextAttemptAt = StatusPending, truncate(err.Error(), 255), &next
+	default:
+		d.Status, d.Error, d.NextAttemptAt = StatusFailed, truncate(err.Error(), 255), nil
+	}
+	if err != nil {
+		log.Printf("webhook: delivery %d (%s) to %s attempt %d: %v", d.ID, d.Event, d.URL, d.Attempts, err)
+	}
+	if err := s.db.WithContext(ctx).Model(&Delivery{ID: d.ID}).Updates(map[string]any{
+		"url":             d.URL,
+		"attempts":        d.Attempts,
+		"status":          d.Status,
+		"error":           d.Error,
+		"next_attempt_at": d.NextAttemptAt,
+		"response_status": d.ResponseStatus,
+		"response_body":   d.ResponseBody,
+		"duration_ms":     d.DurationMs,
+		"delivered_at":    d.DeliveredAt,
+	}).Error; err != nil {
+		log.Printf("webhook: update delivery %d: %v", d.ID, err)
+	}
+}
+
+// post：簽章並送出，回傳狀態碼與回應開頭（寫入紀錄供排查）
+func (s *Service) post(ctx context.Context, target, secret string, d *Delivery) (int, string, time.Duration, error) {
+	body := []byte(d.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, "", 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ZeusShop-Webhook/1.0")
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderDelivery, d.EventID)
+	req.Header.Set(HeaderSignature, Sign(secret, time.Now(), body))
+
+	start := time.Now()
+	res, err := s.client.Do(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		return 0, "", elapsed, err
+	}
+	defer res.Body.Close()
+	excerpt, _ := io.ReadAll(io.LimitReader(res.Body, 1000))
+	return res.StatusCode, strings.ToValidUTF8(string(excerpt), ""), elapsed, nil
+}
+
+func validEvent(typ string) bool {
+	for _, t := range events.Types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func newSecret() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// truncate：以字元（rune）截斷，不會切開多位元組的中文字
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// ---- 訂閱管理（vendorID 空 = 平台訂閱）----
+
+// SubscriptionInput：建立 / 修改訂閱
+type SubscriptionInput struct {
+	URL         string   `json:"url" binding:"required"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"` // 空 = 所有事件
+	Active      *bool    `json:"active"` // 修改時可停用 / 啟用
+}
+
+// normalize：檢查網址與事件類型，回傳要存的事件字串
+func (s *Service) normalize(in *SubscriptionInput) (string, error) {
+	in.URL = strings.TrimSpace(in.URL)
+	u, err := url.Parse(in.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") || u.User != nil {
+		return "", ErrInvalidURL
+	}
+	if !s.allowPrivate {
+		if u.Scheme != "https" {
+			return "", ErrHTTPSRequired
+		}
+		host := u.Hostname()
+		if ip := net.ParseIP(host); strings.EqualFold(host, "localhost") || (ip != nil && privateIP(ip)) {
+			return "", ErrInvalidURL
+		}
+	}
+	seen := map[string]bool{}
+	var list []string
+	for _, e := range in.Events {
+		if !validEvent(e) {
+			return "", ErrInvalidEvent
+		}
+		if !seen[e] {
+			seen[e] = true
+			list = append(list, e)
+		}
+	}
+	in.Description = truncate(strings.TrimSpace(in.Description), 190)
+	return strings.Join(list, ","), nil
+}
+
+func (s *Service) Subscriptions(ctx context.Context, vendorID string) ([]Subscription, error) {
+	var list []Subscription
+	err := s.db.WithContext(ctx).Where("vendor_id = ?", vendorID).Order("id").Find(&list).Error
+	return list, err
+}
+
+func (s *Service) subscription(ctx context.Context, vendorID string, id uint64) (*Subscription, error) {
+	var sub Subscription
+	if err := s.db.WithContext(ctx).Where("id = ? AND vendor_id = ?", id, vendorID).First(&sub).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return &sub, nil
+}
+
+// CreateSubscription：回傳訂閱與簽章金鑰明碼（只在建立 / 更換時顯示）
+func (s *Service) CreateSubscription(ctx context.Context, vendorID string, in SubscriptionInput) (*Subscription, string, error) {
+	evs, err := s.normalize(&in)
+	if err != nil {
+		return nil, "", err
+	}
+	var n int64
+	if err := s.db.WithContext(ctx).Model(&Subscription{}).Where("vendor_id = ?", vendorID).Count(&n).Error; err != nil {
+		return nil, "", err
+	}
+	if n >= maxSubs {
+		return nil, "", ErrTooMany
+	}
+	secret := newSecret()
+	sealed, err := s.box.Seal(secret)
+	if err != nil {
+		return nil, "", err
+	}
+	sub := &Subscription{
+		VendorID:    vendorID,
+		URL:         in.URL,
+		Description: in.Description,
+		Events:      evs,
+		SecretEnc:   sealed,
+		IsActive:    in.Active == nil || *in.Active,
+	}
+	if err := s.db.WithContext(ctx).Create(sub).Error; err != nil {
+		return nil, "", err
+	}
+	return sub, secret, nil
+}
+
+func (s *Service) UpdateSubscription(ctx context.Context, vendorID string, id uint64, in SubscriptionInput) (*Subscription, error) {
+	sub, err := s.subscription(ctx, vendorID, id)
+	if err != nil {
+		return nil, err
+	}
+	evs, err := s.normalize(&in)
+	if err != nil {
+		return nil, err
+	}
+	cols := map[string]any{"url": in.URL, "description": in.Description, "events": evs}
+	if in.Active != nil {
+		cols["is_active"] = *in.Active
+	}
+	if err := s.db.WithContext(ctx).Model(sub).Updates(cols).Error; err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// RotateSecret：更換簽章金鑰，回傳新金鑰明碼（之後的投遞改用新金鑰簽章）
+func (s *Service) RotateSecret(ctx context.Context, vendorID string, id uint64) (string, error) {
+	sub, err := s.subscription(ctx, vendorID, id)
+	if err != nil {
+		return "", err
+	}
+	secret := newSecret()
+	sealed, err := s.box.Seal(secret)
+	if err != nil {
+		return "", err
+	}
+	if err := s.db.WithContext(ctx).Model(sub).Update("secret_enc", sealed).Error; err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// DeleteSubscription：刪除訂閱；尚未送出的投遞標記失敗，紀錄保留
+func (s *Service) DeleteSubscription(ctx context.Context, vendorID string, id uint64) error {
+	sub, err := s.subscription(ctx, vendorID, id)
+	if err != nil {
+		return err
+	}
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Delivery{}).
+			Where("subscription_id = ? AND status = ?", sub.ID, StatusPending).
+			Updates(map[string]any{"status": StatusFailed, "error": "subscription deleted", "next_attempt_at": nil}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(sub).Error
+	})
+}
+
+// Ping：送出一則測試事件（只嘗試一次，同樣寫入投遞紀錄）
+func (s *Service) Ping(ctx context.Context, vendorID string, id uint64) (*Delivery, error) {
+	sub, err := s.subscription(ctx, vendorID, id)
+	if err != nil {
+		return nil, err
+	}
+	eventID := uuid.NewString()
+	body, _ := json.Marshal(Payload{
+		ID: eventID, Type: EventPing, CreatedAt: time.Now(),
+		Data: map[string]any{"subscriptionId": sub.ID},
+	})
+	d := &Delivery{
+		SubscriptionID: sub.ID,
+		VendorID:       sub.VendorID,
+		EventID:        eventID,
+		Event:          EventPing,
+		URL:            sub.URL,
+		Payload:        string(body),
+		Status:         StatusPending,
+	}
+	if err := s.db.WithContext(ctx).Create(d).Error; err != nil {
+		return nil, err
+	}
+	s.attempt(ctx, d, false)
+	return d, nil
+}
+
+// ---- 投遞紀錄 ----
+
+// DeliveryQuery：投遞紀錄篩選；VendorID nil = 所有（後台），否則限定該廠商（空字串 = 平台）
+type DeliveryQuery struct {
+	VendorID       *string
+	SubscriptionID uint64
+	Status         string
+	Event          string
+	Limit          int
+	Offset         int
+}
+
+func (s *Service) Deliveries(ctx context.Context, q DeliveryQuery) ([]Delivery, int64, error) {
+	tx := s.db.WithContext(ctx).Model(&Delivery{})
+	if q.VendorID != nil {
+		tx = tx.Where("vendor_id = ?", *q.VendorID)
+	}
+	if q.SubscriptionID != 0 {
+		tx = tx.Where("subscription_id = ?", q.SubscriptionID)
+	}
+	if q.Status != "" {
+		tx = tx.Where("status = ?", q.Status)
+	}
+	if q.Event != "" {
+		tx = tx.Where("event = ?", q.Event)
+	}
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var list []Delivery
+	err := tx.Order("id DESC").Limit(q.Limit).Offset(q.Offset).Find(&list).Error
+	return list, total, err
+}
+
+// Redeliver：手動重送（同一事件內容、目前的訂閱網址與金鑰，另開一筆紀錄，只嘗試一次）。
+// vendorID 非 nil 時只能重送該廠商的投遞
+func (s *Service) Redeliver(ctx context.Context, vendorID *string, id uint64) (*Delivery, error) {
+	var orig Delivery
+	tx := s.db.WithContext(ctx)
+	if vendorID != nil {
+		tx = tx.Where("vendor_id = ?", *vendorID)
+	}
+	if err := tx.First(&orig, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	sub, err := s.subscription(ctx, orig.VendorID, orig.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	d := &Delivery{
+		SubscriptionID: orig.SubscriptionID,
+		VendorID:       orig.VendorID,
+		EventID:        orig.EventID,
+		Event:          orig.Event,
+		URL:            sub.URL,
+		Payload:        orig.Payload,
+		Status:         StatusPending,
+		RedeliveryOf:   orig.ID,
+	}
+	if err := s.db.WithContext(ctx).Create(d).Error; err != nil {
+		return nil, err
+	}
+	s.attempt(ctx, d, false)
+	return d, nil
+}
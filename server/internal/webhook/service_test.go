@@ -0,0 +1,223 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/events"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/product"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/secretbox"
+	"github.com/sjjfjuhiuhgiuehgui/zeusshop/server/internal/vendors/models"
+)
+
+// receiver：假的接收端，記錄每次收到的請求並回應 status
+type receiver struct {
+	mu     sync.Mutex
+	status int
+	got    []received
+}
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.got = append(rc.got, received{header: r.Header.Clone(), body: body})
+	w.WriteHeader(rc.status)
+}
+
+func (rc *receiver) respond(status int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rc.status = status
+}
+
+func (rc *receiver) last(t *testing.T) received {
+	t.Helper()
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.got) == 0 {
+		t.Fatal("receiver got no request")
+	}
+	return rc.got[len(rc.got)-1]
+}
+
+func newTestService(t *testing.T) (*Service, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1) // :memory: 每條連線各自一個資料庫
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&Subscription{}, &Delivery{}, &models.Vendor{}, &product.Product{}); err != nil {
+		t.Fatal(err)
+	}
+	box, err := secretbox.New("test-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewService(db, box, true), db
+}
+
+func reload(t *testing.T, db *gorm.DB, id uint64) Delivery {
+	t.Helper()
+	var d Delivery
+	if err := db.First(&d, id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestDeliveryRetryAndRedeliver(t *testing.T) {
+	ctx := context.Background()
+	s, db := newTestService(t)
+	rc := &receiver{status: http.StatusInternalServerError}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+
+	sub, secret, err := s.CreateSubscription(ctx, "", SubscriptionInput{URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	d := Delivery{
+		SubscriptionID: sub.ID,
+		EventID:        "evt-1",
+		Event:          events.OrderPaid,
+		URL:            sub.URL,
+		Payload:        `{"id":"evt-1","type":"order.paid"}`,
+		Status:         StatusPending,
+		NextAttemptAt:  &now,
+	}
+	if err := db.Create(&d).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// 5xx：每次失敗排到 baseBackoff << (第幾次 - 1) 之後，用完 maxAttempts 次標記失敗
+	for n := 0; n < maxAttempts; n++ {
+		before := time.Now()
+		s.dispatch(ctx, 1)
+		got := reload(t, db, d.ID)
+		if got.Attempts != n+1 || got.ResponseStatus != http.StatusInternalServerError {
+			t.Fatalf("attempt %d: attempts = %d, responseStatus = %d", n+1, got.Attempts, got.ResponseStatus)
+		}
+
+		req := rc.last(t)
+		if err := Verify(secret, req.header.Get(HeaderSignature), req.body, time.Now(), 5*time.Minute); err != nil {
+			t.Fatalf("attempt %d: Verify(%s) = %v", n+1, HeaderSignature, err)
+		}
+		if id := req.header.Get(HeaderDelivery); id != "evt-1" {
+			t.Fatalf("attempt %d: %s = %q, want evt-1", n+1, HeaderDelivery, id)
+		}
+
+		if n == maxAttempts-1 {
+			if got.Status != StatusFailed || got.NextAttemptAt != nil {
+				t.Fatalf("after %d attempts: status = %s, next = %v, want failed", maxAttempts, got.Status, got.NextAttemptAt)
+			}
+			break
+		}
+		want := baseBackoff << n
+		if want > maxBackoff {
+			want = maxBackoff
+		}
+		if got.Status != StatusPending || got.NextAttemptAt == nil {
+			t.Fatalf("attempt %d: status = %s, next = %v, want pending", n+1, got.Status, got.NextAttemptAt)
+		}
+		if delay := got.NextAttemptAt.Sub(before); delay < want || delay > want+5*time.Second {
+			t.Fatalf("attempt %d: rescheduled after %v, want %v", n+1, delay, want)
+		}
+		// 提前到期，下一輪 dispatch 直接重試
+		if err := db.Model(&Delivery{}).Where("id = ?", d.ID).
+			Update("next_attempt_at", time.Now().Add(-time.Second)).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 手動重送：另開一筆，事件 ID 不變
+	rc.respond(http.StatusOK)
+	re, err := s.Redeliver(ctx, nil, d.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if re.ID == d.ID || re.RedeliveryOf != d.ID || re.EventID != d.EventID || re.Status != StatusSuccess {
+		t.Fatalf("redelivery = %+v", re)
+	}
+	req := rc.last(t)
+	if id := req.header.Get(HeaderDelivery); id != d.EventID {
+		t.Fatalf("redelivery %s = %q, want %q", HeaderDelivery, id, d.EventID)
+	}
+	if err := Verify(secret, req.header.Get(HeaderSignature), req.body, time.Now(), 5*time.Minute); err != nil {
+		t.Fatalf("redelivery Verify = %v", err)
+	}
+}
+
+func TestHandleSkipsSuspendedVendor(t *testing.T) {
+	ctx := context.Background()
+	s, db := newTestService(t)
+
+	v := models.Vendor{ID: "vendor-1", Email: "v1@example.com", PasswordHash: "x", IsActive: true, Status: models.StatusApproved}
+	if err := db.Create(&v).Error; err != nil {
+		t.Fatal(err)
+	}
+	p := product.Product{Name: "Mug", VendorID: v.ID}
+	if err := db.Create(&p).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, vendorID := range []string{"", v.ID} {
+		if _, _, err := s.CreateSubscription(ctx, vendorID, SubscriptionInput{URL: "http://127.0.0.1:1/hook"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	count := func() (platform, vendor int64) {
+		t.Helper()
+		if err := db.Model(&Delivery{}).Where("vendor_id = ''").Count(&platform).Error; err != nil {
+			t.Fatal(err)
+		}
+		if err := db.Model(&Delivery{}).Where("vendor_id = ?", v.ID).Count(&vendor).Error; err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	ev := events.Event{Type: events.ProductLowStock, ProductID: p.ID, At: time.Now()}
+	s.handle(ctx, ev)
+	if platform, vendor := count(); platform != 1 || vendor != 1 {
+		t.Fatalf("approved vendor: deliveries platform = %d, vendor = %d, want 1 / 1", platform, vendor)
+	}
+
+	if err := db.Model(&v).Update("status", models.StatusSuspended).Error; err != nil {
+		t.Fatal(err)
+	}
+	s.handle(ctx, ev)
+	if platform, vendor := count(); platform != 2 || vendor != 1 {
+		t.Fatalf("suspended vendor: deliveries platform = %d, vendor = %d, want 2 / 1", platform, vendor)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("訂閱已停用", 3); got != "訂閱已" {
+		t.Fatalf("truncate = %q", got)
+	}
+	if got := truncate("ok", 3); got != "ok" {
+		t.Fatalf("truncate = %q", got)
+	}
+}
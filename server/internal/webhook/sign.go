@@ -0,0 +1,70 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 送出的 HTTP header
+const (
+	HeaderEvent     = "X-Zeusshop-Event"
+	HeaderDelivery  = "X-Zeusshop-Delivery"
+	HeaderSignature = "X-Zeusshop-Signature"
+)
+
+var (
+	ErrBadSignature   = errors.New("webhook: signature mismatch")
+	ErrStaleSignature = errors.New("webhook: signature timestamp outside tolerance")
+)
+
+func mac(secret string, ts int64, body []byte) string {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(strconv.FormatInt(ts, 10)))
+	h.Write([]byte("."))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Sign：簽章 header 值 "t=<unix 秒>,v1=<hex>"，
+// v1 = HMAC-SHA256(secret, "<unix 秒>.<body>")；時間戳一起簽，接收端可拒絕過舊的重放
+func Sign(secret string, at time.Time, body []byte) string {
+	ts := at.Unix()
+	return "t=" + strconv.FormatInt(ts, 10) + ",v1=" + mac(secret, ts, body)
+}
+
+// Verify：接收端驗證簽章；tolerance <= 0 不檢查時間
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var (
+		ts   int64
+		sigs []string
+	)
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts, _ = strconv.ParseInt(v, 10, 64)
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+	if ts == 0 || len(sigs) == 0 {
+		return ErrBadSignature
+	}
+	if tolerance > 0 {
+		if d := now.Sub(time.Unix(ts, 0)); d > tolerance || d < -tolerance {
+			return ErrStaleSignature
+		}
+	}
+	want := mac(secret, ts, body)
+	for _, s := range sigs {
+		if hmac.Equal([]byte(s), []byte(want)) {
+			return nil
+		}
+	}
+	return ErrBadSignature
+}
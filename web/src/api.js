@@ -13,12 +13,12 @@ const api = axios.create({
 export const getAdminToken = () => localStorage.getItem('adminToken')
 export const setAdminToken = (t) => localStorage.setItem('adminToken', t)
 
-// 自動帶上 Admin Token（僅 admin 路徑）
+// 自動帶上後台登入的 JWT（僅 admin 路徑）
 api.interceptors.request.use(cfg => {
   const t = getAdminToken()
   const path = cfg.url || ''
   if (t && (path.startsWith('/admin/') || path.startsWith('admin/'))) {
-    cfg.headers['X-Admin-Token'] = t
+    cfg.headers['Authorization'] = `Bearer ${t}`
   }
   return cfg
 })
@@ -30,13 +30,17 @@ api.interceptors.response.use(
     const status = e?.response?.status
     if (status === 401) {
       if (!location.pathname.startsWith('/admin/login')) {
-        alert('未授權：請先在 /admin/login 設定正確的管理 Token')
+        alert('未授權或登入已逾時，請重新登入管理後台')
         try {
           sessionStorage.setItem('postLoginRedirect', location.pathname + location.search)
         } catch {}
         location.href = '/admin/login'
       }
     }
+    // 後台要求雙因素驗證但尚未綁定
+    if (status === 403 && e?.response?.data?.code === 'TWO_FACTOR_REQUIRED') {
+      if (!location.pathname.startsWith('/admin/security')) location.href = '/admin/security'
+    }
     throw e
   }
 )
@@ -57,10 +61,53 @@ export const updateOrderRemit = async (id, payload) =>
   (await api.put(`/orders/${id}/remit`, payload)).data
 
 /* ==========
- * Admin APIs（需 X-Admin-Token）
+ * 超商電子地圖：整頁導向選店，選完會帶 ?cvsState= 回到 returnUrl
+ * ========== */
+export const cvsMapUrl = (returnUrl, brand = '711') =>
+  `${BASE_URL}/logistics/cvs/map?brand=${encodeURIComponent(brand)}&returnUrl=${encodeURIComponent(returnUrl)}`
+
+export const getCvsStore = async (state) =>
+  (await api.get(`/logistics/cvs/store/${encodeURIComponent(state)}`)).data
+
+/* ==========
+ * Admin APIs（需先登入，Authorization: Bearer <JWT>）
  * ========== */
-export const adminListOrders = async () =>
-  (await api.get('/admin/orders')).data.items
+export const adminLogin = async (email, password) =>
+  (await api.post('/admin/login', { email, password })).data
+
+// 已啟用雙因素驗證時回傳 { twoFactorRequired, challenge }，再以驗證碼呼叫 adminLogin2FA
+export const adminLogin2FA = async (challenge, code) =>
+  (await api.post('/admin/login/2fa', { challenge, code })).data
+
+export const adminMe = async () =>
+  (await api.get('/admin/me')).data
+
+// 雙因素驗證（TOTP）
+export const adminGetTwoFactor = async () =>
+  (await api.get('/admin/me/2fa')).data
+
+export const adminSetupTwoFactor = async () =>
+  (await api.post('/admin/me/2fa/setup')).data
+
+export const adminEnableTwoFactor = async (code) =>
+  (await api.post('/admin/me/2fa/enable', { code })).data
+
+export const adminDisableTwoFactor = async (password, code) =>
+  (await api.post('/admin/me/2fa/disable', { password, code })).data
+
+export const adminRegenerateRecoveryCodes = async (code) =>
+  (await api.post('/admin/me/2fa/recovery-codes', { code })).data
+
+export const adminGetSecurityPolicy = async () =>
+  (await api.get('/admin/security-policy')).data
+
+export const adminSetSecurityPolicy = async (require2fa) =>
+  (await api.put('/admin/security-policy', { require2fa })).data
+
+// params: { q, status, payment, shipping, vendor, from, to, sort, limit, offset }
+// 回傳 { items, total, limit, offset }
+export const adminListOrders = async (params = {}) =>
+  (await api.get('/admin/orders', { params })).data
 
 export const adminGetOrder = async (id) =>
   (await api.get(`/admin/orders/${id}`)).data
@@ -71,6 +118,59 @@ export const adminUpdateOrderStatus = async (id, status) =>
 export const adminDeleteOrder = async (id) =>
   (await api.delete(`/admin/orders/${id}`)).data
 
+// 批次操作：回傳 { results: [{ id, ok, error }], succeeded, failed }
+export const adminBulkOrderStatus = async (ids, status) =>
+  (await api.post('/admin/orders/bulk/status', { ids, status })).data
+
+export const adminBulkConfirmPayment = async (ids, paid = true) =>
+  (await api.post('/admin/orders/bulk/payment', { ids, paid })).data
+
+export const adminBulkShipments = async (shipments) =>
+  (await api.post('/admin/orders/bulk/shipments', { shipments })).data
+
+export const adminBulkPrint = async (ids) =>
+  (await api.post('/admin/orders/bulk/print', { ids })).data
+
+// 廠商登入失敗紀錄：params { email, ip, vendorId, limit, offset }
+export const adminListVendorLoginAudits = async (params = {}) =>
+  (await api.get('/admin/vendors/login-audits', { params })).data
+
+export const adminUnlockVendor = async (id) =>
+  (await api.post(`/admin/vendors/${id}/unlock`)).data
+
+// 簡訊 / LINE 通知發送紀錄：params { orderId, status, channel, limit, offset }
+export const adminListNotifications = async (params = {}) =>
+  (await api.get('/admin/notifications', { params })).data
+
+export const adminResendNotification = async (id) =>
+  (await api.post(`/admin/notifications/${id}/resend`)).data
+
+// 平台 webhook 訂閱
+export const adminListWebhooks = async () =>
+  (await api.get('/admin/webhooks')).data
+
+export const adminCreateWebhook = async (payload) =>
+  (await api.post('/admin/webhooks', payload)).data
+
+export const adminUpdateWebhook = async (id, payload) =>
+  (await api.put(`/admin/webhooks/${id}`, payload)).data
+
+export const adminDeleteWebhook = async (id) =>
+  (await api.delete(`/admin/webhooks/${id}`)).data
+
+export const adminRotateWebhookSecret = async (id) =>
+  (await api.post(`/admin/webhooks/${id}/rotate-secret`)).data
+
+export const adminPingWebhook = async (id) =>
+  (await api.post(`/admin/webhooks/${id}/ping`)).data
+
+// webhook 投遞紀錄：params { vendorId, subscriptionId, status, event, limit, offset }
+export const adminListWebhookDeliveries = async (params = {}) =>
+  (await api.get('/admin/webhook-deliveries', { params })).data
+
+export const adminRedeliverWebhook = async (id) =>
+  (await api.post(`/admin/webhook-deliveries/${id}/redeliver`)).data
+
 export const adminCreateProduct = async (payload) =>
   (await api.post('/admin/products', payload)).data
 
@@ -9,11 +9,33 @@ function normalizeVendorResponse(data) {
   if (!data) return { ok: true, vendor: null };
   if (data.vendor) return { ok: data.ok !== false, vendor: data.vendor };
   if (data.id && data.email) return { ok: true, vendor: { id: data.id, email: data.email, name: data.name } };
-  return { ok: data.ok !== false, vendor: null };
+  return { ...data, ok: data.ok !== false, vendor: null };
+}
+
+// access token 只有 15 分鐘：遇到 401 先用 refresh cookie 換發一次再重試。
+// 多個請求同時 401 時共用同一個刷新請求；409 表示其他分頁剛換發過，cookie 已是新的，直接重試
+let refreshing = null;
+function refreshSession() {
+  if (!refreshing) {
+    refreshing = fetch("/api/vendor/auth/refresh", { method: "POST", credentials: "include" })
+      .then((res) => res.ok || res.status === 409)
+      .catch(() => false)
+      .finally(() => { refreshing = null; });
+  }
+  return refreshing;
+}
+
+// vFetch：廠商 API 專用的 fetch（自動帶 cookie、401 自動刷新後重試一次）
+export async function vFetch(url, init = {}) {
+  const opts = { credentials: "include", ...init };
+  const res = await fetch(url, opts);
+  if (res.status !== 401 || url.startsWith("/api/vendor/auth/") || url.startsWith("/api/vendor/login")) return res;
+  if (!(await refreshSession())) return res;
+  return fetch(url, opts);
 }
 
 export async function vPOST(path, body) {
-  const res = await fetch(`/api/vendor${path}`, {
+  const res = await vFetch(`/api/vendor${path}`, {
     method: "POST",
     headers: { "Content-Type": "application/json" },
     credentials: "include",
@@ -28,7 +50,7 @@ export async function vPOST(path, body) {
 }
 
 export async function vGET(path) {
-  const res = await fetch(`/api/vendor${path}`, { credentials: "include" });
+  const res = await vFetch(`/api/vendor${path}`);
   const data = await parseJSONSafe(res);
   if (!res.ok) {
     const msg = (data && (data.error || data.message)) || `${res.status} ${res.statusText}`;
@@ -41,7 +63,7 @@ export async function vGET(path) {
 export async function vUPLOAD(file) {
   const fd = new FormData();
   fd.append('file', file);
-  const res = await fetch('/api/vendor/upload', {
+  const res = await vFetch('/api/vendor/upload', {
     method: 'POST',
     credentials: 'include',
     body: fd,
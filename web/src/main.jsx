@@ -17,6 +17,9 @@ import Favorites from './pages/Favorites'
 import AdminLogin from './pages/admin/Login'
 import Orders from './pages/admin/Orders'
 import OrderDetail from './pages/admin/OrderDetail'
+import AdminSecurity from './pages/admin/Security'
+import VendorLoginAudits from './pages/admin/VendorLoginAudits'
+import AdminWebhooks from './pages/admin/Webhooks'
 
 // 分類頁
 import CategoryPage from './pages/CategoryPage.jsx'
@@ -39,6 +42,12 @@ import VendorDashboard from "./pages/vendor/VendorDashboard";
 import VendorProducts from "./pages/vendor/VendorProducts";
 import VendorProductForm from "./pages/vendor/VendorProductForm";
 import VendorOrders from "./pages/vendor/VendorOrders";
+import VendorSecurity from "./pages/vendor/VendorSecurity";
+import VendorUnlock from "./pages/vendor/VendorUnlock";
+import VendorStaff from "./pages/vendor/VendorStaff";
+import VendorInvite from "./pages/vendor/VendorInvite";
+import VendorApiKeys from "./pages/vendor/VendorApiKeys";
+import VendorWebhooks from "./pages/vendor/VendorWebhooks";
 
 // Vendor API（保護頁面＆檢查登入）
 import { vGET } from './lib/vendorApi'
@@ -111,6 +120,7 @@ function Header() {
 
   const logout = () => {
     localStorage.removeItem('adminToken')
+    localStorage.removeItem('adminUser')
     setAuthed(false)
     navigate('/')
   }
@@ -438,12 +448,17 @@ function App() {
           <Route path="/admin/login" element={<AdminLogin />} />
           <Route path="/admin/orders" element={<RequireAdmin><Orders /></RequireAdmin>} />
           <Route path="/admin/orders/:id" element={<RequireAdmin><OrderDetail /></RequireAdmin>} />
+          <Route path="/admin/security" element={<RequireAdmin><AdminSecurity /></RequireAdmin>} />
+          <Route path="/admin/vendor-login-audits" element={<RequireAdmin><VendorLoginAudits /></RequireAdmin>} />
+          <Route path="/admin/webhooks" element={<RequireAdmin><AdminWebhooks /></RequireAdmin>} />
 
           {/* 廠商：公開頁（可未登入） */}
           <Route path="/vendor/login" element={<VendorLogin />} />
           <Route path="/vendor/register" element={<VendorRegister />} />
           <Route path="/vendor/forgot" element={<VendorForgot />} />
           <Route path="/vendor/reset" element={<VendorReset />} />
+          <Route path="/vendor/unlock" element={<VendorUnlock />} />
+          <Route path="/vendor/invite" element={<VendorInvite />} />
 
           {/* 廠商：需登入頁（加上保護） */}
           <Route path="/vendor" element={<RequireVendor><VendorDashboard /></RequireVendor>} />
@@ -451,6 +466,10 @@ function App() {
           <Route path="/vendor/products/new" element={<RequireVendor><VendorProductForm /></RequireVendor>} />
           <Route path="/vendor/products/:id/edit" element={<RequireVendor><VendorProductForm /></RequireVendor>} />
           <Route path="/vendor/orders" element={<RequireVendor><VendorOrders /></RequireVendor>} />
+          <Route path="/vendor/security" element={<RequireVendor><VendorSecurity /></RequireVendor>} />
+          <Route path="/vendor/staff" element={<RequireVendor><VendorStaff /></RequireVendor>} />
+          <Route path="/vendor/api-keys" element={<RequireVendor><VendorApiKeys /></RequireVendor>} />
+          <Route path="/vendor/webhooks" element={<RequireVendor><VendorWebhooks /></RequireVendor>} />
         </Route>
       </Routes>
     </BrowserRouter>
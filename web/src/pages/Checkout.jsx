@@ -1,7 +1,7 @@
 // web/src/pages/Checkout.jsx
-import React, { useMemo, useState } from 'react'
-import { useNavigate } from 'react-router-dom'
-import { createOrder } from '../api'
+import React, { useEffect, useMemo, useState } from 'react'
+import { useNavigate, useSearchParams } from 'react-router-dom'
+import { createOrder, cvsMapUrl, getCvsStore } from '../api'
 
 const currency = new Intl.NumberFormat('zh-TW', { style: 'currency', currency: 'TWD' })
 const nt = (n) => currency.format(Number(n) || 0)
@@ -32,20 +32,50 @@ export default function Checkout() {
     [cart]
   )
 
-  const [form, setForm] = useState({
-    buyerName: '',
-    buyerPhone: '',
-    shippingMethod: 'pickup', // pickup | sevencv | home
-    storeCode: '',
-    address: '',
+  const [form, setForm] = useState(() => {
+    // 從電子地圖導回時還原先前填寫的內容
+    const saved = JSON.parse(sessionStorage.getItem('checkoutForm') || 'null')
+    return saved || {
+      buyerName: '',
+      buyerPhone: '',
+      buyerEmail: '',
+      shippingMethod: 'pickup', // pickup | sevencv | home
+      storeCode: '',
+      address: '',
+    }
   })
+  const [store, setStore] = useState(null) // 電子地圖選定的門市 { code, name, address }
+  const [storeToken, setStoreToken] = useState('')
   const onChange = (k, v) => setForm(prev => ({ ...prev, [k]: v }))
   const navigate = useNavigate()
+  const [params, setParams] = useSearchParams()
+
+  useEffect(() => {
+    const state = params.get('cvsState')
+    if (!state) return
+    sessionStorage.removeItem('checkoutForm')
+    getCvsStore(state)
+      .then(st => {
+        setStore(st)
+        setStoreToken(state)
+        setForm(prev => ({ ...prev, shippingMethod: 'sevencv', storeCode: st.code }))
+      })
+      .catch(() => alert('門市資料已過期，請重新選擇'))
+    params.delete('cvsState')
+    setParams(params, { replace: true })
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [])
+
+  function openStoreMap() {
+    sessionStorage.setItem('checkoutForm', JSON.stringify(form))
+    window.location.href = cvsMapUrl(`${window.location.origin}/checkout`)
+  }
 
   async function submit() {
     if (cart.length === 0) { alert('購物車為空'); return }
     if (!form.buyerName.trim()) { alert('請輸入姓名'); return }
     if (!/^09\d{8}$/.test(form.buyerPhone)) { alert('請輸入正確手機號碼（09 開頭，共 10 碼）'); return }
+    if (form.buyerEmail && !/^[^\s@]+@[^\s@]+\.[^\s@]+$/.test(form.buyerEmail.trim())) { alert('Email 格式不正確'); return }
     if (form.shippingMethod === 'sevencv' && !form.storeCode.trim()) { alert('請輸入 7-11 門市代碼/名稱'); return }
     if (form.shippingMethod === 'home' && !form.address.trim()) { alert('請輸入宅配地址'); return }
 
@@ -72,8 +102,10 @@ export default function Checkout() {
     const payload = {
       buyerName: form.buyerName.trim(),        // ✅ 後端要 buyerName
       buyerPhone: form.buyerPhone.trim(),      // ✅ 後端要 buyerPhone
+      buyerEmail: (form.buyerEmail || '').trim(), // 選填：訂單通知信
       shippingMethod: form.shippingMethod,     // pickup | sevencv | home
       storeCode: form.shippingMethod === 'sevencv' ? form.storeCode.trim() : '',
+      storeToken: form.shippingMethod === 'sevencv' && store ? storeToken : '',
       address: form.shippingMethod === 'home' ? form.address.trim() : '',
       items,                                   // ✅ 後端要求的陣列
     }
@@ -122,6 +154,17 @@ export default function Checkout() {
           />
         </label>
 
+        <label className="flex flex-col gap-1">
+          <span>Email（選填，接收訂單通知信）</span>
+          <input
+            type="email"
+            value={form.buyerEmail || ''}
+            onChange={e => onChange('buyerEmail', e.target.value)}
+            placeholder="name@example.com"
+            className="border rounded px-3 py-2"
+          />
+        </label>
+
         <label className="flex flex-col gap-1">
           <span>寄送方式</span>
           <select
@@ -136,15 +179,25 @@ export default function Checkout() {
         </label>
 
         {form.shippingMethod === 'sevencv' && (
-          <label className="flex flex-col gap-1">
-            <span>門市代碼 / 名稱</span>
-            <input
-              value={form.storeCode}
-              onChange={e => onChange('storeCode', e.target.value)}
-              placeholder="例如：7-11_123456 或 OO門市"
-              className="border rounded px-3 py-2"
-            />
-          </label>
+          <div className="flex flex-col gap-1">
+            <span>取貨門市</span>
+            {store ? (
+              <div className="border rounded px-3 py-2">
+                <div>{store.name}（{store.code}）</div>
+                <div className="text-sm text-gray-500">{store.address}</div>
+              </div>
+            ) : (
+              <input
+                value={form.storeCode}
+                onChange={e => onChange('storeCode', e.target.value)}
+                placeholder="例如：7-11_123456 或 OO門市"
+                className="border rounded px-3 py-2"
+              />
+            )}
+            <button type="button" onClick={openStoreMap} className="border rounded px-3 py-2">
+              {store ? '重新選擇門市' : '從電子地圖選擇門市'}
+            </button>
+          </div>
         )}
 
         {form.shippingMethod === 'home' && (
@@ -1,38 +1,112 @@
 import React, { useState } from 'react'
 import { useNavigate } from 'react-router-dom'
+import { adminLogin, adminLogin2FA, setAdminToken } from '../../api'
 
 export default function AdminLogin() {
+  const [email, setEmail] = useState('')
   const [pwd, setPwd] = useState('')
+  const [loading, setLoading] = useState(false)
+  const [challenge, setChallenge] = useState('')
+  const [code, setCode] = useState('')
   const navigate = useNavigate()
 
-  const submit = (e) => {
+  const finish = (res) => {
+    setAdminToken(res.token)
+    localStorage.setItem('adminUser', JSON.stringify({ ...res.user, permissions: res.permissions }))
+    // 政策要求 2FA 但尚未綁定：先去綁定
+    if (res.twoFactorSetupRequired) {
+      navigate('/admin/security')
+      return
+    }
+    // 路徑保持原本的行為：登入後跳 /admin/orders（或登入前所在頁）
+    const next = sessionStorage.getItem('postLoginRedirect') || '/admin/orders'
+    sessionStorage.removeItem('postLoginRedirect')
+    navigate(next)
+  }
+
+  const submit = async (e) => {
     e.preventDefault()
-    if (!pwd) {
-      alert("請輸入管理 Token（需與後端 ADMIN_TOKEN 一致）")
+    if (!email || !pwd) {
+      alert('請輸入帳號與密碼')
       return
     }
-    // 不做硬碼比對，直接把輸入值當 Token 存起來
-    localStorage.setItem("adminToken", pwd)
-    alert("登入成功")
-    // 路徑保持你原來的行為：登入後跳 /admin/orders
-    navigate("/admin/orders")
+    try {
+      setLoading(true)
+      const res = await adminLogin(email.trim(), pwd)
+      if (res.twoFactorRequired) {
+        setChallenge(res.challenge)
+        return
+      }
+      finish(res)
+    } catch (err) {
+      alert(err?.response?.data?.error || err.message)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  // 第二步：驗證器 App 的 6 位數驗證碼，或備用碼
+  const submitCode = async (e) => {
+    e.preventDefault()
+    if (!code.trim()) return
+    try {
+      setLoading(true)
+      finish(await adminLogin2FA(challenge, code.trim()))
+    } catch (err) {
+      alert(err?.response?.data?.error || err.message)
+      // challenge 逾時：回到第一步
+      if (err?.response?.status === 401) {
+        setChallenge('')
+        setCode('')
+      }
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  if (challenge) {
+    return (
+      <div style={{maxWidth:400, margin:"50px auto", padding:20, border:"1px solid #ccc", borderRadius:8}}>
+        <h2>雙因素驗證</h2>
+        <p style={{fontSize:14, color:"#666"}}>請輸入驗證器 App 顯示的 6 位數驗證碼；手機遺失時可輸入備用碼。</p>
+        <form onSubmit={submitCode} style={{display:"grid", gap:12}}>
+          <input
+            value={code}
+            onChange={(e)=>setCode(e.target.value)}
+            placeholder="驗證碼"
+            inputMode="numeric"
+            autoComplete="one-time-code"
+            autoFocus
+            style={{padding:8, fontSize:16}}
+          />
+          <button type="submit" style={{padding:10}} disabled={loading}>{loading ? '驗證中…' : '驗證'}</button>
+          <button type="button" style={{padding:10}} onClick={()=>{ setChallenge(''); setCode('') }}>返回</button>
+        </form>
+      </div>
+    )
   }
 
   return (
     <div style={{maxWidth:400, margin:"50px auto", padding:20, border:"1px solid #ccc", borderRadius:8}}>
       <h2>管理員登入</h2>
-      <p style={{color:'#666', fontSize:14, lineHeight:1.6}}>
-        請輸入管理者金鑰
-      </p>
       <form onSubmit={submit} style={{display:"grid", gap:12}}>
+        <input
+          type="email"
+          value={email}
+          onChange={(e)=>setEmail(e.target.value)}
+          placeholder="Email"
+          autoComplete="username"
+          style={{padding:8, fontSize:16}}
+        />
         <input
           type="password"
           value={pwd}
           onChange={(e)=>setPwd(e.target.value)}
-          placeholder="請輸入管理者金鑰"
+          placeholder="密碼"
+          autoComplete="current-password"
           style={{padding:8, fontSize:16}}
         />
-        <button type="submit" style={{padding:10}}>登入</button>
+        <button type="submit" style={{padding:10}} disabled={loading}>{loading ? '登入中…' : '登入'}</button>
       </form>
     </div>
   )
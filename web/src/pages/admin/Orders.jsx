@@ -1,158 +1,230 @@
-import React, { useEffect, useState, useMemo } from 'react'
-import { Link, useNavigate } from 'react-router-dom'
-import {
-  adminListOrders,
-  adminUpdateOrderStatus,
-  adminDeleteOrder,
-} from '../../api'
-
-export default function Orders() {
-  const [items, setItems] = useState([])
-  const [loading, setLoading] = useState(false)
-  const [q, setQ] = useState('') // 簡易搜尋：訂單編號/電話/姓名/後五碼
-  const navigate = useNavigate()
-
-  const refresh = async () => {
-    try {
-      setLoading(true)
-      const data = await adminListOrders()
-      setItems(data || [])
-    } catch (e) {
-      // 401 → 送回登入頁（路徑保持不變：/admin/login）
-      if (e?.response?.status === 401) {
-        alert('未授權：請先登入管理後台')
-        navigate('/admin/login')
-        return
-      }
-      alert(e?.response?.data?.error || e.message)
-    } finally {
-      setLoading(false)
-    }
-  }
-
-  useEffect(() => {
-    // 若沒有 token，減少無謂請求，直接去登入
-    const t = localStorage.getItem('adminToken')
-    if (!t) {
-      navigate('/admin/login')
-      return
-    }
-    refresh()
-    // eslint-disable-next-line react-hooks/exhaustive-deps
-  }, [])
-
-  const filtered = useMemo(() => {
-    const text = q.trim().toLowerCase()
-    if (!text) return items
-    return items.filter(o =>
-      String(o.orderNo || '').toLowerCase().includes(text) ||
-      String(o.buyerPhone || '').toLowerCase().includes(text) ||
-      String(o.buyerName || '').toLowerCase().includes(text) ||
-      String(o.remitLast5 || '').toLowerCase().includes(text)
-    )
-  }, [items, q])
-
-  const onStatus = async (id, status) => {
-    try {
-      await adminUpdateOrderStatus(id, status)
-      refresh()
-    } catch (e) {
-      if (e?.response?.status === 401) {
-        alert('未授權：請先登入管理後台')
-        navigate('/admin/login')
-        return
-      }
-      alert(e?.response?.data?.error || e.message)
-    }
-  }
-
-  const onDelete = async (id) => {
-    if (!confirm(`確定刪除訂單 #${id}？`)) return
-    try {
-      await adminDeleteOrder(id)
-      refresh()
-    } catch (e) {
-      if (e?.response?.status === 401) {
-        alert('未授權：請先登入管理後台')
-        navigate('/admin/login')
-        return
-      }
-      alert(e?.response?.data?.error || e.message)
-    }
-  }
-
-  // 列印：開新分頁到詳情 + 自動列印
-  const onPrint = (id) => {
-    window.open(`/admin/orders/${id}?print=1`, '_blank')
-  }
-
-  const fmtMoney = (cents) => {
-    const n = Number.isFinite(cents) ? Math.round(cents / 100) : 0
-    return n.toLocaleString('zh-TW')
-  }
-
-  return (
-    <div>
-      <h2>訂單管理</h2>
-
-      <div style={{display:'flex', gap:8, alignItems:'center', marginBottom:12}}>
-        <input
-          value={q}
-          onChange={e=>setQ(e.target.value)}
-          placeholder="搜尋：訂單編號 / 電話 / 姓名 / 後五碼"
-          style={{flex:1, maxWidth:420}}
-        />
-        <button onClick={refresh} disabled={loading}>{loading ? '更新中…' : '重新整理'}</button>
-      </div>
-
-      <div style={{overflowX:'auto'}}>
-        <table width="100%" cellPadding="8" style={{borderCollapse:'collapse', minWidth: 980}}>
-          <thead>
-            <tr style={{background:'#fafafa'}}>
-              <th align="left">ID</th>
-              <th align="left">訂單編號</th>
-              <th align="left">買家</th>
-              <th align="left">電話</th>
-              <th align="right">總額</th>
-              <th align="left">狀態</th>
-              <th align="left">匯款後五碼</th>
-              <th align="left">建立時間</th>
-              <th align="left">操作</th>
-            </tr>
-          </thead>
-          <tbody>
-            {filtered.map(o => (
-              <tr key={o.id} style={{borderTop:'1px solid #eee'}}>
-                <td>{o.id}</td>
-                <td>
-                  {/* 點訂單號進入詳情：/admin/orders/:id */}
-                  <Link to={`/admin/orders/${o.id}`}>{o.orderNo}</Link>
-                </td>
-                <td>{o.buyerName || '-'}</td>
-                <td>{o.buyerPhone || '-'}</td>
-                <td align="right">NT$ {fmtMoney(o.totalAmount)}</td>
-                <td>{o.status}</td>
-                <td>{o.remitLast5 || '-'}</td>
-                <td>{o.createdAt ? new Date(o.createdAt).toLocaleString() : '-'}</td>
-                <td style={{display:'flex', gap:6, flexWrap:'wrap'}}>
-                  {/* 查看 → /admin/orders/:id */}
-                  <button onClick={()=>navigate(`/admin/orders/${o.id}`)}>查看</button>
-                  {/* 列印 */}
-                  <button onClick={()=>onPrint(o.id)}>列印</button>
-                  <button onClick={()=>onStatus(o.id, 'shipped')}>出貨</button>
-                  <button onClick={()=>onStatus(o.id, 'completed')}>完成</button>
-                  <button onClick={()=>onDelete(o.id)} style={{color:'#b00'}}>刪除</button>
-                </td>
-              </tr>
-            ))}
-            {filtered.length === 0 && !loading && (
-              <tr>
-                <td colSpan={9} style={{padding:24, textAlign:'center', color:'#666'}}>沒有符合條件的訂單</td>
-              </tr>
-            )}
-          </tbody>
-        </table>
-      </div>
-    </div>
-  )
-}
+import React, { useEffect, useState } from 'react'
+import { Link, useNavigate } from 'react-router-dom'
+import {
+  adminListOrders,
+  adminUpdateOrderStatus,
+  adminDeleteOrder,
+  adminBulkOrderStatus,
+  adminBulkConfirmPayment,
+} from '../../api'
+
+const PAGE_SIZE = 50
+
+export default function Orders() {
+  const [items, setItems] = useState([])
+  const [loading, setLoading] = useState(false)
+  const [q, setQ] = useState('') // 搜尋：訂單編號/電話/姓名/後五碼（由後端比對）
+  const [status, setStatus] = useState('')
+  const [payment, setPayment] = useState('')
+  const [offset, setOffset] = useState(0)
+  const [total, setTotal] = useState(0)
+  const [selected, setSelected] = useState([]) // 勾選的訂單 ID（批次操作）
+  const navigate = useNavigate()
+
+  const refresh = async (nextOffset = offset) => {
+    try {
+      setLoading(true)
+      const data = await adminListOrders({ q, status, payment, limit: PAGE_SIZE, offset: nextOffset })
+      setItems(data?.items || [])
+      setTotal(data?.total || 0)
+      setOffset(nextOffset)
+      setSelected([])
+    } catch (e) {
+      // 401 → 送回登入頁（路徑保持不變：/admin/login）
+      if (e?.response?.status === 401) {
+        alert('未授權：請先登入管理後台')
+        navigate('/admin/login')
+        return
+      }
+      alert(e?.response?.data?.error || e.message)
+    } finally {
+      setLoading(false)
+    }
+  }
+
+  useEffect(() => {
+    // 若沒有 token，減少無謂請求，直接去登入
+    const t = localStorage.getItem('adminToken')
+    if (!t) {
+      navigate('/admin/login')
+      return
+    }
+    refresh()
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, [])
+
+  const onStatus = async (id, status) => {
+    try {
+      await adminUpdateOrderStatus(id, status)
+      refresh()
+    } catch (e) {
+      if (e?.response?.status === 401) {
+        alert('未授權：請先登入管理後台')
+        navigate('/admin/login')
+        return
+      }
+      alert(e?.response?.data?.error || e.message)
+    }
+  }
+
+  const onDelete = async (id) => {
+    if (!confirm(`確定刪除訂單 #${id}？`)) return
+    try {
+      await adminDeleteOrder(id)
+      refresh()
+    } catch (e) {
+      if (e?.response?.status === 401) {
+        alert('未授權：請先登入管理後台')
+        navigate('/admin/login')
+        return
+      }
+      alert(e?.response?.data?.error || e.message)
+    }
+  }
+
+  const toggle = (id) =>
+    setSelected(sel => sel.includes(id) ? sel.filter(x => x !== id) : [...sel, id])
+
+  // 批次：逐筆執行，失敗的訂單列出原因，其餘照常完成
+  const onBulk = async (fn) => {
+    if (selected.length === 0) return
+    try {
+      const res = await fn(selected)
+      const failed = (res?.results || []).filter(r => !r.ok)
+      if (failed.length > 0) {
+        alert(`成功 ${res.succeeded} 筆，失敗 ${res.failed} 筆：\n` +
+          failed.map(r => `#${r.id}：${r.error}`).join('\n'))
+      }
+      refresh()
+    } catch (e) {
+      if (e?.response?.status === 401) {
+        alert('未授權：請先登入管理後台')
+        navigate('/admin/login')
+        return
+      }
+      alert(e?.response?.data?.error || e.message)
+    }
+  }
+
+  // 列印：開新分頁到詳情 + 自動列印
+  const onPrint = (id) => {
+    window.open(`/admin/orders/${id}?print=1`, '_blank')
+  }
+
+  const fmtMoney = (cents) => {
+    const n = Number.isFinite(cents) ? Math.round(cents / 100) : 0
+    return n.toLocaleString('zh-TW')
+  }
+
+  return (
+    <div>
+      <div style={{display:'flex', justifyContent:'space-between', alignItems:'center'}}>
+        <h2>訂單管理</h2>
+        <div style={{display:'flex', gap:8}}>
+          <button onClick={()=>navigate('/admin/vendor-login-audits')}>廠商登入紀錄</button>
+          <button onClick={()=>navigate('/admin/webhooks')}>Webhook</button>
+          <button onClick={()=>navigate('/admin/security')}>帳號安全</button>
+        </div>
+      </div>
+
+      <div style={{display:'flex', gap:8, alignItems:'center', marginBottom:12}}>
+        <input
+          value={q}
+          onChange={e=>setQ(e.target.value)}
+          onKeyDown={e=>{ if (e.key === 'Enter') refresh(0) }}
+          placeholder="搜尋：訂單編號 / 電話 / 姓名 / 後五碼"
+          style={{flex:1, maxWidth:420}}
+        />
+        <select value={status} onChange={e=>setStatus(e.target.value)}>
+          <option value="">全部狀態</option>
+          <option value="pending">待處理</option>
+          <option value="processing">處理中</option>
+          <option value="shipped">已出貨</option>
+          <option value="completed">已完成</option>
+          <option value="out_of_stock">缺貨</option>
+        </select>
+        <select value={payment} onChange={e=>setPayment(e.target.value)}>
+          <option value="">全部付款</option>
+          <option value="unpaid">未付款</option>
+          <option value="reported">已回填待對帳</option>
+          <option value="paid">已收款</option>
+        </select>
+        <button onClick={()=>refresh(0)} disabled={loading}>{loading ? '更新中…' : '查詢'}</button>
+      </div>
+
+      {selected.length > 0 && (
+        <div style={{display:'flex', gap:8, alignItems:'center', marginBottom:12}}>
+          <span>已選 {selected.length} 筆</span>
+          <button onClick={()=>onBulk(ids => adminBulkConfirmPayment(ids, true))}>確認收款</button>
+          <button onClick={()=>onBulk(ids => adminBulkOrderStatus(ids, 'completed'))}>完成</button>
+          <button onClick={()=>selected.forEach(onPrint)}>列印</button>
+        </div>
+      )}
+
+      <div style={{overflowX:'auto'}}>
+        <table width="100%" cellPadding="8" style={{borderCollapse:'collapse', minWidth: 980}}>
+          <thead>
+            <tr style={{background:'#fafafa'}}>
+              <th>
+                <input
+                  type="checkbox"
+                  checked={items.length > 0 && selected.length === items.length}
+                  onChange={e=>setSelected(e.target.checked ? items.map(o => o.id) : [])}
+                />
+              </th>
+              <th align="left">ID</th>
+              <th align="left">訂單編號</th>
+              <th align="left">買家</th>
+              <th align="left">電話</th>
+              <th align="right">總額</th>
+              <th align="left">狀態</th>
+              <th align="left">匯款後五碼</th>
+              <th align="left">建立時間</th>
+              <th align="left">操作</th>
+            </tr>
+          </thead>
+          <tbody>
+            {items.map(o => (
+              <tr key={o.id} style={{borderTop:'1px solid #eee'}}>
+                <td><input type="checkbox" checked={selected.includes(o.id)} onChange={()=>toggle(o.id)} /></td>
+                <td>{o.id}</td>
+                <td>
+                  {/* 點訂單號進入詳情：/admin/orders/:id */}
+                  <Link to={`/admin/orders/${o.id}`}>{o.orderNo}</Link>
+                </td>
+                <td>{o.buyerName || '-'}</td>
+                <td>{o.buyerPhone || '-'}</td>
+                <td align="right">NT$ {fmtMoney(o.totalAmount)}</td>
+                <td>{o.status}</td>
+                <td>{o.remitLast5 || '-'}</td>
+                <td>{o.createdAt ? new Date(o.createdAt).toLocaleString() : '-'}</td>
+                <td style={{display:'flex', gap:6, flexWrap:'wrap'}}>
+                  {/* 查看 → /admin/orders/:id */}
+                  <button onClick={()=>navigate(`/admin/orders/${o.id}`)}>查看</button>
+                  {/* 列印 */}
+                  <button onClick={()=>onPrint(o.id)}>列印</button>
+                  <button onClick={()=>onStatus(o.id, 'shipped')}>出貨</button>
+                  <button onClick={()=>onStatus(o.id, 'completed')}>完成</button>
+                  <button onClick={()=>onDelete(o.id)} style={{color:'#b00'}}>刪除</button>
+                </td>
+              </tr>
+            ))}
+            {items.length === 0 && !loading && (
+              <tr>
+                <td colSpan={10} style={{padding:24, textAlign:'center', color:'#666'}}>沒有符合條件的訂單</td>
+              </tr>
+            )}
+          </tbody>
+        </table>
+      </div>
+
+      <div style={{display:'flex', gap:8, alignItems:'center', marginTop:12}}>
+        <button onClick={()=>refresh(Math.max(0, offset - PAGE_SIZE))} disabled={loading || offset === 0}>上一頁</button>
+        <span>
+          {total === 0 ? 0 : offset + 1}–{Math.min(offset + PAGE_SIZE, total)} / 共 {total} 筆
+        </span>
+        <button onClick={()=>refresh(offset + PAGE_SIZE)} disabled={loading || offset + PAGE_SIZE >= total}>下一頁</button>
+      </div>
+    </div>
+  )
+}
@@ -0,0 +1,134 @@
+import React, { useEffect, useState } from 'react'
+import {
+  adminGetTwoFactor, adminSetupTwoFactor, adminEnableTwoFactor, adminDisableTwoFactor,
+  adminRegenerateRecoveryCodes, adminSetSecurityPolicy,
+} from '../../api'
+
+// 後台：雙因素驗證（TOTP）綁定 / 停用 / 備用碼；owner 可要求所有帳號啟用
+export default function AdminSecurity() {
+  const [status, setStatus] = useState(null) // { twoFactor: {enabled, recoveryCodesLeft}, required }
+  const [setup, setSetup] = useState(null)   // { secret, uri }
+  const [codes, setCodes] = useState(null)   // 備用碼（只顯示一次）
+  const [code, setCode] = useState('')
+  const [pwd, setPwd] = useState('')
+  const [busy, setBusy] = useState(false)
+
+  const user = JSON.parse(localStorage.getItem('adminUser') || '{}')
+  const canManage = (user.permissions || []).includes('users')
+
+  const load = async () => setStatus(await adminGetTwoFactor())
+
+  useEffect(() => { load().catch(err => alert(err?.response?.data?.error || err.message)) }, [])
+
+  const run = async (fn) => {
+    try {
+      setBusy(true)
+      await fn()
+      setCode('')
+      setPwd('')
+      await load()
+    } catch (err) {
+      alert(err?.response?.data?.error || err.message)
+    } finally {
+      setBusy(false)
+    }
+  }
+
+  const start = () => run(async () => {
+    setCodes(null)
+    setSetup(await adminSetupTwoFactor())
+  })
+
+  const enable = (e) => {
+    e.preventDefault()
+    run(async () => {
+      const res = await adminEnableTwoFactor(code.trim())
+      setSetup(null)
+      setCodes(res.recoveryCodes)
+    })
+  }
+
+  const disable = () => run(async () => {
+    await adminDisableTwoFactor(pwd, code.trim())
+    setCodes(null)
+  })
+
+  const regenerate = () => run(async () => {
+    const res = await adminRegenerateRecoveryCodes(code.trim())
+    setCodes(res.recoveryCodes)
+  })
+
+  const togglePolicy = () => run(async () => {
+    if (!status.required && !confirm('開啟後，所有未綁定的後台帳號登入後只能先完成綁定，確定？')) return
+    await adminSetSecurityPolicy(!status.required)
+  })
+
+  if (!status) return null
+  const tf = status.twoFactor
+  return (
+    <div style={{maxWidth:560, margin:"30px auto", padding:20, display:"grid", gap:16}}>
+      <h2>帳號安全</h2>
+
+      {status.required && !tf.enabled && (
+        <div style={{padding:12, background:"#fff4e5", border:"1px solid #f0b400", borderRadius:6}}>
+          系統已要求所有後台帳號啟用雙因素驗證，完成綁定後才能使用其他功能。
+        </div>
+      )}
+
+      <section style={{padding:16, border:"1px solid #ccc", borderRadius:8, display:"grid", gap:10}}>
+        <div>
+          雙因素驗證：{tf.enabled ? `已啟用（剩餘備用碼 ${tf.recoveryCodesLeft} 組）` : '未啟用'}
+        </div>
+
+        {!tf.enabled && !setup && (
+          <button style={{padding:8}} onClick={start} disabled={busy}>開始設定</button>
+        )}
+
+        {setup && (
+          <form onSubmit={enable} style={{display:"grid", gap:8}}>
+            <div style={{fontSize:14}}>
+              在手機上開啟驗證器 App（Google Authenticator、Authy 等），<a href={setup.uri}>點此加入</a>，或手動輸入金鑰：
+            </div>
+            <code style={{padding:8, background:"#f5f5f5", wordBreak:"break-all"}}>{setup.secret}</code>
+            <input value={code} onChange={(e)=>setCode(e.target.value)} placeholder="App 顯示的 6 位數驗證碼"
+              inputMode="numeric" style={{padding:8, fontSize:16}} />
+            <button type="submit" style={{padding:8}} disabled={busy}>確認啟用</button>
+          </form>
+        )}
+
+        {codes && (
+          <div style={{padding:12, background:"#fffbe6", border:"1px solid #e6d200", borderRadius:6}}>
+            <div style={{fontSize:14, marginBottom:8}}>備用碼只會顯示這一次，請妥善保存；每組只能使用一次。</div>
+            <div style={{display:"grid", gridTemplateColumns:"1fr 1fr", gap:4, fontFamily:"monospace"}}>
+              {codes.map(c => <div key={c}>{c}</div>)}
+            </div>
+          </div>
+        )}
+
+        {tf.enabled && (
+          <div style={{display:"grid", gap:8}}>
+            <input value={code} onChange={(e)=>setCode(e.target.value)} placeholder="驗證碼或備用碼"
+              style={{padding:8, fontSize:16}} />
+            {!status.required && (
+              <input type="password" value={pwd} onChange={(e)=>setPwd(e.target.value)} placeholder="密碼（停用時需要）"
+                autoComplete="current-password" style={{padding:8, fontSize:16}} />
+            )}
+            <div style={{display:"flex", gap:8}}>
+              <button onClick={regenerate} disabled={busy}>重新產生備用碼</button>
+              {!status.required && <button onClick={disable} disabled={busy} style={{color:"#c00"}}>停用雙因素驗證</button>}
+            </div>
+          </div>
+        )}
+      </section>
+
+      {canManage && (
+        <section style={{padding:16, border:"1px solid #ccc", borderRadius:8, display:"grid", gap:10}}>
+          <div>安全政策：{status.required ? '所有後台帳號必須啟用雙因素驗證' : '雙因素驗證為選用'}</div>
+          <button style={{padding:8}} onClick={togglePolicy} disabled={busy}>
+            {status.required ? '改為選用' : '要求所有帳號啟用'}
+          </button>
+        </section>
+      )}
+    </div>
+  )
+}
@@ -0,0 +1,89 @@
+import React, { useEffect, useState } from 'react'
+import { adminListVendorLoginAudits, adminUnlockVendor } from '../../api'
+
+const REASONS = {
+  INVALID_CREDENTIALS: '密碼錯誤',
+  INVALID_CODE: '驗證碼錯誤',
+  TOO_MANY_ATTEMPTS: '嘗試過快 / IP 過多',
+  ACCOUNT_LOCKED: '鎖定中仍嘗試',
+  LOCKED_OUT: '觸發鎖定',
+}
+
+const PAGE = 50
+
+// 後台：廠商登入失敗紀錄（可依 Email / IP 篩選，並手動解除鎖定）
+export default function VendorLoginAudits() {
+  const [email, setEmail] = useState('')
+  const [ip, setIp] = useState('')
+  const [offset, setOffset] = useState(0)
+  const [data, setData] = useState({ items: [], total: 0 })
+
+  const load = async (off = offset) => {
+    try {
+      setData(await adminListVendorLoginAudits({ email: email.trim(), ip: ip.trim(), limit: PAGE, offset: off }))
+      setOffset(off)
+    } catch (err) {
+      alert(err?.response?.data?.error || err.message)
+    }
+  }
+
+  useEffect(() => { load(0) }, [])
+
+  const unlock = async (vendorId) => {
+    if (!confirm('確定解除此廠商的登入鎖定？')) return
+    try {
+      await adminUnlockVendor(vendorId)
+      alert('已解除鎖定')
+    } catch (err) {
+      alert(err?.response?.data?.error || err.message)
+    }
+  }
+
+  return (
+    <div style={{maxWidth:1000, margin:'20px auto', padding:16}}>
+      <h2>廠商登入失敗紀錄</h2>
+
+      <form onSubmit={(e)=>{ e.preventDefault(); load(0) }} style={{display:'flex', gap:8, marginBottom:12}}>
+        <input value={email} onChange={(e)=>setEmail(e.target.value)} placeholder="Email" style={{padding:6}} />
+        <input value={ip} onChange={(e)=>setIp(e.target.value)} placeholder="IP" style={{padding:6}} />
+        <button type="submit">查詢</button>
+      </form>
+
+      <div style={{overflowX:'auto'}}>
+        <table width="100%" cellPadding="6" style={{borderCollapse:'collapse', fontSize:14, minWidth:700}}>
+          <thead>
+            <tr style={{background:'#fafafa'}}>
+              <th align="left">時間</th>
+              <th align="left">Email</th>
+              <th align="left">IP</th>
+              <th align="left">原因</th>
+              <th align="left">User-Agent</th>
+              <th></th>
+            </tr>
+          </thead>
+          <tbody>
+            {data.items.map(a => (
+              <tr key={a.id} style={{borderTop:'1px solid #eee'}}>
+                <td>{new Date(a.createdAt).toLocaleString()}</td>
+                <td>{a.email}{!a.vendorId && <span style={{color:'#999'}}>（無此帳號）</span>}</td>
+                <td>{a.ip}</td>
+                <td>{REASONS[a.reason] || a.reason}</td>
+                <td style={{maxWidth:240, overflow:'hidden', textOverflow:'ellipsis', whiteSpace:'nowrap'}} title={a.userAgent}>{a.userAgent}</td>
+                <td>{a.reason === 'LOCKED_OUT' && a.vendorId && <button onClick={()=>unlock(a.vendorId)}>解除鎖定</button>}</td>
+              </tr>
+            ))}
+            {data.items.length === 0 && (
+              <tr><td colSpan={6} style={{padding:16, textAlign:'center', color:'#666'}}>沒有紀錄</td></tr>
+            )}
+          </tbody>
+        </table>
+      </div>
+
+      <div style={{display:'flex', gap:8, alignItems:'center', marginTop:12}}>
+        <button disabled={offset === 0} onClick={()=>load(Math.max(0, offset - PAGE))}>上一頁</button>
+        <span>{data.total === 0 ? 0 : offset + 1}–{Math.min(offset + PAGE, data.total)} / {data.total}</span>
+        <button disabled={offset + PAGE >= data.total} onClick={()=>load(offset + PAGE)}>下一頁</button>
+      </div>
+    </div>
+  )
+}
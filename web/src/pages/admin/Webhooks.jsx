@@ -0,0 +1,210 @@
+import React, { useEffect, useState } from 'react'
+import {
+  adminListWebhooks, adminCreateWebhook, adminUpdateWebhook, adminDeleteWebhook,
+  adminRotateWebhookSecret, adminPingWebhook, adminListWebhookDeliveries, adminRedeliverWebhook,
+} from '../../api'
+
+const EVENTS = {
+  'order.created': '新訂單',
+  'order.paid': '訂單已付款',
+  'order.shipped': '訂單已出貨',
+  'product.low_stock': '商品庫存偏低',
+  ping: '測試',
+}
+
+const STATUS = { pending: '等待重試', success: '成功', failed: '失敗' }
+
+const PAGE = 50
+
+const errMsg = (err) => err?.response?.data?.error || err.message
+
+// 後台：平台 webhook 訂閱（收到所有訂單 / 商品事件）與投遞紀錄（含各廠商）
+export default function Webhooks() {
+  const [subs, setSubs] = useState([])
+  const [types, setTypes] = useState([])
+  const [form, setForm] = useState({ url: '', description: '', events: [] })
+  const [secret, setSecret] = useState(null)
+
+  const [filter, setFilter] = useState({ vendorId: '', status: '', event: '' })
+  const [offset, setOffset] = useState(0)
+  const [data, setData] = useState({ items: [], total: 0 })
+  const [open, setOpen] = useState(null)
+
+  const loadSubs = async () => {
+    const res = await adminListWebhooks()
+    setSubs(res.items || [])
+    setTypes(res.events || [])
+  }
+
+  const load = async (off = offset) => {
+    try {
+      const params = { status: filter.status, event: filter.event, limit: PAGE, offset: off }
+      if (filter.vendorId.trim()) params.vendorId = filter.vendorId.trim()
+      setData(await adminListWebhookDeliveries(params))
+      setOffset(off)
+    } catch (err) {
+      alert(errMsg(err))
+    }
+  }
+
+  useEffect(() => {
+    loadSubs().catch(err => alert(errMsg(err)))
+    load(0)
+  }, [])
+
+  const act = async (fn) => {
+    try {
+      await fn()
+      await loadSubs()
+      await load()
+    } catch (err) {
+      alert(errMsg(err))
+    }
+  }
+
+  const toggleEvent = (t) => setForm(f => ({
+    ...f, events: f.events.includes(t) ? f.events.filter(x => x !== t) : [...f.events, t],
+  }))
+
+  const create = (e) => {
+    e.preventDefault()
+    act(async () => {
+      const res = await adminCreateWebhook({ ...form, url: form.url.trim(), description: form.description.trim() })
+      setSecret(res.secret)
+      setForm({ url: '', description: '', events: [] })
+    })
+  }
+
+  const remove = (s) => {
+    if (!confirm(`確定刪除 ${s.url}？`)) return
+    act(() => adminDeleteWebhook(s.id))
+  }
+
+  const rotate = (s) => {
+    if (!confirm('確定更換簽章金鑰？舊金鑰會立即失效。')) return
+    act(async () => setSecret((await adminRotateWebhookSecret(s.id)).secret))
+  }
+
+  return (
+    <div style={{maxWidth:1100, margin:'20px auto', padding:16}}>
+      <h2>Webhook</h2>
+
+      {secret && (
+        <div style={{border:'1px solid #f0b429', background:'#fffbea', padding:12, marginBottom:12}}>
+          <div>簽章金鑰（只顯示這一次，請立即保存）：</div>
+          <code style={{display:'block', wordBreak:'break-all', margin:'6px 0'}}>{secret}</code>
+          <button onClick={()=>setSecret(null)}>我已保存</button>
+        </div>
+      )}
+
+      <form onSubmit={create} style={{display:'flex', flexWrap:'wrap', gap:8, alignItems:'center', marginBottom:12}}>
+        <input required type="url" value={form.url} onChange={(e)=>setForm({...form, url:e.target.value})} placeholder="https://…" style={{padding:6, minWidth:320}} />
+        <input value={form.description} onChange={(e)=>setForm({...form, description:e.target.value})} placeholder="說明" style={{padding:6}} />
+        {types.map(t => (
+          <label key={t} style={{fontSize:14}}>
+            <input type="checkbox" checked={form.events.includes(t)} onChange={()=>toggleEvent(t)} /> {EVENTS[t] || t}
+          </label>
+        ))}
+        <span style={{color:'#999', fontSize:13}}>（不勾 = 全部）</span>
+        <button type="submit">新增</button>
+      </form>
+
+      <table width="100%" cellPadding="6" style={{borderCollapse:'collapse', fontSize:14, marginBottom:24}}>
+        <thead>
+          <tr style={{background:'#fafafa'}}>
+            <th align="left">網址</th>
+            <th align="left">事件</th>
+            <th align="left">狀態</th>
+            <th></th>
+          </tr>
+        </thead>
+        <tbody>
+          {subs.map(s => (
+            <tr key={s.id} style={{borderTop:'1px solid #eee', color: s.isActive ? undefined : '#999'}}>
+              <td style={{wordBreak:'break-all'}}>{s.url}{s.description && <div style={{color:'#999'}}>{s.description}</div>}</td>
+              <td>{s.events.length ? s.events.map(t => EVENTS[t] || t).join('、') : '全部'}</td>
+              <td>{s.isActive ? '啟用' : '停用'}</td>
+              <td style={{whiteSpace:'nowrap'}}>
+                <button disabled={!s.isActive} onClick={()=>act(()=>adminPingWebhook(s.id))}>測試</button>{' '}
+                <button onClick={()=>act(()=>adminUpdateWebhook(s.id, { url: s.url, description: s.description, events: s.events, active: !s.isActive }))}>{s.isActive ? '停用' : '啟用'}</button>{' '}
+                <button onClick={()=>rotate(s)}>更換金鑰</button>{' '}
+                <button onClick={()=>remove(s)}>刪除</button>
+              </td>
+            </tr>
+          ))}
+          {subs.length === 0 && (
+            <tr><td colSpan={4} style={{padding:16, textAlign:'center', color:'#666'}}>尚未設定平台 Webhook</td></tr>
+          )}
+        </tbody>
+      </table>
+
+      <h3>投遞紀錄</h3>
+      <form onSubmit={(e)=>{ e.preventDefault(); load(0) }} style={{display:'flex', gap:8, marginBottom:12}}>
+        <input value={filter.vendorId} onChange={(e)=>setFilter({...filter, vendorId:e.target.value})} placeholder="廠商 ID（空 = 全部）" style={{padding:6}} />
+        <select value={filter.status} onChange={(e)=>setFilter({...filter, status:e.target.value})}>
+          <option value="">全部狀態</option>
+          {Object.entries(STATUS).map(([k, v]) => <option key={k} value={k}>{v}</option>)}
+        </select>
+        <select value={filter.event} onChange={(e)=>setFilter({...filter, event:e.target.value})}>
+          <option value="">全部事件</option>
+          {Object.entries(EVENTS).map(([k, v]) => <option key={k} value={k}>{v}</option>)}
+        </select>
+        <button type="submit">查詢</button>
+      </form>
+
+      <div style={{overflowX:'auto'}}>
+        <table width="100%" cellPadding="6" style={{borderCollapse:'collapse', fontSize:14, minWidth:800}}>
+          <thead>
+            <tr style={{background:'#fafafa'}}>
+              <th align="left">時間</th>
+              <th align="left">廠商</th>
+              <th align="left">事件</th>
+              <th align="left">網址</th>
+              <th align="left">結果</th>
+              <th align="left">次數</th>
+              <th></th>
+            </tr>
+          </thead>
+          <tbody>
+            {data.items.map(d => (
+              <React.Fragment key={d.id}>
+                <tr style={{borderTop:'1px solid #eee'}}>
+                  <td>{new Date(d.createdAt).toLocaleString()}</td>
+                  <td>{d.vendorId || <span style={{color:'#999'}}>平台</span>}</td>
+                  <td>{EVENTS[d.event] || d.event}{d.redeliveryOf ? '（重送）' : ''}</td>
+                  <td style={{maxWidth:240, overflow:'hidden', textOverflow:'ellipsis', whiteSpace:'nowrap'}} title={d.url}>{d.url}</td>
+                  <td style={{color: d.status === 'failed' ? '#c00' : undefined}}>
+                    {STATUS[d.status] || d.status}{d.responseStatus ? ` · HTTP ${d.responseStatus}` : ''}
+                    {d.error && <div style={{color:'#999', fontSize:12}}>{d.error}</div>}
+                  </td>
+                  <td>{d.attempts}</td>
+                  <td style={{whiteSpace:'nowrap'}}>
+                    <button onClick={()=>setOpen(open === d.id ? null : d.id)}>內容</button>{' '}
+                    {d.status !== 'pending' && <button onClick={()=>act(()=>adminRedeliverWebhook(d.id))}>重送</button>}
+                  </td>
+                </tr>
+                {open === d.id && (
+                  <tr>
+                    <td colSpan={7}>
+                      <pre style={{background:'#f6f6f6', padding:8, overflowX:'auto', fontSize:12}}>{d.payload}</pre>
+                      {d.responseBody && <pre style={{background:'#f6f6f6', padding:8, overflowX:'auto', fontSize:12}}>{d.responseBody}</pre>}
+                    </td>
+                  </tr>
+                )}
+              </React.Fragment>
+            ))}
+            {data.items.length === 0 && (
+              <tr><td colSpan={7} style={{padding:16, textAlign:'center', color:'#666'}}>沒有紀錄</td></tr>
+            )}
+          </tbody>
+        </table>
+      </div>
+
+      <div style={{display:'flex', gap:8, alignItems:'center', marginTop:12}}>
+        <button disabled={offset === 0} onClick={()=>load(Math.max(0, offset - PAGE))}>上一頁</button>
+        <span>{data.total === 0 ? 0 : offset + 1}–{Math.min(offset + PAGE, data.total)} / {data.total}</span>
+        <button disabled={offset + PAGE >= data.total} onClick={()=>load(offset + PAGE)}>下一頁</button>
+      </div>
+    </div>
+  )
+}